@@ -0,0 +1,528 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config holds the server configuration. It is parsed once from the environment
+// (after the --env file has been loaded) so defaults and validation live in one place.
+type Config struct {
+	// Server
+	EnableTools  []string
+	EnableSSE    bool
+	SSEAddr      string
+	SSEBasePath  string
+	SSEAuthToken string
+	SSETLSCert   string
+	SSETLSKey    string
+
+	// Webhooks
+	EnableWebhook bool
+	WebhookAddr   string
+	WebhookSecret string
+	WebhookConfig string
+
+	// Logging
+	LogLevel  string
+	LogFormat string
+
+	// Tool execution
+	ToolLogLevel       string
+	ToolTimeout        time.Duration
+	ToolTimeouts       map[string]time.Duration
+	OutputDir          string
+	ScreenshotMaxAge   time.Duration
+	CleanupInterval    time.Duration
+	IdempotencyTTL     time.Duration
+	ResultTemplatesDir string
+	MaxResultBytes     int
+
+	// HTTP
+	ProxyURL   string
+	RateLimits map[string]float64
+
+	// AI providers
+	OpenAIAPIKey         string
+	OpenAIBaseURL        string
+	OpenAIOrgID          string
+	OpenAIProxy          string
+	OpenAITimeout        time.Duration
+	DeepseekAPIKey       string
+	DeepseekAPIBase      string
+	OpenRouterAPIKey     string
+	UseOpenRouter        bool
+	UseOllamaDeepseek    bool
+	OllamaURL            string
+	GoogleAIAPIKey       string
+	GeminiTimeout        time.Duration
+	GeminiMaxAttempts    int
+	AIResponseCacheTTL   time.Duration
+	AIResponseCacheSize  int
+	FetchCacheTTL        time.Duration
+	FetchCacheSize       int
+	AIFallbacks          map[string][]AIFallback
+	AISystemPromptPrefix string
+	YouTubeSummaryModel  string
+	MRSummaryModel       string
+	OCRBackend           string
+	OCROpenAIModel       string
+
+	// Atlassian
+	AtlassianHost               string
+	AtlassianEmail              string
+	AtlassianToken              string
+	AtlassianCACert             string
+	AtlassianInsecureSkipVerify bool
+	ConfluenceCacheTTL          time.Duration
+	ConfluenceCacheSize         int
+
+	// GitLab
+	GitLabHost               string
+	GitLabToken              string
+	GitLabDefaultBranch      string
+	GitLabRepoCacheMaxMB     int64
+	GitLabProjectCacheTTL    time.Duration
+	GitLabProjectCacheSize   int
+	GitLabCACert             string
+	GitLabInsecureSkipVerify bool
+
+	// Google
+	GoogleTokenFile       string
+	GoogleCredentialsFile string
+	GoogleMapsAPIKey      string
+
+	// Brave
+	BraveAPIKey      string
+	BraveTimeout     time.Duration
+	BraveMaxAttempts int
+
+	// Qdrant
+	QdrantHost   string
+	QdrantPort   int
+	QdrantAPIKey string
+
+	// RAG
+	RAGIndexExtensions   []string
+	RAGEmbeddingBaseURL  string
+	RAGEmbeddingAPIKey   string
+	RAGEmbeddingModels   map[string]uint64
+	RAGContextModel      string
+	RAGDefaultModel      string
+	RAGDefaultCollection string
+
+	errs []error
+}
+
+// Get returns the configuration, loading it from the environment on first use
+var Get = sync.OnceValue(Load)
+
+// Load parses the configuration from the environment, applying defaults. Values
+// that fail to parse keep their default and are reported by Validate.
+func Load() *Config {
+	c := &Config{
+		EnableTools:  splitList(os.Getenv("ENABLE_TOOLS")),
+		SSEAuthToken: os.Getenv("SSE_AUTH_TOKEN"),
+		SSETLSCert:   os.Getenv("SSE_TLS_CERT"),
+		SSETLSKey:    os.Getenv("SSE_TLS_KEY"),
+
+		WebhookSecret: os.Getenv("WEBHOOK_SECRET"),
+		WebhookConfig: os.Getenv("WEBHOOK_CONFIG"),
+
+		OutputDir:          os.Getenv("OUTPUT_DIR"),
+		ResultTemplatesDir: os.Getenv("RESULT_TEMPLATES_DIR"),
+		ProxyURL:           os.Getenv("PROXY_URL"),
+
+		OpenAIAPIKey:     os.Getenv("OPENAI_API_KEY"),
+		OpenAIBaseURL:    os.Getenv("OPENAI_BASE_URL"),
+		OpenAIOrgID:      os.Getenv("OPENAI_ORG_ID"),
+		OpenAIProxy:      os.Getenv("OPENAI_PROXY"),
+		DeepseekAPIKey:   os.Getenv("DEEPSEEK_API_KEY"),
+		DeepseekAPIBase:  envString("DEEPSEEK_API_BASE", "https://api.deepseek.com/v1"),
+		OpenRouterAPIKey: os.Getenv("OPENROUTER_API_KEY"),
+		OllamaURL:        envString("OLLAMA_URL", "http://localhost:11434"),
+		GoogleAIAPIKey:   os.Getenv("GOOGLE_AI_API_KEY"),
+
+		YouTubeSummaryModel: envString("YOUTUBE_SUMMARY_MODEL", "gpt-4o-mini"),
+		MRSummaryModel:      envString("MR_SUMMARY_MODEL", "gpt-4o-mini"),
+		OCRBackend:          envString("OCR_BACKEND", "auto"),
+		OCROpenAIModel:      envString("OCR_OPENAI_MODEL", "gpt-4o-mini"),
+
+		AtlassianHost:   os.Getenv("ATLASSIAN_HOST"),
+		AtlassianEmail:  os.Getenv("ATLASSIAN_EMAIL"),
+		AtlassianToken:  os.Getenv("ATLASSIAN_TOKEN"),
+		AtlassianCACert: os.Getenv("ATLASSIAN_CA_CERT"),
+
+		GitLabHost:   os.Getenv("GITLAB_HOST"),
+		GitLabToken:  os.Getenv("GITLAB_TOKEN"),
+		GitLabCACert: os.Getenv("GITLAB_CA_CERT"),
+
+		GitLabDefaultBranch: envString("GITLAB_DEFAULT_BRANCH", "main"),
+
+		GoogleTokenFile:       os.Getenv("GOOGLE_TOKEN_FILE"),
+		GoogleCredentialsFile: os.Getenv("GOOGLE_CREDENTIALS_FILE"),
+		GoogleMapsAPIKey:      os.Getenv("GOOGLE_MAPS_API_KEY"),
+
+		BraveAPIKey: os.Getenv("BRAVE_API_KEY"),
+
+		QdrantHost:   os.Getenv("QDRANT_HOST"),
+		QdrantAPIKey: os.Getenv("QDRANT_API_KEY"),
+
+		RAGIndexExtensions:   splitList(os.Getenv("RAG_INDEX_EXTENSIONS")),
+		RAGEmbeddingBaseURL:  os.Getenv("RAG_EMBEDDING_BASE_URL"),
+		RAGEmbeddingAPIKey:   os.Getenv("RAG_EMBEDDING_API_KEY"),
+		RAGContextModel:      envString("RAG_CONTEXT_MODEL", "codesmart"),
+		RAGDefaultModel:      envString("RAG_DEFAULT_MODEL", "codesmart.embedding"),
+		RAGDefaultCollection: os.Getenv("RAG_DEFAULT_COLLECTION"),
+
+		AISystemPromptPrefix: strings.TrimSpace(os.Getenv("AI_SYSTEM_PROMPT_PREFIX")),
+
+		LogLevel:     strings.ToLower(envString("LOG_LEVEL", "info")),
+		LogFormat:    strings.ToLower(envString("LOG_FORMAT", "text")),
+		ToolLogLevel: strings.ToLower(envString("TOOL_LOG_LEVEL", "info")),
+	}
+
+	c.EnableSSE = c.parseBool("ENABLE_SSE")
+	c.SSEAddr = envString("SSE_ADDR", ":8080")
+	c.EnableWebhook = c.parseBool("ENABLE_WEBHOOK")
+	c.WebhookAddr = envString("WEBHOOK_ADDR", ":8090")
+	c.SSEBasePath = envString("SSE_BASE_PATH", "/mcp")
+	c.UseOpenRouter = c.parseBool("USE_OPENROUTER")
+	c.UseOllamaDeepseek = c.parseBool("USE_OLLAMA_DEEPSEEK")
+	c.AtlassianInsecureSkipVerify = c.parseBool("ATLASSIAN_INSECURE_SKIP_VERIFY")
+	c.GitLabInsecureSkipVerify = c.parseBool("GITLAB_INSECURE_SKIP_VERIFY")
+
+	c.ToolTimeout = c.parseDuration("TOOL_TIMEOUT", 5*time.Minute)
+	c.ToolTimeouts = c.parseDurationMap("TOOL_TIMEOUTS")
+	c.ScreenshotMaxAge = c.parseDuration("SCREENSHOT_MAX_AGE", 7*24*time.Hour)
+	c.CleanupInterval = c.parseDuration("CLEANUP_INTERVAL", 0)
+	c.IdempotencyTTL = c.parseDuration("IDEMPOTENCY_TTL", 24*time.Hour)
+	c.MaxResultBytes = c.parseInt("MAX_RESULT_BYTES", 1<<20)
+	c.OpenAITimeout = c.parseDuration("OPENAI_TIMEOUT", 0)
+	c.GeminiTimeout = c.parseDuration("GEMINI_TIMEOUT", 0)
+	c.GeminiMaxAttempts = c.parseInt("GEMINI_MAX_ATTEMPTS", 3)
+	c.BraveTimeout = c.parseDuration("BRAVE_TIMEOUT", 30*time.Second)
+	c.BraveMaxAttempts = c.parseInt("BRAVE_MAX_ATTEMPTS", 3)
+
+	c.AIResponseCacheTTL = c.parseDuration("AI_RESPONSE_CACHE_TTL", 0)
+	c.AIResponseCacheSize = c.parseInt("AI_RESPONSE_CACHE_SIZE", 100)
+	c.FetchCacheTTL = c.parseDuration("FETCH_CACHE_TTL", 0)
+	c.FetchCacheSize = c.parseInt("FETCH_CACHE_SIZE", 100)
+	c.ConfluenceCacheTTL = c.parseDuration("CONFLUENCE_CACHE_TTL", 5*time.Minute)
+	c.ConfluenceCacheSize = c.parseInt("CONFLUENCE_CACHE_SIZE", 100)
+	c.GitLabRepoCacheMaxMB = int64(c.parseInt("GITLAB_REPO_CACHE_MAX_MB", 1024))
+	c.GitLabProjectCacheTTL = c.parseDuration("GITLAB_PROJECT_CACHE_TTL", time.Minute)
+	c.GitLabProjectCacheSize = c.parseInt("GITLAB_PROJECT_CACHE_SIZE", 100)
+	c.QdrantPort = c.parseInt("QDRANT_PORT", 0)
+	c.AIFallbacks = c.parseAIFallbacks()
+	c.RateLimits = c.parseRateLimits()
+	c.RAGEmbeddingModels = c.parseEmbeddingModels("RAG_EMBEDDING_MODELS")
+
+	return c
+}
+
+// Validate reports values that failed to parse and inconsistent settings
+func (c *Config) Validate() error {
+	errs := append([]error(nil), c.errs...)
+
+	if (c.SSETLSCert == "") != (c.SSETLSKey == "") {
+		errs = append(errs, errors.New("SSE_TLS_CERT and SSE_TLS_KEY must be set together"))
+	}
+
+	if c.EnableWebhook && (c.WebhookSecret == "" || c.WebhookConfig == "") {
+		errs = append(errs, errors.New("WEBHOOK_SECRET and WEBHOOK_CONFIG are required when the webhook receiver is enabled"))
+	}
+
+	if c.GeminiMaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("invalid GEMINI_MAX_ATTEMPTS %d: must be at least 1", c.GeminiMaxAttempts))
+	}
+	if c.BraveMaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("invalid BRAVE_MAX_ATTEMPTS %d: must be at least 1", c.BraveMaxAttempts))
+	}
+
+	switch c.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("invalid LOG_LEVEL %q: use debug, info, warn or error", c.LogLevel))
+	}
+
+	switch c.LogFormat {
+	case "text", "json":
+	default:
+		errs = append(errs, fmt.Errorf("invalid LOG_FORMAT %q: use text or json", c.LogFormat))
+	}
+
+	switch c.ToolLogLevel {
+	case "debug", "info", "error", "off":
+	default:
+		errs = append(errs, fmt.Errorf("invalid TOOL_LOG_LEVEL %q: use debug, info, error or off", c.ToolLogLevel))
+	}
+
+	switch c.OCRBackend {
+	case "auto", "tesseract", "openai":
+	default:
+		errs = append(errs, fmt.Errorf("invalid OCR_BACKEND %q: use auto, tesseract or openai", c.OCRBackend))
+	}
+
+	if c.OpenAIProxy != "" {
+		if err := validateProxyURL(c.OpenAIProxy); err != nil {
+			errs = append(errs, fmt.Errorf("invalid OPENAI_PROXY %q: %v", c.OpenAIProxy, err))
+		}
+	}
+
+	if c.UseOpenRouter && c.OpenRouterAPIKey == "" {
+		errs = append(errs, errors.New("OPENROUTER_API_KEY is required when USE_OPENROUTER is true"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateProxyURL checks that a proxy is given as an http, https or socks5 URL
+// with a host, e.g. http://proxy.internal:3128
+func validateProxyURL(rawURL string) error {
+	proxy, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	switch proxy.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return errors.New("use an http://, https:// or socks5:// URL")
+	}
+	if proxy.Host == "" {
+		return errors.New("missing proxy host")
+	}
+	return nil
+}
+
+// Secrets returns the configured credentials, so they can be scrubbed from logs
+func (c *Config) Secrets() []string {
+	var secrets []string
+	for _, secret := range []string{
+		c.SSEAuthToken, c.WebhookSecret, c.OpenAIAPIKey, c.DeepseekAPIKey, c.OpenRouterAPIKey,
+		c.GoogleAIAPIKey, c.AtlassianToken, c.GitLabToken, c.GoogleMapsAPIKey, c.BraveAPIKey,
+		c.QdrantAPIKey, c.RAGEmbeddingAPIKey,
+	} {
+		if secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
+
+// AIFallback is a provider, and optionally a model, to try when an AI tool's
+// primary provider fails
+type AIFallback struct {
+	Provider string
+	Model    string
+}
+
+// aiFallbackProviders are the providers an AI fallback chain can name
+var aiFallbackProviders = []string{"deepseek", "openrouter", "ollama", "openai", "gemini"}
+
+// AIFallbackChain returns the fallback chain of an AI tool: AI_FALLBACK_<TOOL>
+// when set, otherwise AI_FALLBACK
+func (c *Config) AIFallbackChain(tool string) []AIFallback {
+	if chain, ok := c.AIFallbacks[tool]; ok {
+		return chain
+	}
+	return c.AIFallbacks[""]
+}
+
+// parseAIFallbacks reads AI_FALLBACK and every AI_FALLBACK_<TOOL>, each a comma
+// separated list of provider or provider:model entries, tried in order
+func (c *Config) parseAIFallbacks() map[string][]AIFallback {
+	result := make(map[string][]AIFallback)
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+		var tool string
+		switch {
+		case key == "AI_FALLBACK":
+		case strings.HasPrefix(key, "AI_FALLBACK_"):
+			tool = strings.ToLower(strings.TrimPrefix(key, "AI_FALLBACK_"))
+		default:
+			continue
+		}
+
+		var chain []AIFallback
+		for _, entry := range splitList(value) {
+			provider, model, _ := strings.Cut(entry, ":")
+			if !slices.Contains(aiFallbackProviders, provider) {
+				c.errs = append(c.errs, fmt.Errorf("invalid %s provider %q: use %s", key, provider, strings.Join(aiFallbackProviders, ", ")))
+				continue
+			}
+			chain = append(chain, AIFallback{Provider: provider, Model: model})
+		}
+		result[tool] = chain
+	}
+	return result
+}
+
+// RateLimitProviders are the outbound API providers whose request rate can be
+// limited with <PROVIDER>_RPS
+var RateLimitProviders = []string{"gitlab", "atlassian", "openai", "deepseek", "openrouter", "qdrant", "google_maps"}
+
+// parseRateLimits reads the requests per second allowed for each provider from
+// <PROVIDER>_RPS, e.g. GITLAB_RPS. Providers without a positive limit are not limited.
+func (c *Config) parseRateLimits() map[string]float64 {
+	result := make(map[string]float64)
+	for _, provider := range RateLimitProviders {
+		key := strings.ToUpper(provider) + "_RPS"
+		if rps := c.parseFloat(key, 0); rps > 0 {
+			result[provider] = rps
+		}
+	}
+	return result
+}
+
+// ToolEnabled reports whether a tool group is enabled by ENABLE_TOOLS. All groups
+// are enabled when ENABLE_TOOLS is empty.
+func (c *Config) ToolEnabled(name string) bool {
+	if len(c.EnableTools) == 0 {
+		return true
+	}
+	for _, tool := range c.EnableTools {
+		if tool == name {
+			return true
+		}
+	}
+	return false
+}
+
+func envString(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (c *Config) parseBool(key string) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return false
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		c.errs = append(c.errs, fmt.Errorf("invalid %s %q: %v", key, value, err))
+		return false
+	}
+	return parsed
+}
+
+func (c *Config) parseInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		c.errs = append(c.errs, fmt.Errorf("invalid %s %q: %v", key, value, err))
+		return fallback
+	}
+	return parsed
+}
+
+func (c *Config) parseFloat(key string, fallback float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		c.errs = append(c.errs, fmt.Errorf("invalid %s %q: %v", key, value, err))
+		return fallback
+	}
+	return parsed
+}
+
+func (c *Config) parseDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		c.errs = append(c.errs, fmt.Errorf("invalid %s %q: %v", key, value, err))
+		return fallback
+	}
+	return parsed
+}
+
+// parseEmbeddingModels parses embedding model vector sizes, given either as a
+// JSON object of model name to dimensions or a comma separated list of
+// model=dimensions pairs
+func (c *Config) parseEmbeddingModels(key string) map[string]uint64 {
+	value := strings.TrimSpace(os.Getenv(key))
+	result := make(map[string]uint64)
+	if value == "" {
+		return result
+	}
+
+	entries := make(map[string]string)
+	if strings.HasPrefix(value, "{") {
+		var parsed map[string]json.Number
+		if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+			c.errs = append(c.errs, fmt.Errorf("invalid %s: %v", key, err))
+			return result
+		}
+		for name, dimensions := range parsed {
+			entries[name] = dimensions.String()
+		}
+	} else {
+		for _, entry := range splitList(value) {
+			name, dimensions, ok := strings.Cut(entry, "=")
+			if !ok {
+				c.errs = append(c.errs, fmt.Errorf("invalid %s entry %q: expected model=dimensions", key, entry))
+				continue
+			}
+			entries[strings.TrimSpace(name)] = strings.TrimSpace(dimensions)
+		}
+	}
+
+	for name, dimensions := range entries {
+		parsed, err := strconv.ParseUint(dimensions, 10, 64)
+		if err != nil || parsed == 0 {
+			c.errs = append(c.errs, fmt.Errorf("invalid %s dimensions for %s: %q must be a positive integer", key, name, dimensions))
+			continue
+		}
+		result[name] = parsed
+	}
+	return result
+}
+
+// parseDurationMap parses a comma separated list of name=duration pairs
+func (c *Config) parseDurationMap(key string) map[string]time.Duration {
+	result := make(map[string]time.Duration)
+	for _, entry := range splitList(os.Getenv(key)) {
+		name, duration, ok := strings.Cut(entry, "=")
+		if !ok {
+			c.errs = append(c.errs, fmt.Errorf("invalid %s entry %q: expected name=duration", key, entry))
+			continue
+		}
+		parsed, err := time.ParseDuration(duration)
+		if err != nil {
+			c.errs = append(c.errs, fmt.Errorf("invalid %s duration for %s: %v", key, name, err))
+			continue
+		}
+		result[strings.TrimSpace(name)] = parsed
+	}
+	return result
+}
@@ -14,6 +14,7 @@ require (
 	github.com/tidwall/gjson v1.18.0
 	gitlab.com/gitlab-org/api/client-go v0.123.0
 	golang.org/x/oauth2 v0.27.0
+	golang.org/x/time v0.10.0
 	google.golang.org/api v0.223.0
 	google.golang.org/genai v0.0.0-20241212193733-4205754a2023
 )
@@ -22,6 +23,7 @@ require (
 	github.com/joho/godotenv v1.5.1
 	github.com/kbinani/screenshot v0.0.0-20250118074034-a3924b7bbc8c
 	github.com/sergi/go-diff v1.3.1
+	google.golang.org/grpc v1.70.0
 	googlemaps.github.io/maps v1.7.0
 )
 
@@ -62,8 +64,6 @@ require (
 	golang.org/x/net v0.35.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
 	golang.org/x/text v0.22.0 // indirect
-	golang.org/x/time v0.10.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250219182151-9fdb1cabc7b2 // indirect
-	google.golang.org/grpc v1.70.0 // indirect
 	google.golang.org/protobuf v1.36.5 // indirect
 )
@@ -5,155 +5,301 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
-	"slices"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/athapong/aio-mcp/config"
 	"github.com/athapong/aio-mcp/prompts"
 	"github.com/athapong/aio-mcp/resources"
 	"github.com/athapong/aio-mcp/tools"
+	"github.com/athapong/aio-mcp/util"
 	"github.com/joho/godotenv"
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
 func main() {
 	envFile := flag.String("env", ".env", "Path to environment file")
 	enableSSE := flag.Bool("sse", false, "Enable SSE server")
-	sseAddr := flag.String("sse-addr", ":8080", "Address for SSE server to listen on")
-	sseBasePath := flag.String("sse-base-path", "/mcp", "Base path for SSE endpoints")
+	sseAddr := flag.String("sse-addr", ":8080", "Address for SSE server to listen on (or SSE_ADDR)")
+	sseBasePath := flag.String("sse-base-path", "/mcp", "Base path for SSE endpoints (or SSE_BASE_PATH)")
+	sseTLSCert := flag.String("sse-tls-cert", "", "Path to TLS certificate file for the SSE server (or SSE_TLS_CERT)")
+	sseTLSKey := flag.String("sse-tls-key", "", "Path to TLS key file for the SSE server (or SSE_TLS_KEY)")
+	enableWebhook := flag.Bool("webhook", false, "Enable the webhook receiver (or ENABLE_WEBHOOK)")
+	webhookAddr := flag.String("webhook-addr", ":8090", "Address for the webhook receiver to listen on (or WEBHOOK_ADDR)")
+	logLevel := flag.String("log-level", "info", "Log level: debug, info, warn or error (or LOG_LEVEL)")
+	logFormat := flag.String("log-format", "text", "Log format: text or json (or LOG_FORMAT)")
+	dumpSchema := flag.String("dump-schema", "", "Write the name, description, argument schema and enabled state of every tool to this JSON file and exit")
 	flag.Parse()
 
 	if err := godotenv.Load(*envFile); err != nil {
-		log.Printf("Warning: Error loading env file %s: %v\n", *envFile, err)
+		slog.Warn("Failed to load env file", "file", *envFile, "error", err)
 	}
-	// Create MCP server
-	mcpServer := server.NewMCPServer(
+
+	// Command-line flags take precedence over the environment
+	cfg := config.Get()
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "sse":
+			cfg.EnableSSE = *enableSSE
+		case "sse-addr":
+			cfg.SSEAddr = *sseAddr
+		case "sse-base-path":
+			cfg.SSEBasePath = *sseBasePath
+		case "sse-tls-cert":
+			cfg.SSETLSCert = *sseTLSCert
+		case "sse-tls-key":
+			cfg.SSETLSKey = *sseTLSKey
+		case "webhook":
+			cfg.EnableWebhook = *enableWebhook
+		case "webhook-addr":
+			cfg.WebhookAddr = *webhookAddr
+		case "log-level":
+			cfg.LogLevel = strings.ToLower(*logLevel)
+		case "log-format":
+			cfg.LogFormat = strings.ToLower(*logFormat)
+		}
+	})
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	util.SetupLogging(cfg.LogLevel, cfg.LogFormat, cfg.Secrets())
+	if err := util.LoadResultTemplates(cfg.ResultTemplatesDir); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	// Create MCP server. Argument validation looks tools up on the server itself,
+	// so it is declared before it is assigned.
+	var mcpServer *server.MCPServer
+	lookupTool := func(ctx context.Context, name string) (mcp.Tool, bool) {
+		return tools.LookupTool(ctx, mcpServer, name)
+	}
+	mcpServer = server.NewMCPServer(
 		"aio-mcp",
 		"1.0.0",
 		server.WithLogging(),
 		server.WithPromptCapabilities(true),
 		server.WithResourceCapabilities(true, true),
+		server.WithToolHandlerMiddleware(util.ToolLoggingMiddleware(cfg.ToolLogLevel)),
+		server.WithToolHandlerMiddleware(util.ToolTimeoutMiddleware(cfg.ToolTimeout, toolTimeouts(cfg), lookupTool)),
+		server.WithToolHandlerMiddleware(util.ArgumentValidationMiddleware(lookupTool)),
 	)
 
-	tools.RegisterToolManagerTool(mcpServer)
+	registerTools(mcpServer, cfg.ToolEnabled)
+
+	// Write the tool catalog and exit. A second server with every tool group
+	// registered supplies the disabled tools.
+	if *dumpSchema != "" {
+		catalog := server.NewMCPServer("aio-mcp", "1.0.0")
+		registerTools(catalog, func(string) bool { return true })
+		if err := tools.WriteToolSchema(context.Background(), mcpServer, catalog, *dumpSchema); err != nil {
+			log.Fatalf("Failed to dump tool schema: %v", err)
+		}
+		return
+	}
+
+	tools.StartJanitor()
+
+	prompts.RegisterCodeTools(mcpServer)
+
+	// Run tool pipelines in response to GitLab and Jira webhooks
+	var webhookServer *http.Server
+	if cfg.EnableWebhook {
+		rules, err := tools.LoadWebhookRules(cfg.WebhookConfig)
+		if err != nil {
+			log.Fatalf("Invalid configuration: %v", err)
+		}
+		webhookServer = &http.Server{Addr: cfg.WebhookAddr, Handler: tools.NewWebhookHandler(mcpServer, rules, cfg.WebhookSecret)}
+		go func() {
+			slog.Info("Starting webhook receiver", "addr", cfg.WebhookAddr, "rules", len(rules))
+			if err := webhookServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Failed to start webhook receiver: %v", err)
+			}
+		}()
+	}
+
+	// Check if SSE server should be enabled
+	if cfg.EnableSSE {
+		// Create SSE server behind our own HTTP server so auth and TLS can be applied
+		httpServer := &http.Server{Addr: cfg.SSEAddr}
+		sseServer := server.NewSSEServer(
+			mcpServer,
+			server.WithBasePath(cfg.SSEBasePath),
+			server.WithKeepAlive(true),
+			server.WithHTTPServer(httpServer),
+		)
+
+		if cfg.SSEAuthToken == "" {
+			slog.Warn("SSE_AUTH_TOKEN is not set, the SSE server accepts unauthenticated requests")
+		}
+		httpServer.Handler = util.BearerAuth(cfg.SSEAuthToken, sseServer)
+
+		// Start SSE server in a goroutine
+		go func() {
+			var err error
+			if cfg.SSETLSCert != "" {
+				slog.Info("Starting SSE server with TLS", "addr", cfg.SSEAddr, "base_path", cfg.SSEBasePath)
+				err = httpServer.ListenAndServeTLS(cfg.SSETLSCert, cfg.SSETLSKey)
+			} else {
+				slog.Info("Starting SSE server", "addr", cfg.SSEAddr, "base_path", cfg.SSEBasePath)
+				err = httpServer.ListenAndServe()
+			}
+			if err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Failed to start SSE server: %v", err)
+			}
+		}()
+
+		// Set up signal handling for graceful shutdown
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+		// Wait for termination signal
+		sig := <-sigCh
+		slog.Info("Shutting down", "signal", sig.String())
+
+		// Gracefully shutdown the SSE server
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := sseServer.Shutdown(ctx); err != nil {
+			slog.Error("SSE server shutdown failed", "error", err)
+		}
+		slog.Info("SSE server shutdown complete")
+		shutdownWebhookServer(ctx, webhookServer)
+	} else {
+		// Use stdio server as before. It returns on SIGINT, SIGTERM or when stdin is closed.
+		err := server.ServeStdio(mcpServer)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownWebhookServer(ctx, webhookServer)
 
-	enableTools := strings.Split(os.Getenv("ENABLE_TOOLS"), ",")
-	allToolsEnabled := len(enableTools) == 1 && enableTools[0] == ""
+		if err != nil {
+			panic(fmt.Sprintf("Server error: %v", err))
+		}
+	}
+}
 
-	isEnabled := func(toolName string) bool {
-		return allToolsEnabled || slices.Contains(enableTools, toolName)
+// shutdownWebhookServer lets in-flight webhook requests finish and closes the
+// listener. It does nothing when the webhook receiver is not enabled.
+func shutdownWebhookServer(ctx context.Context, webhookServer *http.Server) {
+	if webhookServer == nil {
+		return
+	}
+	if err := webhookServer.Shutdown(ctx); err != nil {
+		slog.Error("Webhook receiver shutdown failed", "error", err)
+		return
 	}
+	slog.Info("Webhook receiver shutdown complete")
+}
+
+// registerTools registers the tools of every group isEnabled accepts
+func registerTools(s *server.MCPServer, isEnabled func(string) bool) {
+	tools.RegisterToolManagerTool(s)
 
 	if isEnabled("gemini") {
-		tools.RegisterGeminiTool(mcpServer)
+		tools.RegisterGeminiTool(s)
 	}
 
 	if isEnabled("deepseek") {
-		tools.RegisterDeepseekTool(mcpServer)
+		tools.RegisterDeepseekTool(s)
+	}
+
+	if isEnabled("openai") {
+		tools.RegisterOpenAITool(s)
 	}
 
 	if isEnabled("fetch") {
-		tools.RegisterFetchTool(mcpServer)
+		tools.RegisterFetchTool(s)
 	}
 
 	if isEnabled("brave_search") {
-		tools.RegisterWebSearchTool(mcpServer)
+		tools.RegisterWebSearchTool(s)
 	}
 
 	if isEnabled("confluence") {
-		tools.RegisterConfluenceTool(mcpServer)
+		tools.RegisterConfluenceTool(s)
 	}
 
 	if isEnabled("youtube") {
-		tools.RegisterYouTubeTool(mcpServer)
+		tools.RegisterYouTubeTool(s)
 	}
 
 	if isEnabled("jira") {
-		tools.RegisterJiraTool(mcpServer)
-		resources.RegisterJiraResource(mcpServer)
+		tools.RegisterJiraTool(s)
+		resources.RegisterJiraResource(s)
 	}
 
 	if isEnabled("gitlab") {
-		tools.RegisterGitLabTool(mcpServer)
+		tools.RegisterGitLabTool(s)
 	}
 
 	if isEnabled("script") {
-		tools.RegisterScriptTool(mcpServer)
+		tools.RegisterScriptTool(s)
 	}
 
 	if isEnabled("rag") {
-		tools.RegisterRagTools(mcpServer)
+		tools.RegisterRagTools(s)
+	}
+
+	if isEnabled("confluence") && isEnabled("rag") {
+		tools.RegisterConfluenceRagTool(s)
+	}
+
+	if isEnabled("gitlab") && isEnabled("rag") {
+		tools.RegisterFindReferencesTool(s)
+	}
+
+	if isEnabled("youtube") && isEnabled("rag") {
+		tools.RegisterYouTubeRagTool(s)
 	}
 
 	if isEnabled("gmail") {
-		tools.RegisterGmailTools(mcpServer)
+		tools.RegisterGmailTools(s)
 	}
 
 	if isEnabled("calendar") {
-		tools.RegisterCalendarTools(mcpServer)
+		tools.RegisterCalendarTools(s)
 	}
 
 	if isEnabled("youtube_channel") {
-		tools.RegisterYouTubeChannelTools(mcpServer)
+		tools.RegisterYouTubeChannelTools(s)
 	}
 
 	if isEnabled("sequential_thinking") {
-		tools.RegisterSequentialThinkingTool(mcpServer)
-		tools.RegisterSequentialThinkingHistoryTool(mcpServer)
+		tools.RegisterSequentialThinkingTool(s)
+		tools.RegisterSequentialThinkingHistoryTool(s)
+		tools.RegisterSequentialThinkingMergeBranchTool(s)
 	}
 
 	if isEnabled("gchat") {
-		tools.RegisterGChatTool(mcpServer)
+		tools.RegisterGChatTool(s)
 	}
 
-	tools.RegisterScreenshotTool(mcpServer)
-
-	prompts.RegisterCodeTools(mcpServer)
+	tools.RegisterScreenshotTool(s)
+	tools.RegisterCleanupTool(s)
 
 	if isEnabled("google_maps") {
-		tools.RegisterGoogleMapTools(mcpServer)
+		tools.RegisterGoogleMapTools(s)
 	}
+}
 
-	// Check if SSE server should be enabled
-	if *enableSSE || os.Getenv("ENABLE_SSE") == "true" {
-		// Create SSE server
-		sseServer := server.NewSSEServer(
-			mcpServer,
-			server.WithBasePath(*sseBasePath),
-			server.WithKeepAlive(true),
-		)
-
-		// Start SSE server in a goroutine
-		go func() {
-			log.Printf("Starting SSE server on %s with base path %s", *sseAddr, *sseBasePath)
-			if err := sseServer.Start(*sseAddr); err != nil {
-				log.Fatalf("Failed to start SSE server: %v", err)
-			}
-		}()
-
-		// Set up signal handling for graceful shutdown
-		sigCh := make(chan os.Signal, 1)
-		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-
-		// Wait for termination signal
-		sig := <-sigCh
-		log.Printf("Received signal %v, shutting down...", sig)
-
-		// Gracefully shutdown the SSE server
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
-		if err := sseServer.Shutdown(ctx); err != nil {
-			log.Printf("Error during SSE server shutdown: %v", err)
-		}
-		log.Println("SSE server shutdown complete")
-	} else {
-		// Use stdio server as before
-		if err := server.ServeStdio(mcpServer); err != nil {
-			panic(fmt.Sprintf("Server error: %v", err))
-		}
+// toolTimeouts returns the per-tool timeouts for long running tools, with
+// TOOL_TIMEOUTS overriding the built-in values. Tools that make changes, such as
+// the indexing tools, have no timeout unless TOOL_TIMEOUTS sets one.
+func toolTimeouts(cfg *config.Config) map[string]time.Duration {
+	timeouts := map[string]time.Duration{
+		"gitlab_wait_pipeline": 35 * time.Minute,
+		"gitlab_summarize_mr":  15 * time.Minute,
+		"youtube_summarize":    15 * time.Minute,
+	}
+	for name, timeout := range cfg.ToolTimeouts {
+		timeouts[name] = timeout
 	}
+	return timeouts
 }
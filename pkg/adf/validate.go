@@ -0,0 +1,196 @@
+package adf
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Problem is a validation problem found in an ADF document, located by the
+// path of the offending node, e.g. content[0].content[2]
+type Problem struct {
+	Path    string
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return fmt.Sprintf("%s: %s", p.Path, p.Message)
+}
+
+// knownNodes are the node types of the ADF schema. Nodes in the map with a
+// true value must have content, leaf nodes must not have any.
+var knownNodes = map[string]bool{
+	"doc":             false,
+	"paragraph":       false,
+	"heading":         false,
+	"codeBlock":       false,
+	"panel":           true,
+	"blockquote":      true,
+	"bulletList":      true,
+	"orderedList":     true,
+	"listItem":        true,
+	"taskList":        true,
+	"taskItem":        false,
+	"decisionList":    true,
+	"decisionItem":    false,
+	"table":           true,
+	"tableRow":        true,
+	"tableHeader":     true,
+	"tableCell":       true,
+	"mediaSingle":     true,
+	"mediaGroup":      true,
+	"layoutSection":   true,
+	"layoutColumn":    true,
+	"expand":          true,
+	"nestedExpand":    true,
+	"extension":       false,
+	"bodiedExtension": true,
+	"text":            false,
+	"hardBreak":       false,
+	"rule":            false,
+	"media":           false,
+	"mention":         false,
+	"emoji":           false,
+	"date":            false,
+	"status":          false,
+	"inlineCard":      false,
+	"blockCard":       false,
+	"embedCard":       false,
+	"inlineExtension": false,
+	"placeholder":     false,
+}
+
+var leafNodes = map[string]bool{
+	"text":            true,
+	"hardBreak":       true,
+	"rule":            true,
+	"media":           true,
+	"mention":         true,
+	"emoji":           true,
+	"date":            true,
+	"status":          true,
+	"inlineCard":      true,
+	"blockCard":       true,
+	"embedCard":       true,
+	"extension":       true,
+	"inlineExtension": true,
+	"placeholder":     true,
+}
+
+var knownMarks = map[string]bool{
+	"strong":          true,
+	"em":              true,
+	"code":            true,
+	"strike":          true,
+	"underline":       true,
+	"link":            true,
+	"subsup":          true,
+	"textColor":       true,
+	"backgroundColor": true,
+	"alignment":       true,
+	"indentation":     true,
+	"border":          true,
+	"annotation":      true,
+	"breakout":        true,
+	"dataConsumer":    true,
+	"fragment":        true,
+}
+
+// ValidateJSON parses an ADF document and validates it, including the
+// top-level version that Node does not carry
+func ValidateJSON(data []byte) []Problem {
+	var doc struct {
+		Version *int `json:"version"`
+		Node
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return []Problem{{Message: fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+
+	var problems []Problem
+	if doc.Version == nil {
+		problems = append(problems, Problem{Message: "missing version, expected 1"})
+	} else if *doc.Version != 1 {
+		problems = append(problems, Problem{Message: fmt.Sprintf("unsupported version %d, expected 1", *doc.Version)})
+	}
+	return append(problems, Validate(&doc.Node)...)
+}
+
+// Validate checks that node is a doc made of known node and mark types, with
+// no empty text nodes, no leaf nodes with content and no empty containers
+// that the schema requires content in
+func Validate(node *Node) []Problem {
+	if node == nil {
+		return []Problem{{Message: "document is empty"}}
+	}
+	if node.Type != "doc" {
+		return []Problem{{Message: fmt.Sprintf("root node must be of type doc, got %q", node.Type)}}
+	}
+
+	var problems []Problem
+	validateNode(node, "", &problems)
+	return problems
+}
+
+func validateNode(node *Node, path string, problems *[]Problem) {
+	report := func(format string, args ...interface{}) {
+		*problems = append(*problems, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if node == nil {
+		report("null node")
+		return
+	}
+
+	requiresContent, known := knownNodes[node.Type]
+	switch {
+	case node.Type == "":
+		report("node has no type")
+	case !known:
+		report("unsupported node type %q", node.Type)
+	case node.Type == "doc" && path != "":
+		report("doc can only be the root node")
+	case node.Type == "text" && node.Text == "":
+		report("empty text node")
+	case leafNodes[node.Type] && len(node.Content) > 0:
+		report("%s node cannot have content", node.Type)
+	case requiresContent && len(node.Content) == 0:
+		report("empty %s node", node.Type)
+	}
+
+	if node.Type == "heading" {
+		var level float64
+		switch value := node.Attrs["level"].(type) {
+		case float64:
+			level = value
+		case int:
+			level = float64(value)
+		}
+		if level < 1 || level > 6 {
+			report("heading needs a level attribute from 1 to 6")
+		}
+	}
+
+	for _, mark := range node.Marks {
+		switch {
+		case mark == nil:
+			report("null mark")
+		case !knownMarks[mark.Type]:
+			report("unsupported mark type %q", mark.Type)
+		case mark.Type == "link":
+			if href, _ := mark.Attrs["href"].(string); href == "" {
+				report("link mark needs an href attribute")
+			}
+		}
+	}
+
+	for i, child := range node.Content {
+		childPath := fmt.Sprintf("content[%d]", i)
+		if path != "" {
+			childPath = path + "." + childPath
+		}
+		validateNode(child, childPath, problems)
+	}
+}
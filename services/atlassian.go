@@ -2,9 +2,11 @@ package services
 
 import (
 	"log"
-	"os"
+	"net/http"
 	"sync"
 
+	"github.com/athapong/aio-mcp/config"
+	confluencev1 "github.com/ctreminiom/go-atlassian/confluence"
 	"github.com/ctreminiom/go-atlassian/confluence/v2"
 	"github.com/ctreminiom/go-atlassian/jira/agile"
 	jira "github.com/ctreminiom/go-atlassian/jira/v2"
@@ -12,9 +14,8 @@ import (
 )
 
 func loadAtlassianCredentials() (host, mail, token string) {
-	host = os.Getenv("ATLASSIAN_HOST")
-	mail = os.Getenv("ATLASSIAN_EMAIL")
-	token = os.Getenv("ATLASSIAN_TOKEN")
+	cfg := config.Get()
+	host, mail, token = cfg.AtlassianHost, cfg.AtlassianEmail, cfg.AtlassianToken
 
 	if host == "" || mail == "" || token == "" {
 		log.Fatal("ATLASSIAN_HOST, ATLASSIAN_EMAIL, ATLASSIAN_TOKEN are required, please set it in MCP Config")
@@ -23,10 +24,21 @@ func loadAtlassianCredentials() (host, mail, token string) {
 	return host, mail, token
 }
 
+// atlassianHTTPClient applies ATLASSIAN_CA_CERT, ATLASSIAN_INSECURE_SKIP_VERIFY
+// and ATLASSIAN_RPS to the Confluence and Jira clients
+var atlassianHTTPClient = sync.OnceValue(func() *http.Client {
+	cfg := config.Get()
+	client, err := NewTLSHTTPClient(cfg.AtlassianCACert, cfg.AtlassianInsecureSkipVerify)
+	if err != nil {
+		log.Fatal(errors.WithMessage(err, "failed to create atlassian http client"))
+	}
+	return RateLimitedHTTPClient("atlassian", client)
+})
+
 var ConfluenceClient = sync.OnceValue(func() *confluence.Client {
 	host, mail, token := loadAtlassianCredentials()
 
-	instance, err := confluence.New(nil, host)
+	instance, err := confluence.New(atlassianHTTPClient(), host)
 	if err != nil {
 		log.Fatal(errors.WithMessage(err, "failed to create confluence client"))
 	}
@@ -36,6 +48,21 @@ var ConfluenceClient = sync.OnceValue(func() *confluence.Client {
 	return instance
 })
 
+// ConfluenceV1Client is the Confluence REST v1 client, used for APIs that have no
+// v2 equivalent such as content labels
+var ConfluenceV1Client = sync.OnceValue(func() *confluencev1.Client {
+	host, mail, token := loadAtlassianCredentials()
+
+	instance, err := confluencev1.New(atlassianHTTPClient(), host)
+	if err != nil {
+		log.Fatal(errors.WithMessage(err, "failed to create confluence v1 client"))
+	}
+
+	instance.Auth.SetBasicAuth(mail, token)
+
+	return instance
+})
+
 var JiraClient = sync.OnceValue(func() *jira.Client {
 	host, mail, token := loadAtlassianCredentials()
 
@@ -43,7 +70,7 @@ var JiraClient = sync.OnceValue(func() *jira.Client {
 		log.Fatal("ATLASSIAN_HOST, ATLASSIAN_EMAIL, ATLASSIAN_TOKEN are required")
 	}
 
-	instance, err := jira.New(nil, host)
+	instance, err := jira.New(atlassianHTTPClient(), host)
 	if err != nil {
 		log.Fatal(errors.WithMessage(err, "failed to create jira client"))
 	}
@@ -56,7 +83,7 @@ var JiraClient = sync.OnceValue(func() *jira.Client {
 var AgileClient = sync.OnceValue(func() *agile.Client {
 	host, mail, token := loadAtlassianCredentials()
 
-	instance, err := agile.New(nil, host)
+	instance, err := agile.New(atlassianHTTPClient(), host)
 	if err != nil {
 		log.Fatal(errors.WithMessage(err, "failed to create agile client"))
 	}
@@ -1,9 +1,9 @@
 package services
 
 import (
-	"os"
 	"sync"
 
+	"github.com/athapong/aio-mcp/config"
 	"github.com/sashabaranov/go-openai"
 )
 
@@ -15,8 +15,9 @@ var (
 // DefaultDeepseekClient returns a singleton instance of the Deepseek OpenAI client
 func DefaultDeepseekClient() *openai.Client {
 	deepseekOnce.Do(func() {
-		useOllama := os.Getenv("USE_OLLAMA_DEEPSEEK") == "true"
-		useOpenRouter := os.Getenv("USE_OPENROUTER") == "true"
+		cfg := config.Get()
+		useOllama := cfg.UseOllamaDeepseek
+		useOpenRouter := cfg.UseOpenRouter
 
 		if useOllama {
 			config := openai.DefaultConfig("not-needed")
@@ -26,7 +27,7 @@ func DefaultDeepseekClient() *openai.Client {
 		}
 
 		if useOpenRouter {
-			apiKey := os.Getenv("OPENROUTER_API_KEY")
+			apiKey := cfg.OpenRouterAPIKey
 			if apiKey == "" {
 				panic("OPENROUTER_API_KEY environment variable is not set")
 			}
@@ -34,22 +35,19 @@ func DefaultDeepseekClient() *openai.Client {
 			config := openai.DefaultConfig(apiKey)
 			config.BaseURL = "https://openrouter.ai/api/v1"
 			config.OrgID = "openrouter"
+			config.HTTPClient = RateLimitedHTTPClient("openrouter", openAIHTTPClient())
 			deepseekClient = openai.NewClientWithConfig(config)
 			return
 		}
 
-		apiKey := os.Getenv("DEEPSEEK_API_KEY")
+		apiKey := cfg.DeepseekAPIKey
 		if apiKey == "" {
 			panic("DEEPSEEK_API_KEY environment variable is not set")
 		}
 
-		baseURL := os.Getenv("DEEPSEEK_API_BASE")
-		if baseURL == "" {
-			baseURL = "https://api.deepseek.com/v1"
-		}
-
 		config := openai.DefaultConfig(apiKey)
-		config.BaseURL = baseURL
+		config.BaseURL = cfg.DeepseekAPIBase
+		config.HTTPClient = RateLimitedHTTPClient("deepseek", openAIHTTPClient())
 
 		deepseekClient = openai.NewClientWithConfig(config)
 	})
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/athapong/aio-mcp/config"
+	"github.com/sashabaranov/go-openai"
+)
+
+// FallbackChatClient returns an OpenAI-compatible client for a provider of an AI
+// fallback chain, and the model to use when the chain entry names none. Unlike
+// the primary clients it reports missing credentials as an error, so the chain
+// can move on to the next provider.
+func FallbackChatClient(provider string) (*openai.Client, string, error) {
+	cfg := config.Get()
+	switch provider {
+	case "deepseek":
+		if cfg.DeepseekAPIKey == "" {
+			return nil, "", fmt.Errorf("DEEPSEEK_API_KEY is not set")
+		}
+		clientConfig := openai.DefaultConfig(cfg.DeepseekAPIKey)
+		clientConfig.BaseURL = cfg.DeepseekAPIBase
+		clientConfig.HTTPClient = RateLimitedHTTPClient("deepseek", openAIHTTPClient())
+		return openai.NewClientWithConfig(clientConfig), "deepseek-chat", nil
+
+	case "openrouter":
+		if cfg.OpenRouterAPIKey == "" {
+			return nil, "", fmt.Errorf("OPENROUTER_API_KEY is not set")
+		}
+		clientConfig := openai.DefaultConfig(cfg.OpenRouterAPIKey)
+		clientConfig.BaseURL = "https://openrouter.ai/api/v1"
+		clientConfig.OrgID = "openrouter"
+		clientConfig.HTTPClient = RateLimitedHTTPClient("openrouter", openAIHTTPClient())
+		return openai.NewClientWithConfig(clientConfig), "deepseek/deepseek-r1-distill-qwen-32b", nil
+
+	case "ollama":
+		clientConfig := openai.DefaultConfig("not-needed")
+		clientConfig.BaseURL = strings.TrimSuffix(cfg.OllamaURL, "/") + "/v1"
+		return openai.NewClientWithConfig(clientConfig), "deepseek-r1:1.5b", nil
+
+	case "openai":
+		if cfg.OpenAIAPIKey == "" {
+			return nil, "", fmt.Errorf("OPENAI_API_KEY is not set")
+		}
+		return DefaultOpenAIClient(), "gpt-4o-mini", nil
+
+	default:
+		return nil, "", fmt.Errorf("provider %s has no OpenAI-compatible client", provider)
+	}
+}
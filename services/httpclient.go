@@ -2,17 +2,21 @@ package services
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
 	"sync"
+
+	"github.com/athapong/aio-mcp/config"
 )
 
 var DefaultHttpClient = sync.OnceValue(func() *http.Client {
 	transport := &http.Transport{}
 
-	proxyURL := os.Getenv("PROXY_URL")
+	proxyURL := config.Get().ProxyURL
 	if proxyURL != "" {
 		proxy, err := url.Parse(proxyURL)
 		if err != nil {
@@ -25,3 +29,37 @@ var DefaultHttpClient = sync.OnceValue(func() *http.Client {
 
 	return &http.Client{Transport: transport}
 })
+
+// NewTLSHTTPClient returns an HTTP client for a self-hosted service. Certificates
+// in the PEM file caCertFile are trusted in addition to the system roots, and
+// insecure disables certificate verification entirely. When neither is set the
+// default client is returned.
+func NewTLSHTTPClient(caCertFile string, insecure bool) (*http.Client, error) {
+	if caCertFile == "" && !insecure {
+		return http.DefaultClient, nil
+	}
+
+	tlsConfig := &tls.Config{}
+	if caCertFile != "" {
+		pem, err := os.ReadFile(caCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate %s: %v", caCertFile, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in %s", caCertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if insecure {
+		slog.Warn("TLS certificate verification is disabled for this client; do not use this outside development")
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport}, nil
+}
@@ -1,20 +1,39 @@
 package services
 
 import (
-	"os"
+	"net/http"
+	"net/url"
 	"sync"
 
+	"github.com/athapong/aio-mcp/config"
 	"github.com/sashabaranov/go-openai"
 )
 
+// openAIHTTPClient is the HTTP client of the OpenAI-compatible clients. It goes
+// through OPENAI_PROXY when set and otherwise honors HTTP_PROXY, HTTPS_PROXY and
+// NO_PROXY, and applies OPENAI_TIMEOUT to each request.
+var openAIHTTPClient = sync.OnceValue(func() *http.Client {
+	cfg := config.Get()
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.OpenAIProxy != "" {
+		// Validated when the configuration is loaded
+		proxy, _ := url.Parse(cfg.OpenAIProxy)
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	return &http.Client{Transport: transport, Timeout: cfg.OpenAITimeout}
+})
+
 var DefaultOpenAIClient = sync.OnceValue(func() *openai.Client {
-	apiKey := os.Getenv("OPENAI_API_KEY")
+	cfg := config.Get()
+	apiKey := cfg.OpenAIAPIKey
 	if apiKey == "" {
 		panic("OPENAI_API_KEY is not set, please set it in MCP Config")
 	}
 
-	baseURL := os.Getenv("OPENAI_BASE_URL")
+	baseURL := cfg.OpenAIBaseURL
 	config := openai.DefaultConfig(apiKey)
+	config.HTTPClient = RateLimitedHTTPClient("openai", openAIHTTPClient())
+	config.OrgID = cfg.OpenAIOrgID
 
 	if baseURL != "" {
 		config.BaseURL = baseURL
@@ -22,3 +41,26 @@ var DefaultOpenAIClient = sync.OnceValue(func() *openai.Client {
 
 	return openai.NewClientWithConfig(config)
 })
+
+// EmbeddingOpenAIClient is the client used for RAG embeddings. It targets
+// RAG_EMBEDDING_BASE_URL / RAG_EMBEDDING_API_KEY when either is set, so embeddings
+// can come from a different provider than chat, and is DefaultOpenAIClient otherwise.
+var EmbeddingOpenAIClient = sync.OnceValue(func() *openai.Client {
+	cfg := config.Get()
+	if cfg.RAGEmbeddingBaseURL == "" && cfg.RAGEmbeddingAPIKey == "" {
+		return DefaultOpenAIClient()
+	}
+
+	config := openai.DefaultConfig(cfg.RAGEmbeddingAPIKey)
+	if cfg.RAGEmbeddingAPIKey == "" {
+		// The organization belongs to the OpenAI key, so it follows the key
+		config = openai.DefaultConfig(cfg.OpenAIAPIKey)
+		config.OrgID = cfg.OpenAIOrgID
+	}
+	config.HTTPClient = RateLimitedHTTPClient("openai", openAIHTTPClient())
+	if cfg.RAGEmbeddingBaseURL != "" {
+		config.BaseURL = cfg.RAGEmbeddingBaseURL
+	}
+
+	return openai.NewClientWithConfig(config)
+})
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+
+	"github.com/athapong/aio-mcp/config"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+)
+
+// rateLimiters holds a token bucket for every provider with a configured
+// <PROVIDER>_RPS, shared by all calls to that provider
+var rateLimiters = sync.OnceValue(func() map[string]*rate.Limiter {
+	limiters := make(map[string]*rate.Limiter)
+	for provider, rps := range config.Get().RateLimits {
+		burst := int(math.Ceil(rps))
+		if burst < 1 {
+			burst = 1
+		}
+		limiters[provider] = rate.NewLimiter(rate.Limit(rps), burst)
+	}
+	return limiters
+})
+
+// WaitRateLimit blocks until a call to provider is allowed by its rate limit, or
+// ctx is done. Providers without a configured limit are never delayed.
+func WaitRateLimit(ctx context.Context, provider string) error {
+	limiter, ok := rateLimiters()[provider]
+	if !ok {
+		return nil
+	}
+	return limiter.Wait(ctx)
+}
+
+// rateLimitedTransport waits for the provider's rate limit before each request
+type rateLimitedTransport struct {
+	provider string
+	base     http.RoundTripper
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := WaitRateLimit(req.Context(), t.provider); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// RateLimitedHTTPClient returns a copy of client whose requests wait for the
+// provider's rate limit, or client itself when the provider has no limit
+func RateLimitedHTTPClient(provider string, client *http.Client) *http.Client {
+	if _, ok := rateLimiters()[provider]; !ok {
+		return client
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	limited := *client
+	limited.Transport = &rateLimitedTransport{provider: provider, base: base}
+	return &limited
+}
+
+// RateLimitUnaryInterceptor makes gRPC calls wait for the provider's rate limit
+func RateLimitUnaryInterceptor(provider string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := WaitRateLimit(ctx, provider); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// withRateLimit replaces the configured limiters with one for provider for one test
+func withRateLimit(t *testing.T, provider string, rps float64) {
+	t.Helper()
+	original := rateLimiters
+	limiters := map[string]*rate.Limiter{provider: rate.NewLimiter(rate.Limit(rps), 1)}
+	rateLimiters = func() map[string]*rate.Limiter { return limiters }
+	t.Cleanup(func() { rateLimiters = original })
+}
+
+func TestRateLimitedHTTPClientPacesRequests(t *testing.T) {
+	withRateLimit(t, "test", 20)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	client := RateLimitedHTTPClient("test", server.Client())
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	// The first request uses the burst, the other three wait 50ms each
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Fatalf("4 requests at 20 rps took %s, want at least 150ms", elapsed)
+	}
+}
+
+func TestRateLimitedHTTPClientWithoutLimit(t *testing.T) {
+	withRateLimit(t, "test", 20)
+
+	client := &http.Client{}
+	if RateLimitedHTTPClient("other", client) != client {
+		t.Fatal("a provider without a limit got a wrapped client")
+	}
+	if err := WaitRateLimit(context.Background(), "other"); err != nil {
+		t.Fatalf("WaitRateLimit without a limit = %v", err)
+	}
+}
+
+func TestWaitRateLimitStopsWhenContextIsDone(t *testing.T) {
+	withRateLimit(t, "test", 0.1)
+
+	if err := WaitRateLimit(context.Background(), "test"); err != nil {
+		t.Fatalf("first call = %v, want it allowed by the burst", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := WaitRateLimit(ctx, "test"); err == nil {
+		t.Fatal("WaitRateLimit waited past the context deadline")
+	}
+}
@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/athapong/aio-mcp/config"
+	"github.com/athapong/aio-mcp/util"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// aiResponseCache caches AI responses keyed on a hash of model and prompt. It is
+// disabled unless AI_RESPONSE_CACHE_TTL is set (e.g. "10m"); AI_RESPONSE_CACHE_SIZE
+// bounds the number of entries.
+var aiResponseCache = sync.OnceValue(func() *util.Cache[string, aiCachedAnswer] {
+	cfg := config.Get()
+	return util.NewCache[string, aiCachedAnswer](cfg.AIResponseCacheTTL, cfg.AIResponseCacheSize)
+})
+
+// aiCachedAnswer is a cached answer with the usage footer of the request that
+// produced it, so include_usage still reports usage on a cache hit
+type aiCachedAnswer struct {
+	answer string
+	usage  string
+}
+
+// aiCacheKey hashes the model and prompt parts into a cache key
+func aiCacheKey(model string, parts ...string) string {
+	hash := sha256.New()
+	hash.Write([]byte(model))
+	for _, part := range parts {
+		hash.Write([]byte{0})
+		hash.Write([]byte(part))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// cachedAIResult returns a tool result for a cached answer, noting that it came
+// from the cache. With includeUsage the usage of the original request is
+// appended, since the cached response itself used no tokens.
+func cachedAIResult(key string, includeUsage bool) (*mcp.CallToolResult, bool) {
+	cached, ok := aiResponseCache().Get(key)
+	if !ok {
+		return nil, false
+	}
+
+	text := cached.answer + "\n\n(cached response)"
+	if includeUsage && cached.usage != "" {
+		text += cached.usage + " (original request, the cached response used no tokens)"
+	}
+	return mcp.NewToolResultText(text), true
+}
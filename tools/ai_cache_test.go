@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/athapong/aio-mcp/util"
+	"github.com/sashabaranov/go-openai"
+)
+
+// fakeChatClient answers every chat completion with the same text and counts the calls
+type fakeChatClient struct {
+	answer string
+	calls  int
+}
+
+func (f *fakeChatClient) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	f.calls++
+	return openai.ChatCompletionResponse{
+		Model:   request.Model,
+		Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: f.answer}}},
+		Usage:   openai.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	}, nil
+}
+
+// withFakeDeepseek replaces the Deepseek client and the AI response cache for one test
+func withFakeDeepseek(t *testing.T, fake *fakeChatClient) {
+	t.Helper()
+	cache := util.NewCache[string, aiCachedAnswer](time.Minute, 10)
+	originalClient, originalCache := deepseekClient, aiResponseCache
+	deepseekClient = func() chatCompletionClient { return fake }
+	aiResponseCache = func() *util.Cache[string, aiCachedAnswer] { return cache }
+	t.Cleanup(func() {
+		deepseekClient, aiResponseCache = originalClient, originalCache
+	})
+}
+
+func TestCallDeepseekAPICachesIdenticalCalls(t *testing.T) {
+	fake := &fakeChatClient{answer: "42"}
+	withFakeDeepseek(t, fake)
+
+	messages := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: "Context:\nmath"},
+		{Role: openai.ChatMessageRoleUser, Content: "What is 6 x 7?"},
+	}
+
+	first, err := callDeepseekAPI(messages, "deepseek-reasoner", false)
+	if err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	second, err := callDeepseekAPI(messages, "deepseek-reasoner", false)
+	if err != nil {
+		t.Fatalf("second call: %v", err)
+	}
+
+	if fake.calls != 1 {
+		t.Fatalf("API called %d times, want 1", fake.calls)
+	}
+	if text := toolResultText(first); text != "42" {
+		t.Fatalf("first result = %q, want %q", text, "42")
+	}
+	if text := toolResultText(second); !strings.HasPrefix(text, "42") || !strings.Contains(text, "(cached response)") {
+		t.Fatalf("second result = %q, want the cached answer", text)
+	}
+}
+
+func TestCallDeepseekAPIDoesNotShareAnswersAcrossModels(t *testing.T) {
+	fake := &fakeChatClient{answer: "42"}
+	withFakeDeepseek(t, fake)
+
+	messages := []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "What is 6 x 7?"}}
+	for _, model := range []string{"deepseek-reasoner", "deepseek-chat"} {
+		if _, err := callDeepseekAPI(messages, model, false); err != nil {
+			t.Fatalf("call with %s: %v", model, err)
+		}
+	}
+
+	if fake.calls != 2 {
+		t.Fatalf("API called %d times, want 2", fake.calls)
+	}
+}
+
+func TestCallDeepseekAPIReportsUsageOnCacheHit(t *testing.T) {
+	fake := &fakeChatClient{answer: "42"}
+	withFakeDeepseek(t, fake)
+
+	messages := []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "What is 6 x 7?"}}
+	if _, err := callDeepseekAPI(messages, "deepseek-reasoner", false); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	cached, err := callDeepseekAPI(messages, "deepseek-reasoner", true)
+	if err != nil {
+		t.Fatalf("second call: %v", err)
+	}
+
+	text := toolResultText(cached)
+	if !strings.Contains(text, "total_tokens=15") || !strings.Contains(text, "the cached response used no tokens") {
+		t.Fatalf("cached result = %q, want the original usage", text)
+	}
+}
@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/athapong/aio-mcp/config"
+	"github.com/athapong/aio-mcp/services"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/genai"
+)
+
+// withAIFallback walks the fallback chain configured for tool when the primary
+// provider's result is an error, returning the first answer a fallback gives.
+// Answers served by a fallback name its provider and model and the providers
+// that failed; results of the primary provider, and errors when there is no
+// chain, are returned unchanged.
+func withAIFallback(ctx context.Context, tool, primary string, messages []openai.ChatCompletionMessage, result *mcp.CallToolResult) *mcp.CallToolResult {
+	if !result.IsError {
+		return result
+	}
+	chain := config.Get().AIFallbackChain(tool)
+	if len(chain) == 0 {
+		return result
+	}
+
+	failures := []string{fmt.Sprintf("%s: %s", primary, toolResultText(result))}
+	for _, fallback := range chain {
+		content, model, err := fallbackChat(ctx, fallback, messages)
+		name := fmt.Sprintf("%s (%s)", fallback.Provider, model)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		return mcp.NewToolResultText(content + servedByFooter(name, failures))
+	}
+
+	return mcp.NewToolResultError("all providers failed:\n- " + strings.Join(failures, "\n- "))
+}
+
+// fallbackChat sends messages to one provider of a fallback chain and returns
+// the answer and the model that produced it
+func fallbackChat(ctx context.Context, fallback config.AIFallback, messages []openai.ChatCompletionMessage) (string, string, error) {
+	if fallback.Provider == "gemini" {
+		model := fallback.Model
+		if model == "" {
+			model = "gemini-2.0-flash"
+		}
+		content, err := geminiChat(ctx, model, messages)
+		return content, model, err
+	}
+
+	client, model, err := services.FallbackChatClient(fallback.Provider)
+	if err != nil {
+		return "", fallback.Model, err
+	}
+	if fallback.Model != "" {
+		model = fallback.Model
+	}
+
+	resp, err := client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:    model,
+		Messages: messages,
+	})
+	if err != nil {
+		return "", model, err
+	}
+	if len(resp.Choices) == 0 {
+		return "", model, fmt.Errorf("no response")
+	}
+	return resp.Choices[0].Message.Content, model, nil
+}
+
+// geminiChat sends chat messages to a Gemini model, passing system messages as
+// the system instruction
+func geminiChat(ctx context.Context, model string, messages []openai.ChatCompletionMessage) (string, error) {
+	if config.Get().GoogleAIAPIKey == "" {
+		return "", fmt.Errorf("GOOGLE_AI_API_KEY is not set")
+	}
+
+	var system, prompt []string
+	for _, message := range messages {
+		if message.Role == openai.ChatMessageRoleSystem {
+			system = append(system, message.Content)
+		} else {
+			prompt = append(prompt, message.Content)
+		}
+	}
+
+	generateConfig := &genai.GenerateContentConfig{}
+	if len(system) > 0 {
+		generateConfig.SystemInstruction = genai.Text(strings.Join(system, "\n\n")).ToContent()
+	}
+
+	resp, err := generateGeminiContent(ctx, model, genai.PartSlice{genai.Text(strings.Join(prompt, "\n\n"))}, generateConfig)
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return "", fmt.Errorf("no response")
+	}
+
+	var text strings.Builder
+	for _, part := range resp.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+	return text.String(), nil
+}
+
+// servedByFooter names the provider that answered, and the providers that
+// failed before it
+func servedByFooter(provider string, failures []string) string {
+	footer := "\n\n---\nServed by: " + provider
+	if len(failures) > 0 {
+		footer += "\nFailed providers:\n- " + strings.Join(failures, "\n- ")
+	}
+	return footer
+}
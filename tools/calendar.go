@@ -3,11 +3,11 @@ package tools
 import (
 	"context"
 	"fmt"
-	"os"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/athapong/aio-mcp/config"
 	"github.com/athapong/aio-mcp/services"
 	"github.com/athapong/aio-mcp/util"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -25,8 +25,20 @@ func RegisterCalendarTools(s *server.MCPServer) {
 		mcp.WithString("start_time", mcp.Required(), mcp.Description("Start time of the event in RFC3339 format (e.g., 2023-12-25T09:00:00Z)")),
 		mcp.WithString("end_time", mcp.Required(), mcp.Description("End time of the event in RFC3339 format")),
 		mcp.WithString("attendees", mcp.Description("Comma-separated list of attendee email addresses")),
+		mcp.WithString("idempotency_key", mcp.Description("Optional unique key; repeating a call with the same key returns the original result instead of creating a duplicate")),
+		util.WithDryRun(),
 	)
-	s.AddTool(createEventTool, util.ErrorGuard(calendarCreateEventHandler))
+	s.AddTool(createEventTool, util.Idempotent(util.ErrorGuard(util.DryRunGuard("create calendar event", "POST /calendars/primary/events", calendarCreateEventHandler))))
+
+	// Quick add tool
+	quickAddTool := mcp.NewTool("calendar_quick_add",
+		mcp.WithDescription("Create an event in Google Calendar from a natural language description, e.g. 'Lunch with Sam tomorrow 12pm'"),
+		mcp.WithString("text", mcp.Required(), mcp.Description("Free text describing the event, including its time")),
+		mcp.WithString("calendar_id", mcp.Description("ID of the calendar to add the event to (default: primary)")),
+		mcp.WithString("idempotency_key", mcp.Description("Optional unique key; repeating a call with the same key returns the original result instead of creating a duplicate")),
+		util.WithDryRun(),
+	)
+	s.AddTool(quickAddTool, util.Idempotent(util.ErrorGuard(util.DryRunGuard("quick add calendar event", "POST /calendars/{calendar_id}/events/quickAdd", calendarQuickAddHandler))))
 
 	// List events tool
 	listEventsTool := mcp.NewTool("calendar_list_events",
@@ -46,27 +58,29 @@ func RegisterCalendarTools(s *server.MCPServer) {
 		mcp.WithString("start_time", mcp.Description("New start time of the event in RFC3339 format")),
 		mcp.WithString("end_time", mcp.Description("New end time of the event in RFC3339 format")),
 		mcp.WithString("attendees", mcp.Description("Comma-separated list of new attendee email addresses")),
+		util.WithDryRun(),
 	)
-	s.AddTool(updateEventTool, util.ErrorGuard(calendarUpdateEventHandler))
+	s.AddTool(updateEventTool, util.ErrorGuard(util.DryRunGuard("update calendar event", "PUT /calendars/primary/events/{event_id}", calendarUpdateEventHandler)))
 
 	// Respond to event tool
 	respondToEventTool := mcp.NewTool("calendar_respond_to_event",
 		mcp.WithDescription("Respond to an event invitation in Google Calendar"),
 		mcp.WithString("event_id", mcp.Required(), mcp.Description("ID of the event to respond to")),
 		mcp.WithString("response", mcp.Required(), mcp.Description("Your response (accepted, declined, or tentative)")),
+		util.WithDryRun(),
 	)
-	s.AddTool(respondToEventTool, util.ErrorGuard(calendarRespondToEventHandler))
+	s.AddTool(respondToEventTool, util.ErrorGuard(util.DryRunGuard("respond to calendar event", "PUT /calendars/primary/events/{event_id}", calendarRespondToEventHandler)))
 }
 
 var calendarService = sync.OnceValue(func() *calendar.Service {
 	ctx := context.Background()
 
-	tokenFile := os.Getenv("GOOGLE_TOKEN_FILE")
+	tokenFile := config.Get().GoogleTokenFile
 	if tokenFile == "" {
 		panic("GOOGLE_TOKEN_FILE environment variable must be set")
 	}
 
-	credentialsFile := os.Getenv("GOOGLE_CREDENTIALS_FILE")
+	credentialsFile := config.Get().GoogleCredentialsFile
 	if credentialsFile == "" {
 		panic("GOOGLE_CREDENTIALS_FILE environment variable must be set")
 	}
@@ -125,6 +139,50 @@ func calendarCreateEventHandler(ctx context.Context, request mcp.CallToolRequest
 	return mcp.NewToolResultText(fmt.Sprintf("Successfully created event with ID: %s", createdEvent.Id)), nil
 }
 
+func calendarQuickAddHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	text, _ := arguments["text"].(string)
+	if text == "" {
+		return mcp.NewToolResultError("text is required"), nil
+	}
+
+	calendarID, _ := arguments["calendar_id"].(string)
+	if calendarID == "" {
+		calendarID = "primary"
+	}
+
+	event, err := calendarService().Events.QuickAdd(calendarID, text).Context(ctx).Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to quick add event: %v", err)), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Successfully created event with ID: %s\n", event.Id))
+	result.WriteString(fmt.Sprintf("Event: %s\n", event.Summary))
+	result.WriteString(fmt.Sprintf("Start: %s\n", formatEventDateTime(event.Start)))
+	result.WriteString(fmt.Sprintf("End: %s\n", formatEventDateTime(event.End)))
+	if event.Location != "" {
+		result.WriteString(fmt.Sprintf("Location: %s\n", event.Location))
+	}
+	result.WriteString(fmt.Sprintf("Link: %s\n", event.HtmlLink))
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// formatEventDateTime formats a timed or all-day event boundary
+func formatEventDateTime(dt *calendar.EventDateTime) string {
+	if dt == nil {
+		return ""
+	}
+	if dt.DateTime != "" {
+		if t, err := time.Parse(time.RFC3339, dt.DateTime); err == nil {
+			return t.Format("2006-01-02 15:04")
+		}
+		return dt.DateTime
+	}
+	return dt.Date + " (all day)"
+}
+
 func calendarListEventsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	arguments := request.Params.Arguments
 	timeMinStr, ok := arguments["time_min"].(string)
@@ -1,14 +1,22 @@
 package tools
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"html"
+	"io"
+	"net/http"
 	"net/url"
+	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/athapong/aio-mcp/config"
 	"github.com/athapong/aio-mcp/pkg/adf"
 	"github.com/athapong/aio-mcp/services"
 	"github.com/athapong/aio-mcp/util"
@@ -18,11 +26,21 @@ import (
 	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
+// confluencePageCache caches fetched pages by ID. TTL and size are configured
+// with CONFLUENCE_CACHE_TTL (e.g. "5m", "0" disables) and CONFLUENCE_CACHE_SIZE.
+var confluencePageCache = sync.OnceValue(func() *util.Cache[string, *models.PageScheme] {
+	cfg := config.Get()
+	return util.NewCache[string, *models.PageScheme](cfg.ConfluenceCacheTTL, cfg.ConfluenceCacheSize)
+})
+
 // registerConfluenceTool is a function that registers the confluence tools to the server
 func RegisterConfluenceTool(s *server.MCPServer) {
 	tool := mcp.NewTool("confluence_search",
 		mcp.WithDescription("Search Confluence"),
 		mcp.WithString("query", mcp.Required(), mcp.Description("Atlassian Confluence Query Language (CQL)")),
+		mcp.WithString("content_type", mcp.DefaultString("page"), mcp.Description("Content type to search (page/blogpost/comment/attachment)")),
+		mcp.WithString("space_keys", mcp.Description("Comma separated keys of the spaces to search in, e.g. ENG,OPS; results are grouped by space with per-space counts (default: all spaces)")),
+		util.WithCursorPagination(confluenceSearchPagination),
 	)
 
 	s.AddTool(tool, confluenceSearchHandler)
@@ -31,9 +49,19 @@ func RegisterConfluenceTool(s *server.MCPServer) {
 	pageTool := mcp.NewTool("confluence_get_page",
 		mcp.WithDescription("Get Confluence page content"),
 		mcp.WithString("page_id", mcp.Required(), mcp.Description("Confluence page ID")),
+		mcp.WithString("output_format", mcp.Description("Content format: markdown (default), text or adf (raw Atlas Doc Format JSON)")),
+		mcp.WithBoolean("include_comments", mcp.Description("Append the page's footer and inline comments as Markdown, with their author and timestamp")),
 	)
 	s.AddTool(pageTool, util.ErrorGuard(confluencePageHandler))
 
+	exportPageTool := mcp.NewTool("confluence_export_page",
+		mcp.WithDescription("Export a Confluence page to an HTML or PDF file and return its path"),
+		mcp.WithString("page_id", mcp.Required(), mcp.Description("Confluence page ID")),
+		mcp.WithString("format", mcp.DefaultString("html"), mcp.Enum("html", "pdf"), mcp.Description("Export format: html (rendered export view) or pdf (Confluence PDF export)")),
+		mcp.WithString("output_dir", mcp.Description("Directory to save the export in, defaults to OUTPUT_DIR or the current working directory")),
+	)
+	s.AddTool(exportPageTool, util.ErrorGuard(confluenceExportPageHandler))
+
 	// Add new tool for creating Confluence pages
 	createPageTool := mcp.NewTool("confluence_create_page",
 		mcp.WithDescription("Create a new Confluence page"),
@@ -41,8 +69,9 @@ func RegisterConfluenceTool(s *server.MCPServer) {
 		mcp.WithString("title", mcp.Required(), mcp.Description("Title of the page")),
 		mcp.WithString("content", mcp.Required(), mcp.Description("Content of the page in storage format (XHTML)")),
 		mcp.WithString("parent_id", mcp.Description("ID of the parent page (optional)")),
+		util.WithDryRun(),
 	)
-	s.AddTool(createPageTool, util.ErrorGuard(confluenceCreatePageHandler))
+	s.AddTool(createPageTool, util.ErrorGuard(util.DryRunGuard("create Confluence page", "POST /wiki/api/v2/pages", confluenceCreatePageHandler)))
 
 	// Add new tool for updating Confluence pages
 	updatePageTool := mcp.NewTool("confluence_update_page",
@@ -51,9 +80,30 @@ func RegisterConfluenceTool(s *server.MCPServer) {
 		mcp.WithString("title", mcp.Description("New title of the page (optional)")),
 		mcp.WithString("content", mcp.Description("New content of the page in storage format (XHTML)")),
 		mcp.WithString("version_number", mcp.Description("Version number for optimistic locking (optional)")),
+		util.WithDryRun(),
 	)
 	s.AddTool(updatePageTool, util.ErrorGuard(confluenceUpdatePageHandler))
 
+	getLabelsTool := mcp.NewTool("confluence_get_labels",
+		mcp.WithDescription("Get the labels of a Confluence page"),
+		mcp.WithString("page_id", mcp.Required(), mcp.Description("Confluence page ID")),
+	)
+	s.AddTool(getLabelsTool, util.ErrorGuard(confluenceGetLabelsHandler))
+
+	addLabelsTool := mcp.NewTool("confluence_add_labels",
+		mcp.WithDescription("Add labels to a Confluence page. Labeled pages can be found with CQL, e.g. label = \"release-notes\""),
+		mcp.WithString("page_id", mcp.Required(), mcp.Description("Confluence page ID")),
+		mcp.WithString("labels", mcp.Required(), mcp.Description("Comma-separated list of labels to add")),
+		util.WithDryRun(),
+	)
+	s.AddTool(addLabelsTool, util.ErrorGuard(util.DryRunGuard("add labels to Confluence page", "POST /wiki/rest/api/content/{page_id}/label", confluenceAddLabelsHandler)))
+
+	validateContentTool := mcp.NewTool("confluence_validate_content",
+		mcp.WithDescription("Validate an Atlas Doc Format (ADF) JSON document before posting it to Confluence, reporting unsupported node or mark types, empty nodes and other problems with their location in the document"),
+		mcp.WithString("content", mcp.Required(), mcp.Description("ADF document as JSON, e.g. {\"version\": 1, \"type\": \"doc\", \"content\": [...]}")),
+	)
+	s.AddTool(validateContentTool, util.ErrorGuard(confluenceValidateContentHandler))
+
 	// Add new tool for comparing page versions
 	compareTool := mcp.NewTool("confluence_compare_versions",
 		mcp.WithDescription("Compare two versions of a Confluence page"),
@@ -64,6 +114,10 @@ func RegisterConfluenceTool(s *server.MCPServer) {
 	s.AddTool(compareTool, util.ErrorGuard(confluenceCompareHandler))
 }
 
+// confluenceSearchPagination is the default paging of confluence_search, which
+// fetches every matching page up to max_items unless told otherwise
+var confluenceSearchPagination = util.Pagination{PerPage: 20, FetchAll: true, MaxItems: 1000}
+
 // confluenceSearchHandler is a handler for the confluence search tool
 func confluenceSearchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	arguments := request.Params.Arguments
@@ -74,61 +128,162 @@ func confluenceSearchHandler(ctx context.Context, request mcp.CallToolRequest) (
 		return nil, fmt.Errorf("query argument is required")
 	}
 
+	contentType := "page"
+	if value, ok := arguments["content_type"].(string); ok && value != "" {
+		contentType = value
+	}
+	spaceKeys := splitPatterns(arguments["space_keys"])
+	switch contentType {
+	case "page":
+	case "blogpost", "comment", "attachment":
+		return confluenceSearchContent(ctx, contentType, query, spaceKeys)
+	default:
+		return nil, fmt.Errorf("invalid content_type %q: use page, blogpost, comment or attachment", contentType)
+	}
+
+	// Page search filters by space ID, so the keys are looked up first
+	spaceIDs, spaceKeysByID, err := confluenceSpaceIDs(ctx, spaceKeys)
+	if err != nil {
+		return nil, err
+	}
+
 	// Use the provided context
 	options := &models.PageOptionsScheme{
 		PageIDs:    nil,
-		SpaceIDs:   nil,
+		SpaceIDs:   spaceIDs,
 		Sort:       "created-date",
 		Status:     []string{"current"},
 		Title:      query, // Use query as title search
 		BodyFormat: "atlas_doc_format",
 	}
 
-	var results strings.Builder
-	var cursor string
-
-	for {
-		chunk, response, err := client.Page.Gets(ctx, options, cursor, 20)
+	p := util.ParsePagination(arguments, confluenceSearchPagination)
+	pages, next, err := util.Paginate(p.Cursor, p, func(cursor string, perPage int) ([]*models.PageScheme, string, error) {
+		chunk, response, err := client.Page.Gets(ctx, options, cursor, perPage)
 		if err != nil {
 			if response != nil {
-				return nil, fmt.Errorf("search failed with status %d: %v", response.Code, err)
+				return nil, "", fmt.Errorf("search failed with status %d: %v", response.Code, err)
 			}
-			return nil, fmt.Errorf("search failed: %v", err)
+			return nil, "", fmt.Errorf("search failed: %v", err)
+		}
+
+		// Check if there are more pages
+		if chunk.Links == nil || chunk.Links.Next == "" {
+			return chunk.Results, "", nil
 		}
 
-		// Process results
-		for _, page := range chunk.Results {
-			results.WriteString(fmt.Sprintf(`
+		// Parse next cursor from URL
+		next, err := url.Parse(chunk.Links.Next)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to parse next page URL: %v", err)
+		}
+		return chunk.Results, next.Query().Get("cursor"), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var results strings.Builder
+	groups := make(map[string]*strings.Builder)
+	counts := make(map[string]int)
+	for _, page := range pages {
+		entry := fmt.Sprintf(`
 Title: %s
 ID: %s
+Type: page
 Status: %s
 SpaceId: %s
 ----------------------------------------
 `,
-				page.Title,
-				page.ID,
-				page.Status,
-				page.SpaceID,
-			))
+			page.Title,
+			page.ID,
+			page.Status,
+			page.SpaceID,
+		)
+		if len(spaceKeys) == 0 {
+			results.WriteString(entry)
+			continue
+		}
+		key := spaceKeysByID[page.SpaceID]
+		if groups[key] == nil {
+			groups[key] = &strings.Builder{}
 		}
+		groups[key].WriteString(entry)
+		counts[key]++
+	}
+	writeSpaceGroups(&results, spaceKeys, groups, counts)
 
-		// Check if there are more pages
-		if chunk.Links == nil || chunk.Links.Next == "" {
-			break
+	if results.Len() == 0 {
+		results.WriteString("No results found")
+	}
+	results.WriteString(util.PaginationNote(next, "cursor"))
+
+	return mcp.NewToolResultText(results.String()), nil
+}
+
+// confluenceSearchContent searches blog posts, comments or attachments with CQL,
+// matching the query against their title and text
+func confluenceSearchContent(ctx context.Context, contentType, query string, spaceKeys []string) (*mcp.CallToolResult, error) {
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace
+	escaped := quote(query)
+	cql := fmt.Sprintf(`type = %s AND (title ~ "%s" OR text ~ "%s")`, contentType, escaped, escaped)
+	if len(spaceKeys) > 0 {
+		quoted := make([]string, len(spaceKeys))
+		for i, key := range spaceKeys {
+			quoted[i] = `"` + quote(key) + `"`
 		}
+		cql += fmt.Sprintf(" AND space IN (%s)", strings.Join(quoted, ", "))
+	}
 
-		// Parse next cursor from URL
-		values, err := url.ParseQuery(chunk.Links.Next)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse next page URL: %v", err)
+	page, response, err := services.ConfluenceV1Client().Search.Content(ctx, cql, &models.SearchContentOptions{Limit: 25})
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("search failed with status %d: %v", response.Code, err)
 		}
+		return nil, fmt.Errorf("search failed: %v", err)
+	}
 
-		if _, hasCursor := values["cursor"]; hasCursor {
-			cursor = values["cursor"][0]
-		} else {
-			break
+	var results strings.Builder
+	groups := make(map[string]*strings.Builder)
+	counts := make(map[string]int)
+	for _, result := range page.Results {
+		if result.Content == nil {
+			continue
+		}
+
+		spaceKey := ""
+		if result.Content.Space != nil {
+			spaceKey = result.Content.Space.Key
+		} else if result.ResultGlobalContainer != nil {
+			spaceKey = result.ResultGlobalContainer.Title
+		}
+
+		out := &results
+		if len(spaceKeys) > 0 {
+			if groups[spaceKey] == nil {
+				groups[spaceKey] = &strings.Builder{}
+			}
+			out = groups[spaceKey]
+			counts[spaceKey]++
 		}
+		out.WriteString(fmt.Sprintf(`
+Title: %s
+ID: %s
+Type: %s
+Status: %s
+Space: %s
+Excerpt: %s
+----------------------------------------
+`,
+			result.Title,
+			result.Content.ID,
+			result.Content.Type,
+			result.Content.Status,
+			spaceKey,
+			result.Excerpt,
+		))
 	}
+	writeSpaceGroups(&results, spaceKeys, groups, counts)
 
 	if results.Len() == 0 {
 		results.WriteString("No results found")
@@ -137,36 +292,114 @@ SpaceId: %s
 	return mcp.NewToolResultText(results.String()), nil
 }
 
+// confluenceSpaceIDs looks up the IDs of the spaces with the given keys,
+// returning them with a map from ID back to key. No keys returns nil.
+func confluenceSpaceIDs(ctx context.Context, spaceKeys []string) ([]int, map[string]string, error) {
+	if len(spaceKeys) == 0 {
+		return nil, nil, nil
+	}
+
+	spaces, response, err := services.ConfluenceClient().Space.Bulk(ctx, &models.GetSpacesOptionSchemeV2{Keys: spaceKeys}, "", len(spaceKeys))
+	if err != nil {
+		if response != nil {
+			return nil, nil, util.UpstreamError(err, response.Code, "failed to get spaces "+strings.Join(spaceKeys, ", "))
+		}
+		return nil, nil, fmt.Errorf("failed to get spaces %s: %v", strings.Join(spaceKeys, ", "), err)
+	}
+
+	var ids []int
+	keysByID := make(map[string]string)
+	for _, space := range spaces.Results {
+		id, err := strconv.Atoi(space.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid space ID %s: %v", space.ID, err)
+		}
+		ids = append(ids, id)
+		keysByID[space.ID] = space.Key
+	}
+
+	var missing []string
+	for _, key := range spaceKeys {
+		found := false
+		for _, known := range keysByID {
+			found = found || strings.EqualFold(known, key)
+		}
+		if !found {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, nil, util.NewToolError(util.ErrCodeNotFound, "spaces not found: %s", strings.Join(missing, ", "))
+	}
+	return ids, keysByID, nil
+}
+
+// writeSpaceGroups writes search results grouped by space, first the spaces
+// asked for in their order, then any other space a result came from, each
+// under a heading with its result count
+func writeSpaceGroups(results *strings.Builder, spaceKeys []string, groups map[string]*strings.Builder, counts map[string]int) {
+	if len(groups) == 0 {
+		return
+	}
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	results.WriteString(fmt.Sprintf("Results by space (%d total):\n", total))
+
+	written := make(map[string]bool)
+	writeGroup := func(key string) {
+		written[key] = true
+		results.WriteString(fmt.Sprintf("\n=== Space %s: %d result(s) ===\n", key, counts[key]))
+		if group := groups[key]; group != nil {
+			results.WriteString(group.String())
+		}
+	}
+
+	for _, requested := range spaceKeys {
+		key := requested
+		for groupKey := range groups {
+			if strings.EqualFold(groupKey, requested) {
+				key = groupKey
+			}
+		}
+		writeGroup(key)
+	}
+
+	var others []string
+	for key := range groups {
+		if !written[key] {
+			others = append(others, key)
+		}
+	}
+	sort.Strings(others)
+	for _, key := range others {
+		writeGroup(key)
+	}
+}
+
 func confluencePageHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	arguments := request.Params.Arguments
-	client := services.ConfluenceClient()
 
 	pageID, ok := arguments["page_id"].(string)
 	if !ok {
 		return nil, fmt.Errorf("page_id argument is required")
 	}
 
-	// Convert pageID to int
-	pageIDInt, err := strconv.Atoi(pageID)
-	if err != nil {
-		return nil, fmt.Errorf("invalid page ID: %v", err)
+	outputFormat := "markdown"
+	if format, ok := arguments["output_format"].(string); ok && format != "" {
+		outputFormat = format
 	}
-
-	ctxWithTimeout, cancel := context.WithTimeout(ctx, 4*time.Second)
-	defer cancel()
-
-	// Use new Page.Get method with atlas_doc_format, setting version to 0 to get latest
-	// Setting draft=true to get only published content, version=-1 to get the latest version
-	page, response, err := client.Page.Get(ctxWithTimeout, pageIDInt, "atlas_doc_format", false, -1)
-	if err != nil {
-		if response != nil {
-			return nil, fmt.Errorf("failed to get page: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
-		}
-		return nil, fmt.Errorf("failed to get page: %v", err)
+	switch outputFormat {
+	case "markdown", "text", "adf":
+	default:
+		return nil, fmt.Errorf("invalid output_format %q: use markdown, text or adf", outputFormat)
 	}
 
-	if page == nil {
-		return nil, fmt.Errorf("no content returned for page ID: %s", pageID)
+	page, err := getConfluencePage(ctx, pageID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Build response
@@ -186,11 +419,19 @@ func confluencePageHandler(ctx context.Context, request mcp.CallToolRequest) (*m
 	// Parse Atlas Doc Format content
 	var contentValue string
 	if page.Body != nil && page.Body.AtlasDocFormat != nil {
-		adfBody := &models.CommentNodeScheme{}
-		if err := json.Unmarshal([]byte(page.Body.AtlasDocFormat.Value), adfBody); err != nil {
-			return nil, fmt.Errorf("failed to parse ADF content: %v", err)
+		if outputFormat == "adf" {
+			contentValue = page.Body.AtlasDocFormat.Value
+		} else {
+			adfBody := &models.CommentNodeScheme{}
+			if err := json.Unmarshal([]byte(page.Body.AtlasDocFormat.Value), adfBody); err != nil {
+				return nil, fmt.Errorf("failed to parse ADF content: %v", err)
+			}
+			if outputFormat == "text" {
+				contentValue = extractTextFromADF(adfBody)
+			} else {
+				contentValue = convertADFToMarkdown(adfBody)
+			}
 		}
-		contentValue = convertADFToMarkdown(adfBody)
 	}
 
 	result.WriteString("\nContent:\n")
@@ -198,9 +439,334 @@ func confluencePageHandler(ctx context.Context, request mcp.CallToolRequest) (*m
 	result.WriteString(contentValue)
 	result.WriteString("\n----------------------------------------\n")
 
+	if includeComments, _ := arguments["include_comments"].(bool); includeComments {
+		comments, err := formatConfluenceComments(ctx, pageID)
+		if err != nil {
+			return nil, err
+		}
+		result.WriteString(comments)
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// confluenceCommentLimit caps how many comments of each kind are fetched for a page
+const confluenceCommentLimit = 250
+
+// confluenceComment is a footer or inline comment as returned by the v2 API,
+// which the client library does not cover
+type confluenceComment struct {
+	ID      string `json:"id"`
+	Version struct {
+		AuthorID  string `json:"authorId"`
+		CreatedAt string `json:"createdAt"`
+	} `json:"version"`
+	Body struct {
+		AtlasDocFormat *models.PageBodyRepresentationScheme `json:"atlas_doc_format"`
+	} `json:"body"`
+	ResolutionStatus string `json:"resolutionStatus"`
+	Properties       struct {
+		InlineOriginalSelection string `json:"inlineOriginalSelection"`
+	} `json:"properties"`
+}
+
+// formatConfluenceComments renders the footer and inline comments of a page as
+// Markdown, each headed by its author and timestamp
+func formatConfluenceComments(ctx context.Context, pageID string) (string, error) {
+	footer, err := getConfluenceComments(ctx, pageID, "footer")
+	if err != nil {
+		return "", err
+	}
+	inline, err := getConfluenceComments(ctx, pageID, "inline")
+	if err != nil {
+		return "", err
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("\nComments (%d):\n", len(footer)+len(inline)))
+
+	authors := make(map[string]string)
+	for _, group := range []struct {
+		kind     string
+		comments []confluenceComment
+	}{{"Footer", footer}, {"Inline", inline}} {
+		for _, comment := range group.comments {
+			author, ok := authors[comment.Version.AuthorID]
+			if !ok {
+				author = confluenceUserName(ctx, comment.Version.AuthorID)
+				authors[comment.Version.AuthorID] = author
+			}
+
+			result.WriteString("----------------------------------------\n")
+			result.WriteString(fmt.Sprintf("[%s] %s, %s", group.kind, author, comment.Version.CreatedAt))
+			if comment.ResolutionStatus != "" {
+				result.WriteString(fmt.Sprintf(" (%s)", comment.ResolutionStatus))
+			}
+			result.WriteString("\n")
+			if comment.Properties.InlineOriginalSelection != "" {
+				result.WriteString(fmt.Sprintf("> %s\n\n", comment.Properties.InlineOriginalSelection))
+			}
+
+			if comment.Body.AtlasDocFormat != nil {
+				adfBody := &models.CommentNodeScheme{}
+				if err := json.Unmarshal([]byte(comment.Body.AtlasDocFormat.Value), adfBody); err != nil {
+					return "", fmt.Errorf("failed to parse ADF content of comment %s: %v", comment.ID, err)
+				}
+				result.WriteString(strings.TrimSpace(convertADFToMarkdown(adfBody)))
+				result.WriteString("\n")
+			}
+		}
+	}
+	result.WriteString("----------------------------------------\n")
+
+	return result.String(), nil
+}
+
+// getConfluenceComments fetches the top-level comments of a page, kind being
+// "footer" or "inline"
+func getConfluenceComments(ctx context.Context, pageID, kind string) ([]confluenceComment, error) {
+	client := services.ConfluenceClient()
+	p := util.Pagination{PerPage: 100, FetchAll: true, MaxItems: confluenceCommentLimit}
+
+	comments, _, err := util.Paginate("", p, func(cursor string, perPage int) ([]confluenceComment, string, error) {
+		query := url.Values{"body-format": {"atlas_doc_format"}, "limit": {strconv.Itoa(perPage)}}
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+
+		req, err := client.NewRequest(ctx, http.MethodGet, fmt.Sprintf("wiki/api/v2/pages/%s/%s-comments?%s", url.PathEscape(pageID), kind, query.Encode()), "", nil)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create comments request: %v", err)
+		}
+
+		var page struct {
+			Results []confluenceComment `json:"results"`
+			Links   struct {
+				Next string `json:"next"`
+			} `json:"_links"`
+		}
+		response, err := client.Call(req, &page)
+		if err != nil {
+			if response != nil {
+				return nil, "", fmt.Errorf("failed to get %s comments: %s (endpoint: %s)", kind, response.Bytes.String(), response.Endpoint)
+			}
+			return nil, "", fmt.Errorf("failed to get %s comments: %v", kind, err)
+		}
+
+		next := ""
+		if page.Links.Next != "" {
+			if nextURL, err := url.Parse(page.Links.Next); err == nil {
+				next = nextURL.Query().Get("cursor")
+			}
+		}
+		return page.Results, next, nil
+	})
+	return comments, err
+}
+
+// confluenceUserName resolves an account ID to a display name, falling back to
+// the account ID when the user cannot be looked up
+func confluenceUserName(ctx context.Context, accountID string) string {
+	if accountID == "" {
+		return "Unknown"
+	}
+
+	client := services.ConfluenceV1Client()
+	req, err := client.NewRequest(ctx, http.MethodGet, "wiki/rest/api/user?accountId="+url.QueryEscape(accountID), "", nil)
+	if err != nil {
+		return accountID
+	}
+
+	var user struct {
+		DisplayName string `json:"displayName"`
+	}
+	if _, err := client.Call(req, &user); err != nil || user.DisplayName == "" {
+		return accountID
+	}
+	return user.DisplayName
+}
+
+func confluenceExportPageHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+
+	pageID, ok := arguments["page_id"].(string)
+	if !ok {
+		return nil, fmt.Errorf("page_id argument is required")
+	}
+
+	format := "html"
+	if value, ok := arguments["format"].(string); ok && value != "" {
+		format = value
+	}
+
+	page, err := getConfluencePage(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	switch format {
+	case "html":
+		data, err = exportConfluenceHTML(ctx, page)
+	case "pdf":
+		data, err = exportConfluencePDF(ctx, pageID)
+	default:
+		return nil, fmt.Errorf("invalid format %q: use html or pdf", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	outputDir, _ := arguments["output_dir"].(string)
+	fileName, err := util.OutputPath(outputDir, fmt.Sprintf("confluence_%s_%d.%s", pageID, time.Now().Unix(), format))
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(fileName, data, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write export: %v", err)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Exported page %q (%s) to %s", page.Title, pageID, fileName)), nil
+}
+
+// exportConfluenceHTML wraps the export view of the page, the HTML Confluence
+// renders for exports, in a standalone document
+func exportConfluenceHTML(ctx context.Context, page *models.PageScheme) ([]byte, error) {
+	version := 0
+	if page.Version != nil {
+		version = page.Version.Number
+	}
+
+	content, response, err := services.ConfluenceV1Client().Content.Get(ctx, page.ID, []string{"body.export_view"}, version)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get export view: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get export view: %v", err)
+	}
+	if content.Body == nil || content.Body.ExportView == nil {
+		return nil, fmt.Errorf("no export view returned for page ID: %s", page.ID)
+	}
+
+	title := html.EscapeString(page.Title)
+	document := fmt.Sprintf("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n<h1>%s</h1>\n%s\n</body>\n</html>\n",
+		title, title, content.Body.ExportView.Value)
+	return []byte(document), nil
+}
+
+// exportConfluencePDF downloads the page from the Confluence PDF export
+// endpoint. Instances without the endpoint answer with an HTML page instead of
+// a PDF, which is reported as an error.
+func exportConfluencePDF(ctx context.Context, pageID string) ([]byte, error) {
+	client := services.ConfluenceV1Client()
+	endpoint := "wiki/spaces/flyingpdf/pdfpageexport.action?pageId=" + url.QueryEscape(pageID)
+
+	req, err := client.NewRequest(ctx, http.MethodGet, endpoint, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PDF export request: %v", err)
+	}
+	req.Header.Set("Accept", "application/pdf")
+
+	resp, err := client.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("PDF export failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF export: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("PDF export failed with status %d", resp.StatusCode)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF")) {
+		return nil, fmt.Errorf("PDF export is not available on this Confluence instance, use format html instead")
+	}
+	return data, nil
+}
+
+// getConfluencePage fetches the latest version of a page in Atlas Doc Format,
+// serving it from the page cache when possible
+func getConfluencePage(ctx context.Context, pageID string) (*models.PageScheme, error) {
+	if page, cached := confluencePageCache().Get(pageID); cached {
+		return page, nil
+	}
+
+	// Convert pageID to int
+	pageIDInt, err := strconv.Atoi(pageID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page ID: %v", err)
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 4*time.Second)
+	defer cancel()
+
+	// Use new Page.Get method with atlas_doc_format, setting version to 0 to get latest
+	// Setting draft=true to get only published content, version=-1 to get the latest version
+	page, response, err := services.ConfluenceClient().Page.Get(ctxWithTimeout, pageIDInt, "atlas_doc_format", false, -1)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get page: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get page: %v", err)
+	}
+
+	if page == nil {
+		return nil, fmt.Errorf("no content returned for page ID: %s", pageID)
+	}
+
+	confluencePageCache().Set(pageID, page)
+	return page, nil
+}
+
+// confluencePageText returns the plain text content of a page
+func confluencePageText(page *models.PageScheme) (string, error) {
+	if page.Body == nil || page.Body.AtlasDocFormat == nil {
+		return "", nil
+	}
+
+	adfBody := &models.CommentNodeScheme{}
+	if err := json.Unmarshal([]byte(page.Body.AtlasDocFormat.Value), adfBody); err != nil {
+		return "", fmt.Errorf("failed to parse ADF content: %v", err)
+	}
+	return extractTextFromADF(adfBody), nil
+}
+
+func confluenceValidateContentHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	content, _ := request.Params.Arguments["content"].(string)
+	if strings.TrimSpace(content) == "" {
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "content argument is required")
+	}
+
+	problems := adf.ValidateJSON([]byte(content))
+	if len(problems) == 0 {
+		return mcp.NewToolResultText("Content is valid ADF"), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Found %d problem(s):\n", len(problems)))
+	for _, problem := range problems {
+		result.WriteString(fmt.Sprintf("- %s\n", problem))
+	}
 	return mcp.NewToolResultText(result.String()), nil
 }
 
+// checkADF validates an ADF body before it is sent, so content the API would
+// reject with an opaque 400 fails early with the problems and their locations
+func checkADF(body *models.CommentNodeScheme) error {
+	problems := adf.Validate(convertToADFNode(body))
+	if len(problems) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(problems))
+	for i, problem := range problems {
+		messages[i] = problem.String()
+	}
+	return util.NewToolError(util.ErrCodeInvalidArgument, "content is not valid ADF:\n- %s", strings.Join(messages, "\n- "))
+}
+
 // Helper function to convert ADF to markdown using our local implementation
 func convertADFToMarkdown(node *models.CommentNodeScheme) string {
 	if node == nil {
@@ -299,6 +865,10 @@ func confluenceCreatePageHandler(ctx context.Context, request mcp.CallToolReques
 		},
 	})
 
+	if err := checkADF(&body); err != nil {
+		return nil, err
+	}
+
 	// Convert ADF body to JSON string
 	bodyValue, err := json.Marshal(&body)
 	if err != nil {
@@ -386,6 +956,11 @@ func confluenceUpdatePageHandler(ctx context.Context, request mcp.CallToolReques
 			},
 		}
 
+		// Only the new content is checked; the existing body was accepted by Confluence
+		if err := checkADF(&models.CommentNodeScheme{Version: 1, Type: "doc", Content: []*models.CommentNodeScheme{contentNode}}); err != nil {
+			return nil, err
+		}
+
 		// Append new content to existing body
 		adfBody.AppendNode(contentNode)
 	}
@@ -432,6 +1007,17 @@ func confluenceUpdatePageHandler(ctx context.Context, request mcp.CallToolReques
 		payload.Version.Number = version
 	}
 
+	if util.IsDryRun(arguments) {
+		appended, _ := arguments["content"].(string)
+		return util.DryRunResult("update Confluence page", fmt.Sprintf("PUT /wiki/api/v2/pages/%d", pageIDInt), map[string]interface{}{
+			"id":               pageIDInt,
+			"title":            payload.Title,
+			"status":           payload.Status,
+			"version":          payload.Version.Number,
+			"appended_content": appended,
+		}), nil
+	}
+
 	// Update the page
 	updatedPage, response, err := client.Page.Update(ctx, pageIDInt, payload)
 	if err != nil {
@@ -441,6 +1027,8 @@ func confluenceUpdatePageHandler(ctx context.Context, request mcp.CallToolReques
 		return nil, fmt.Errorf("failed to update page: %v", err)
 	}
 
+	confluencePageCache().Delete(pageID)
+
 	result := fmt.Sprintf("Page updated successfully!\nTitle: %s\nID: %s\nStatus: %s\nVersion: %d",
 		updatedPage.Title,
 		updatedPage.ID,
@@ -451,6 +1039,66 @@ func confluenceUpdatePageHandler(ctx context.Context, request mcp.CallToolReques
 	return mcp.NewToolResultText(result), nil
 }
 
+func confluenceGetLabelsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	pageID, ok := arguments["page_id"].(string)
+	if !ok || pageID == "" {
+		return nil, fmt.Errorf("page_id argument is required")
+	}
+
+	labels, response, err := services.ConfluenceV1Client().Content.Label.Gets(ctx, pageID, "", 0, 200)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to get labels: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to get labels: %v", err)
+	}
+
+	return mcp.NewToolResultText(formatContentLabels(pageID, labels)), nil
+}
+
+func confluenceAddLabelsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	pageID, ok := arguments["page_id"].(string)
+	if !ok || pageID == "" {
+		return nil, fmt.Errorf("page_id argument is required")
+	}
+
+	labelsArg, _ := arguments["labels"].(string)
+	var payload []*models.ContentLabelPayloadScheme
+	for _, label := range strings.Split(labelsArg, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			payload = append(payload, &models.ContentLabelPayloadScheme{Prefix: "global", Name: label})
+		}
+	}
+	if len(payload) == 0 {
+		return nil, fmt.Errorf("labels argument is required")
+	}
+
+	labels, response, err := services.ConfluenceV1Client().Content.Label.Add(ctx, pageID, payload, false)
+	if err != nil {
+		if response != nil {
+			return nil, fmt.Errorf("failed to add labels: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, fmt.Errorf("failed to add labels: %v", err)
+	}
+
+	return mcp.NewToolResultText(formatContentLabels(pageID, labels)), nil
+}
+
+// formatContentLabels lists the label names of a page
+func formatContentLabels(pageID string, labels *models.ContentLabelPageScheme) string {
+	if labels == nil || len(labels.Results) == 0 {
+		return fmt.Sprintf("Page %s has no labels", pageID)
+	}
+
+	names := make([]string, 0, len(labels.Results))
+	for _, label := range labels.Results {
+		names = append(names, label.Name)
+	}
+	return fmt.Sprintf("Labels for page %s (%d): %s", pageID, len(names), strings.Join(names, ", "))
+}
+
 // Add this new handler function
 func confluenceCompareHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	arguments := request.Params.Arguments
@@ -0,0 +1,186 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/athapong/aio-mcp/services"
+	"github.com/athapong/aio-mcp/util"
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const defaultConfluenceIndexLimit = 50
+
+// RegisterConfluenceRagTool registers the tool that indexes Confluence pages into
+// RAG memory. It needs both the confluence and rag tool groups.
+func RegisterConfluenceRagTool(s *server.MCPServer) {
+	indexPageTool := mcp.NewTool("confluence_index_page",
+		mcp.WithDescription("Index a Confluence page, or every page in a space, into a RAG memory collection"),
+		withCollection("Memory collection name"),
+		mcp.WithString("page_id", mcp.Description("ID of the page to index")),
+		mcp.WithString("space_key", mcp.Description("Key of a space to index all pages of, used when page_id is not set")),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of pages to index from a space (default: 50)")),
+		mcp.WithString("model", mcp.Description("Embedding model to use (default: "+defaultEmbeddingModel()+")")),
+		withContextualize(),
+		util.WithDryRun(),
+	)
+	s.AddTool(indexPageTool, util.ErrorGuard(util.DryRunGuard("index Confluence pages", "PUT /collections/{collection}/points", confluenceIndexPageHandler)))
+}
+
+func confluenceIndexPageHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+
+	collection, err := collectionArgument(arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	modelStr := defaultEmbeddingModel()
+	if modelArg, ok := arguments["model"].(string); ok && modelArg != "" {
+		embModel, _, err := validateEmbeddingModel(modelArg)
+		if err != nil {
+			return nil, err
+		}
+		modelStr = string(embModel)
+	}
+
+	pageID, _ := arguments["page_id"].(string)
+	spaceKey, _ := arguments["space_key"].(string)
+
+	var pageIDs []string
+	switch {
+	case pageID != "":
+		pageIDs = []string{pageID}
+	case spaceKey != "":
+		limit := defaultConfluenceIndexLimit
+		if limitArg, ok := arguments["limit"].(float64); ok && limitArg > 0 {
+			limit = int(limitArg)
+		}
+
+		var err error
+		pageIDs, err = listConfluenceSpacePages(ctx, spaceKey, limit)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "either page_id or space_key is required")
+	}
+
+	opts := indexOptions{contextualize: shouldContextualize(arguments)}
+
+	var result strings.Builder
+	indexed := 0
+	for _, id := range pageIDs {
+		title, err := indexConfluencePage(ctx, collection, id, spaceKey, modelStr, opts)
+		if err != nil {
+			result.WriteString(fmt.Sprintf("Failed to index page %s: %v\n", id, err))
+			continue
+		}
+		indexed++
+		result.WriteString(fmt.Sprintf("Indexed page %s: %s\n", id, title))
+	}
+
+	if len(pageIDs) == 1 && indexed == 0 {
+		return nil, fmt.Errorf("failed to index page %s: %s", pageIDs[0], strings.TrimSpace(result.String()))
+	}
+
+	result.WriteString(fmt.Sprintf("\nIndexed %d of %d pages into collection %s with model %s", indexed, len(pageIDs), collection, modelStr))
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// indexConfluencePage indexes the plain text of a page, returning its title. Pages
+// are keyed as confluence:<page_id> so re-indexing replaces earlier chunks.
+func indexConfluencePage(ctx context.Context, collection, pageID, spaceKey, modelStr string, opts indexOptions) (string, error) {
+	page, err := getConfluencePage(ctx, pageID)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := confluencePageText(page)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(content) == "" {
+		return "", fmt.Errorf("page has no text content")
+	}
+
+	metadata := map[string]any{
+		"source":   "confluence",
+		"page_id":  page.ID,
+		"title":    page.Title,
+		"space_id": page.SpaceID,
+	}
+	if spaceKey != "" {
+		metadata["space_key"] = spaceKey
+	}
+	if page.Version != nil {
+		metadata["version"] = page.Version.Number
+	}
+
+	if _, err := upsertContent(ctx, collection, "confluence:"+page.ID, content, modelStr, metadata, opts); err != nil {
+		return "", err
+	}
+	return page.Title, nil
+}
+
+// listConfluenceSpacePages returns up to limit page IDs from the space with the given key
+func listConfluenceSpacePages(ctx context.Context, spaceKey string, limit int) ([]string, error) {
+	client := services.ConfluenceClient()
+
+	spaces, response, err := client.Space.Bulk(ctx, &models.GetSpacesOptionSchemeV2{Keys: []string{spaceKey}}, "", 1)
+	if err != nil {
+		if response != nil {
+			return nil, util.UpstreamError(err, response.Code, "failed to get space "+spaceKey)
+		}
+		return nil, fmt.Errorf("failed to get space %s: %v", spaceKey, err)
+	}
+	if len(spaces.Results) == 0 {
+		return nil, util.NewToolError(util.ErrCodeNotFound, "space %s not found", spaceKey)
+	}
+
+	spaceID, err := strconv.Atoi(spaces.Results[0].ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid space ID %s: %v", spaces.Results[0].ID, err)
+	}
+
+	var pageIDs []string
+	var cursor string
+	for len(pageIDs) < limit {
+		chunk, response, err := client.Page.GetsBySpace(ctx, spaceID, cursor, min(limit-len(pageIDs), 250))
+		if err != nil {
+			if response != nil {
+				return nil, util.UpstreamError(err, response.Code, "failed to list pages in space "+spaceKey)
+			}
+			return nil, fmt.Errorf("failed to list pages in space %s: %v", spaceKey, err)
+		}
+
+		for _, page := range chunk.Results {
+			pageIDs = append(pageIDs, page.ID)
+		}
+
+		// Check if there are more pages
+		if chunk.Links == nil || chunk.Links.Next == "" {
+			break
+		}
+
+		// Parse next cursor from URL
+		nextURL, err := url.Parse(chunk.Links.Next)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse next page URL: %v", err)
+		}
+		cursor = nextURL.Query().Get("cursor")
+		if cursor == "" {
+			break
+		}
+	}
+
+	if len(pageIDs) > limit {
+		pageIDs = pageIDs[:limit]
+	}
+	return pageIDs, nil
+}
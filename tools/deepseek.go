@@ -6,8 +6,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
 
+	"github.com/athapong/aio-mcp/config"
 	"github.com/athapong/aio-mcp/services"
 	"github.com/athapong/aio-mcp/util"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -15,6 +15,21 @@ import (
 	"github.com/sashabaranov/go-openai"
 )
 
+// chatCompletionClient is the part of an OpenAI-compatible client the AI tools
+// call, so tests can stand in for the API
+type chatCompletionClient interface {
+	CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error)
+}
+
+// deepseekClient returns the client deepseek_reasoning sends requests to, or
+// nil if it is not configured
+var deepseekClient = func() chatCompletionClient {
+	if client := services.DefaultDeepseekClient(); client != nil {
+		return client
+	}
+	return nil
+}
+
 type OllamaRequest struct {
 	Model    string    `json:"model"`
 	Messages []Message `json:"messages"`
@@ -35,6 +50,8 @@ func RegisterDeepseekTool(s *server.MCPServer) {
 		mcp.WithString("question", mcp.Required(), mcp.Description("The structured query or problem statement requiring deep analysis and reasoning")),
 		mcp.WithString("context", mcp.Required(), mcp.Description("Defines the operational context and purpose of the query within the MCP ecosystem")),
 		mcp.WithString("knowledge", mcp.Description("Provides relevant chat history, knowledge base entries, and structured data context for MCP-aware reasoning")),
+		mcp.WithString("model", mcp.Description("Model to use (default: deepseek-reasoner, or deepseek-r1:1.5b with USE_OLLAMA_DEEPSEEK)")),
+		mcp.WithBoolean("include_usage", mcp.Description("Append token usage (prompt/completion/total) and model to the result")),
 	)
 
 	s.AddTool(reasoningTool, util.ErrorGuard(deepseekReasoningHandler))
@@ -43,9 +60,18 @@ func RegisterDeepseekTool(s *server.MCPServer) {
 func deepseekReasoningHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	arguments := request.Params.Arguments
 	systemPrompt, question, _ := buildMessages(arguments)
+	fallbackMessages := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: systemPrompt},
+		{Role: openai.ChatMessageRoleUser, Content: question},
+	}
+	model, _ := arguments["model"].(string)
 
 	// Check if we should use Ollama
-	if useOllama := os.Getenv("USE_OLLAMA_DEEPSEEK"); useOllama == "true" {
+	if config.Get().UseOllamaDeepseek {
+		if model == "" {
+			model = "deepseek-r1:1.5b"
+		}
+
 		ollamaMessages := []Message{
 			{
 				Role:    "system",
@@ -58,11 +84,15 @@ func deepseekReasoningHandler(ctx context.Context, request mcp.CallToolRequest)
 		}
 
 		ollamaReq := OllamaRequest{
-			Model:    "deepseek-r1:1.5b",
+			Model:    model,
 			Messages: ollamaMessages,
 		}
 
-		return callOllamaDeepseek(ollamaReq)
+		result, err := callOllamaDeepseek(ollamaReq)
+		if err != nil {
+			return nil, err
+		}
+		return withAIFallback(ctx, "deepseek_reasoning", "ollama ("+ollamaReq.Model+")", fallbackMessages, result), nil
 	}
 
 	// Using Deepseek API
@@ -77,7 +107,20 @@ func deepseekReasoningHandler(ctx context.Context, request mcp.CallToolRequest)
 		},
 	}
 
-	return callDeepseekAPI(messages)
+	if model == "" {
+		model = "deepseek-reasoner"
+	}
+	includeUsage, _ := arguments["include_usage"].(bool)
+	result, err := callDeepseekAPI(messages, model, includeUsage)
+	if err != nil {
+		return nil, err
+	}
+
+	primary := fmt.Sprintf("deepseek (%s)", model)
+	if config.Get().UseOpenRouter {
+		primary = fmt.Sprintf("openrouter (%s)", model)
+	}
+	return withAIFallback(ctx, "deepseek_reasoning", primary, fallbackMessages, result), nil
 }
 
 func buildMessages(arguments map[string]interface{}) (string, string, string) {
@@ -90,20 +133,39 @@ func buildMessages(arguments map[string]interface{}) (string, string, string) {
 		systemPrompt += "\n\nAdditional Context:\n" + chatContext
 	}
 
-	return systemPrompt, question, chatContext
+	return withSystemPromptPrefix(systemPrompt), question, chatContext
+}
+
+// withSystemPromptPrefix prepends AI_SYSTEM_PROMPT_PREFIX, the organization's
+// guidance for all AI tools, to a generated system prompt
+func withSystemPromptPrefix(systemPrompt string) string {
+	prefix := config.Get().AISystemPromptPrefix
+	if prefix == "" {
+		return systemPrompt
+	}
+	return prefix + "\n\n" + systemPrompt
 }
 
-func callDeepseekAPI(messages []openai.ChatCompletionMessage) (*mcp.CallToolResult, error) {
+func callDeepseekAPI(messages []openai.ChatCompletionMessage, model string, includeUsage bool) (*mcp.CallToolResult, error) {
 	ctx := context.Background()
-	client := services.DefaultDeepseekClient()
+	client := deepseekClient()
 	if client == nil {
 		return mcp.NewToolResultError("Deepseek client not properly initialized"), nil
 	}
 
+	promptParts := make([]string, 0, len(messages)*2)
+	for _, message := range messages {
+		promptParts = append(promptParts, message.Role, message.Content)
+	}
+	cacheKey := aiCacheKey(model, promptParts...)
+	if result, ok := cachedAIResult(cacheKey, includeUsage); ok {
+		return result, nil
+	}
+
 	resp, err := client.CreateChatCompletion(
 		ctx,
 		openai.ChatCompletionRequest{
-			Model:       "deepseek-reasoner",
+			Model:       model,
 			Messages:    messages,
 			Temperature: 0.7,
 		},
@@ -117,7 +179,18 @@ func callDeepseekAPI(messages []openai.ChatCompletionMessage) (*mcp.CallToolResu
 		return mcp.NewToolResultError("no response from Deepseek"), nil
 	}
 
-	return mcp.NewToolResultText(resp.Choices[0].Message.Content), nil
+	content := resp.Choices[0].Message.Content
+	usage := usageFooter(resp.Model, int64(resp.Usage.PromptTokens), int64(resp.Usage.CompletionTokens), int64(resp.Usage.TotalTokens))
+	aiResponseCache().Set(cacheKey, aiCachedAnswer{answer: content, usage: usage})
+	if includeUsage {
+		content += usage
+	}
+	return mcp.NewToolResultText(content), nil
+}
+
+// usageFooter formats token usage as a compact footer appended to AI tool results
+func usageFooter(model string, promptTokens, completionTokens, totalTokens int64) string {
+	return fmt.Sprintf("\n\n---\nUsage: model=%s prompt_tokens=%d completion_tokens=%d total_tokens=%d", model, promptTokens, completionTokens, totalTokens)
 }
 
 func callOllamaDeepseek(req OllamaRequest) (*mcp.CallToolResult, error) {
@@ -126,12 +199,7 @@ func callOllamaDeepseek(req OllamaRequest) (*mcp.CallToolResult, error) {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal Ollama request: %s", err)), nil
 	}
 
-	ollamaURL := os.Getenv("OLLAMA_URL")
-	if ollamaURL == "" {
-		ollamaURL = "http://localhost:11434"
-	}
-
-	resp, err := http.Post(ollamaURL+"/api/chat", "application/json", bytes.NewBuffer(jsonData))
+	resp, err := http.Post(config.Get().OllamaURL+"/api/chat", "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to call Ollama: %s", err)), nil
 	}
@@ -4,18 +4,32 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
 
 	htmltomarkdownnnn "github.com/JohannesKaufmann/html-to-markdown/v2"
 
+	"github.com/athapong/aio-mcp/config"
 	"github.com/athapong/aio-mcp/services"
 	"github.com/athapong/aio-mcp/util"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
+// fetchCache caches converted page content keyed on method and URL. It is
+// disabled unless FETCH_CACHE_TTL is set (e.g. "2m"); FETCH_CACHE_SIZE bounds
+// the number of entries.
+var fetchCache = sync.OnceValue(func() *util.Cache[string, string] {
+	cfg := config.Get()
+	return util.NewCache[string, string](cfg.FetchCacheTTL, cfg.FetchCacheSize)
+})
+
 func RegisterFetchTool(s *server.MCPServer) {
 	tool := mcp.NewTool("get_web_content",
-		mcp.WithDescription("Fetches content from a given HTTP/HTTPS URL. This tool allows you to retrieve text content from web pages, APIs, or any accessible HTTP endpoints. Returns the raw content as text."),
+		mcp.WithDescription("Fetches content from a given HTTP/HTTPS URL. This tool allows you to retrieve text content from web pages, APIs, or any accessible HTTP endpoints. HTML pages are returned as Markdown, other content as raw text."),
 		mcp.WithString("url",
 			mcp.Required(),
 			mcp.Description("The complete HTTP/HTTPS URL to fetch content from (e.g., https://example.com)"),
@@ -32,6 +46,14 @@ func fetchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallTo
 		return mcp.NewToolResultError("url must be a string"), nil
 	}
 
+	// Requests carry no custom headers, so method and URL identify the response
+	cacheKey := http.MethodGet + " " + url
+	if content, ok := fetchCache().Get(cacheKey); ok {
+		hits, misses := fetchCache().Stats()
+		slog.Debug("fetch cache hit", "url", url, "hits", hits, "misses", misses)
+		return mcp.NewToolResultText(content), nil
+	}
+
 	resp, err := services.DefaultHttpClient().Get(url)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to fetch URL: %s", err)), nil
@@ -44,11 +66,31 @@ func fetchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallTo
 		return mcp.NewToolResultError(fmt.Sprintf("failed to read response body: %s", err)), nil
 	}
 
-	// Convert HTML content to Markdown
-	mdContent, err := htmltomarkdownnnn.ConvertString(string(body))
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to convert HTML to Markdown: %v", err)), nil
+	content := string(body)
+	if isHTMLContent(resp.Header.Get("Content-Type")) {
+		// Convert HTML content to Markdown
+		content, err = htmltomarkdownnnn.ConvertString(content)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to convert HTML to Markdown: %v", err)), nil
+		}
+	}
+
+	if resp.StatusCode < 300 && !strings.Contains(strings.ToLower(resp.Header.Get("Cache-Control")), "no-store") {
+		fetchCache().Set(cacheKey, content)
 	}
 
-	return mcp.NewToolResultText(mdContent), nil
+	return mcp.NewToolResultText(content), nil
+}
+
+// isHTMLContent reports whether a response of the given Content-Type should be
+// converted to Markdown. Responses without a Content-Type are assumed to be HTML.
+func isHTMLContent(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return true
+	}
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
 }
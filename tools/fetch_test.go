@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/athapong/aio-mcp/util"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// withFetchCache enables the fetch cache for one test
+func withFetchCache(t *testing.T) {
+	t.Helper()
+	original := fetchCache
+	cache := util.NewCache[string, string](time.Minute, 10)
+	fetchCache = func() *util.Cache[string, string] { return cache }
+	t.Cleanup(func() { fetchCache = original })
+}
+
+func fetchURL(t *testing.T, url string) *mcp.CallToolResult {
+	t.Helper()
+	var request mcp.CallToolRequest
+	request.Params.Name = "get_web_content"
+	request.Params.Arguments = map[string]interface{}{"url": url}
+	result, err := fetchHandler(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestFetchHandlerServesCachedURLWithoutNetwork(t *testing.T) {
+	withFetchCache(t)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<h1>Release notes</h1>"))
+	}))
+	defer server.Close()
+
+	first := toolResultText(fetchURL(t, server.URL))
+	second := toolResultText(fetchURL(t, server.URL))
+
+	if requests.Load() != 1 {
+		t.Fatalf("server got %d requests, want 1", requests.Load())
+	}
+	if first != "# Release notes" || second != first {
+		t.Fatalf("results = %q, %q, want the converted page twice", first, second)
+	}
+}
+
+func TestFetchHandlerDoesNotCacheUncacheableResponses(t *testing.T) {
+	withFetchCache(t)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	for _, path := range []string{"/no-store", "/missing"} {
+		fetchURL(t, server.URL+path)
+		fetchURL(t, server.URL+path)
+	}
+
+	if requests.Load() != 4 {
+		t.Fatalf("server got %d requests, want every call to reach it", requests.Load())
+	}
+}
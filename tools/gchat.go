@@ -23,10 +23,11 @@ func RegisterGChatTool(s *server.MCPServer) {
 		mcp.WithDescription("Send a message to a Google Chat space or direct message"),
 		mcp.WithString("space_name", mcp.Required(), mcp.Description("Name of the space to send the message to")),
 		mcp.WithString("message", mcp.Required(), mcp.Description("Text message to send")),
+		util.WithDryRun(),
 	)
 
 	s.AddTool(listSpacesTool, util.ErrorGuard(gChatListSpacesHandler))
-	s.AddTool(sendMessageTool, util.ErrorGuard(gChatSendMessageHandler))
+	s.AddTool(sendMessageTool, util.ErrorGuard(util.DryRunGuard("send Google Chat message", "POST /v1/{space_name}/messages", gChatSendMessageHandler)))
 }
 
 func gChatListSpacesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -2,14 +2,18 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/athapong/aio-mcp/config"
 	"github.com/athapong/aio-mcp/util"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/sashabaranov/go-openai"
 	"google.golang.org/genai"
 )
 
@@ -19,13 +23,16 @@ func RegisterGeminiTool(s *server.MCPServer) {
 		mcp.WithString("question", mcp.Required(), mcp.Description("The question to ask. Should be a question")),
 		// context
 		mcp.WithString("context", mcp.Required(), mcp.Description("Context/purpose of the question, helps Gemini to understand the question better")),
+		mcp.WithString("model", mcp.Description("Gemini model to use (default: gemini-2.0-pro-exp-02-05)")),
+		mcp.WithBoolean("include_usage", mcp.Description("Append token usage (prompt/completion/total) and model to the result")),
+		mcp.WithBoolean("enable_search", mcp.DefaultBool(true), mcp.Description("Ground the answer in Google Search results and list the source URLs; set to false to answer from the model alone")),
 	)
 
 	s.AddTool(searchTool, util.ErrorGuard(aiWebSearchHandler))
 }
 
 var genAiClient = sync.OnceValue(func() *genai.Client {
-	apiKey := os.Getenv("GOOGLE_AI_API_KEY")
+	apiKey := config.Get().GoogleAIAPIKey
 	if apiKey == "" {
 		panic("GOOGLE_AI_API_KEY environment variable must be set")
 	}
@@ -43,6 +50,46 @@ var genAiClient = sync.OnceValue(func() *genai.Client {
 	return client
 })
 
+// geminiRetryDelay is the delay before the first retry of a failed Gemini
+// request; GEMINI_MAX_ATTEMPTS bounds the attempts
+const geminiRetryDelay = time.Second
+
+// generateGeminiContent calls Gemini, retrying rate limits and server errors
+// with backoff. Each attempt is bounded by GEMINI_TIMEOUT when it is set.
+func generateGeminiContent(ctx context.Context, model string, parts genai.PartSlice, generateConfig *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	cfg := config.Get()
+	var resp *genai.GenerateContentResponse
+	err := util.Retry(ctx, cfg.GeminiMaxAttempts, geminiRetryDelay, func() error {
+		attemptCtx := ctx
+		if cfg.GeminiTimeout > 0 {
+			var cancel context.CancelFunc
+			attemptCtx, cancel = context.WithTimeout(ctx, cfg.GeminiTimeout)
+			defer cancel()
+		}
+
+		var err error
+		resp, err = genAiClient().Models.GenerateContent(attemptCtx, model, parts, generateConfig)
+		if err != nil {
+			return geminiError(err, "failed to generate content")
+		}
+		return nil
+	})
+	return resp, err
+}
+
+// geminiError wraps a Gemini API error, deriving the error code from its status
+func geminiError(err error, message string) error {
+	var clientErr genai.ClientError
+	if errors.As(err, &clientErr) {
+		return util.UpstreamError(err, clientErr.Code, message)
+	}
+	var serverErr genai.ServerError
+	if errors.As(err, &serverErr) {
+		return util.UpstreamError(err, serverErr.Code, message)
+	}
+	return util.WrapToolError(util.ErrCodeUpstream, err, message)
+}
+
 func aiWebSearchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	arguments := request.Params.Arguments
 	question, ok := arguments["question"].(string)
@@ -50,32 +97,61 @@ func aiWebSearchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.
 		return mcp.NewToolResultError("question must be a string"), nil
 	}
 
+	enableSearch := true
+	if value, ok := arguments["enable_search"].(bool); ok {
+		enableSearch = value
+	}
+
 	systemInstruction := "You are a search engine. You will search the web for the answer to the question. You will then provide the answer to the question. Always try to search the web for the answer first before providing the answer. writing style: short, concise, direct, and to the point."
+	if !enableSearch {
+		systemInstruction = "You answer questions from your own knowledge. writing style: short, concise, direct, and to the point."
+	}
 
 	questionContext, ok := arguments["context"].(string)
 	if !ok {
 		systemInstruction += "\n\nContext: " + questionContext
 	}
 
-	resp, err := genAiClient().Models.GenerateContent(ctx,
-		"gemini-2.0-pro-exp-02-05", //gemini-2.0-flash
+	systemInstruction = withSystemPromptPrefix(systemInstruction)
+
+	model, _ := arguments["model"].(string)
+	if model == "" {
+		model = "gemini-2.0-pro-exp-02-05"
+	}
+	primary := fmt.Sprintf("gemini (%s)", model)
+	messages := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: systemInstruction},
+		{Role: openai.ChatMessageRoleUser, Content: question},
+	}
+	includeUsage, _ := arguments["include_usage"].(bool)
+	cacheKey := aiCacheKey(model, systemInstruction, question, strconv.FormatBool(enableSearch))
+	if result, ok := cachedAIResult(cacheKey, includeUsage); ok {
+		return withAIFallback(ctx, "ai_web_search", primary, messages, result), nil
+	}
+
+	generateConfig := &genai.GenerateContentConfig{
+		SystemInstruction: genai.Text(systemInstruction).ToContent(),
+	}
+	if enableSearch {
+		generateConfig.Tools = []*genai.Tool{
+			{GoogleSearch: &genai.GoogleSearch{}},
+		}
+	}
+
+	resp, err := generateGeminiContent(ctx,
+		model,
 		genai.PartSlice{
 			genai.Text(question),
 		},
-		&genai.GenerateContentConfig{
-			SystemInstruction: genai.Text(systemInstruction).ToContent(),
-			Tools: []*genai.Tool{
-				{GoogleSearch: &genai.GoogleSearch{}},
-			},
-		},
+		generateConfig,
 	)
 
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to generate content: %s", err)), nil
+		return withAIFallback(ctx, "ai_web_search", primary, messages, mcp.NewToolResultError(err.Error())), nil
 	}
 
 	if len(resp.Candidates) == 0 {
-		return mcp.NewToolResultError("no response from Gemini"), nil
+		return withAIFallback(ctx, "ai_web_search", primary, messages, mcp.NewToolResultError("no response from Gemini")), nil
 	}
 
 	candidate := resp.Candidates[0]
@@ -109,7 +185,22 @@ func aiWebSearchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.
 				textBuilder.WriteString(chunk.Web.URI)
 			}
 		}
+
+		if len(candidate.GroundingMetadata.WebSearchQueries) > 0 {
+			textBuilder.WriteString("\n\nSearch queries: ")
+			textBuilder.WriteString(strings.Join(candidate.GroundingMetadata.WebSearchQueries, "; "))
+		}
+	}
+
+	var usage string
+	if resp.UsageMetadata != nil {
+		usage = usageFooter(model, resp.UsageMetadata.PromptTokenCount, resp.UsageMetadata.CandidatesTokenCount, resp.UsageMetadata.TotalTokenCount)
+	}
+	aiResponseCache().Set(cacheKey, aiCachedAnswer{answer: textBuilder.String(), usage: usage})
+
+	if includeUsage {
+		textBuilder.WriteString(usage)
 	}
 
-	return mcp.NewToolResultText(textBuilder.String()), nil
+	return withAIFallback(ctx, "ai_web_search", primary, messages, mcp.NewToolResultText(textBuilder.String())), nil
 }
@@ -1,17 +1,24 @@
 package tools
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	htmltomarkdownnnn "github.com/JohannesKaufmann/html-to-markdown/v2"
+	"github.com/athapong/aio-mcp/config"
+	"github.com/athapong/aio-mcp/services"
 	"github.com/athapong/aio-mcp/util"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -20,17 +27,27 @@ import (
 )
 
 var gitlabClient = sync.OnceValue(func() *gitlab.Client {
-	token := os.Getenv("GITLAB_TOKEN")
+	cfg := config.Get()
+	token := cfg.GitLabToken
 	if token == "" {
 		log.Fatal("GITLAB_TOKEN is required")
 	}
 
-	host := os.Getenv("GITLAB_HOST")
+	host := cfg.GitLabHost
 	if host == "" {
 		log.Fatal("GITLAB_HOST is required")
 	}
 
-	client, err := gitlab.NewClient(token, gitlab.WithBaseURL(host))
+	httpClient, err := services.NewTLSHTTPClient(cfg.GitLabCACert, cfg.GitLabInsecureSkipVerify)
+	if err != nil {
+		log.Fatal(errors.WithMessage(err, "failed to create gitlab http client"))
+	}
+	options := []gitlab.ClientOptionFunc{
+		gitlab.WithBaseURL(host),
+		gitlab.WithHTTPClient(services.RateLimitedHTTPClient("gitlab", httpClient)),
+	}
+
+	client, err := gitlab.NewClient(token, options...)
 	if err != nil {
 		log.Fatal(errors.WithMessage(err, "failed to create gitlab client"))
 	}
@@ -38,6 +55,53 @@ var gitlabClient = sync.OnceValue(func() *gitlab.Client {
 	return client
 })
 
+// gitlabPagination is the default paging of the GitLab list tools
+var gitlabPagination = util.Pagination{PerPage: 100, MaxItems: 1000}
+
+// mrDiffsPagination fetches every changed file of a merge request
+var mrDiffsPagination = util.Pagination{PerPage: 100, FetchAll: true, MaxItems: 3000}
+
+// releasesPagination is the default paging of gitlab_list_releases
+var releasesPagination = util.Pagination{PerPage: 20, MaxItems: 1000}
+
+// releaseNotesPagination fetches every commit since the previous release
+var releaseNotesPagination = util.Pagination{PerPage: 100, FetchAll: true, MaxItems: 3000}
+
+// paginateGitLab runs a GitLab list call over the pages selected by the paging
+// arguments, returning the items and the page to continue from, 0 when done
+func paginateGitLab[T any](arguments map[string]interface{}, defaults util.Pagination, list func(opt gitlab.ListOptions) ([]T, *gitlab.Response, error)) ([]T, int, error) {
+	p := util.ParsePagination(arguments, defaults)
+	return util.Paginate(p.Page, p, func(page, perPage int) ([]T, int, error) {
+		items, resp, err := list(gitlab.ListOptions{Page: page, PerPage: perPage})
+		if err != nil {
+			return nil, 0, err
+		}
+		return items, resp.NextPage, nil
+	})
+}
+
+// gitTLSEnv passes GITLAB_CA_CERT and GITLAB_INSECURE_SKIP_VERIFY on to git
+func gitTLSEnv() []string {
+	cfg := config.Get()
+	var env []string
+	if cfg.GitLabCACert != "" {
+		env = append(env, "GIT_SSL_CAINFO="+cfg.GitLabCACert)
+	}
+	if cfg.GitLabInsecureSkipVerify {
+		env = append(env, "GIT_SSL_NO_VERIFY=true")
+	}
+	return env
+}
+
+// gitlabError wraps a GitLab API error, deriving the error code from the response status
+func gitlabError(err error, message string) error {
+	var errResp *gitlab.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil {
+		return util.UpstreamError(err, errResp.Response.StatusCode, message)
+	}
+	return util.WrapToolError(util.ErrCodeUpstream, err, message)
+}
+
 // GitLabRepoCache manages temporary cloned repositories
 type GitLabRepoCache struct {
 	BaseDir string
@@ -45,14 +109,41 @@ type GitLabRepoCache struct {
 	mu      sync.Mutex
 }
 
+// gitlabProjectCache caches project metadata by project path, so flows that read
+// several files from one repository look the project up once. TTL and size are
+// configured with GITLAB_PROJECT_CACHE_TTL (e.g. "1m", "0" disables) and
+// GITLAB_PROJECT_CACHE_SIZE.
+var gitlabProjectCache = sync.OnceValue(func() *util.Cache[string, *gitlab.Project] {
+	cfg := config.Get()
+	return util.NewCache[string, *gitlab.Project](cfg.GitLabProjectCacheTTL, cfg.GitLabProjectCacheSize)
+})
+
+// getGitLabProject returns the project at projectPath, from the cache unless
+// forceRefresh is set
+func getGitLabProject(projectPath string, forceRefresh bool) (*gitlab.Project, error) {
+	if forceRefresh {
+		gitlabProjectCache().Delete(projectPath)
+	} else if project, cached := gitlabProjectCache().Get(projectPath); cached {
+		return project, nil
+	}
+
+	project, _, err := gitlabClient().Projects.GetProject(projectPath, nil)
+	if err != nil {
+		return nil, gitlabError(err, "failed to get project")
+	}
+	gitlabProjectCache().Set(projectPath, project)
+	return project, nil
+}
+
 var repoCache = &GitLabRepoCache{
 	BaseDir: filepath.Join(os.TempDir(), "gitlab-repos"),
 	Repos:   make(map[string]string),
 }
 
 // ensureRepo ensures the repository is cloned and up-to-date
-// ref can be a branch name, tag, or empty (for default branch)
-func (c *GitLabRepoCache) ensureRepo(projectPath string, ref string) (string, error) {
+// ref can be a branch name, tag, or empty (for default branch); forceRefresh
+// bypasses the cached project metadata
+func (c *GitLabRepoCache) ensureRepo(projectPath string, ref string, forceRefresh bool) (string, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -61,9 +152,9 @@ func (c *GitLabRepoCache) ensureRepo(projectPath string, ref string) (string, er
 	}
 
 	// Get repository URL and default branch
-	project, _, err := gitlabClient().Projects.GetProject(projectPath, nil)
+	project, err := getGitLabProject(projectPath, forceRefresh)
 	if err != nil {
-		return "", fmt.Errorf("failed to get project: %v", err)
+		return "", err
 	}
 
 	// If ref is empty, use default branch
@@ -85,13 +176,14 @@ func (c *GitLabRepoCache) ensureRepo(projectPath string, ref string) (string, er
 	}
 
 	cloneURL := project.HTTPURLToRepo
-	token := os.Getenv("GITLAB_TOKEN")
+	token := config.Get().GitLabToken
 	if token != "" {
 		cloneURL = strings.Replace(cloneURL, "https://", "https://oauth2:"+token+"@", 1)
 	}
 
 	// Clone repository
 	cmd := exec.Command("git", "clone", "--mirror", cloneURL, localPath)
+	cmd.Env = append(os.Environ(), gitTLSEnv()...)
 	if err := cmd.Run(); err != nil {
 		return "", fmt.Errorf("failed to clone repository: %v", err)
 	}
@@ -103,7 +195,7 @@ func (c *GitLabRepoCache) ensureRepo(projectPath string, ref string) (string, er
 		// Clean up on failure
 		os.RemoveAll(localPath)
 		delete(c.Repos, projectPath)
-		return "", fmt.Errorf("reference '%s' not found in repository: %v", ref, err)
+		return "", util.WrapToolError(util.ErrCodeNotFound, err, fmt.Sprintf("reference '%s' not found in repository", ref))
 	}
 
 	return localPath, nil
@@ -115,23 +207,58 @@ func RegisterGitLabTool(s *server.MCPServer) {
 		mcp.WithDescription("List GitLab projects"),
 		mcp.WithString("group_id", mcp.Required(), mcp.Description("gitlab group ID")),
 		mcp.WithString("search", mcp.Description("Multiple terms can be provided, separated by an escaped space, either + or %20, and will be ANDed together. Example: one+two will match substrings one and two (in any order).")),
+		util.WithPagePagination(gitlabPagination),
 	)
 
 	projectTool := mcp.NewTool("gitlab_get_project",
 		mcp.WithDescription("Get GitLab project details"),
 		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
+		mcp.WithBoolean("force_refresh", mcp.Description("Look the project up again instead of using its cached metadata")),
 	)
 
 	mrListTool := mcp.NewTool("gitlab_list_mrs",
 		mcp.WithDescription("List merge requests"),
 		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
 		mcp.WithString("state", mcp.DefaultString("all"), mcp.Description("MR state (opened/closed/merged)")),
+		util.WithPagePagination(gitlabPagination),
+	)
+
+	myReviewsTool := mcp.NewTool("gitlab_my_reviews",
+		mcp.WithDescription("List open merge requests across all accessible projects where a user is a reviewer or assignee, most recently updated first"),
+		mcp.WithString("username", mcp.Description("GitLab username (default: the user of the GitLab token)")),
 	)
 
 	mrDetailsTool := mcp.NewTool("gitlab_get_mr_details",
 		mcp.WithDescription("Get merge request details"),
 		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
 		mcp.WithString("mr_iid", mcp.Required(), mcp.Description("Merge request IID")),
+		mcp.WithBoolean("summary_only", mcp.Description("List changed files with added/removed line counts instead of full diffs")),
+	)
+
+	mrFilesTool := mcp.NewTool("gitlab_list_mr_files",
+		mcp.WithDescription("List the files changed by a merge request with their added/removed line counts, to pick which diffs to fetch with gitlab_get_mr_file_diff"),
+		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
+		mcp.WithString("mr_iid", mcp.Required(), mcp.Description("Merge request IID")),
+	)
+
+	mrFileDiffTool := mcp.NewTool("gitlab_get_mr_file_diff",
+		mcp.WithDescription("Get the diff of a single file changed by a merge request"),
+		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
+		mcp.WithString("mr_iid", mcp.Required(), mcp.Description("Merge request IID")),
+		mcp.WithString("file_path", mcp.Required(), mcp.Description("Path of the file in the repository, its old path for renamed or deleted files also works")),
+	)
+
+	mrApprovalRulesTool := mcp.NewTool("gitlab_get_mr_approval_rules",
+		mcp.WithDescription("Get the approval requirements of a merge request: whether it is approved, approvals required and left, who approved, and each approval rule with its eligible approvers"),
+		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
+		mcp.WithString("mr_iid", mcp.Required(), mcp.Description("Merge request IID")),
+	)
+
+	mrSummarizeTool := mcp.NewTool("gitlab_summarize_mr",
+		mcp.WithDescription("Summarize a merge request for reviewers with a chat model: its intent, risk areas and test coverage gaps, based on the diffs"),
+		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
+		mcp.WithString("mr_iid", mcp.Required(), mcp.Description("Merge request IID")),
+		mcp.WithString("model", mcp.Description("Chat model to use, defaults to MR_SUMMARY_MODEL or gpt-4o-mini")),
 	)
 
 	mrCommentTool := mcp.NewTool("gitlab_create_MR_note",
@@ -139,6 +266,7 @@ func RegisterGitLabTool(s *server.MCPServer) {
 		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
 		mcp.WithString("mr_iid", mcp.Required(), mcp.Description("Merge request IID")),
 		mcp.WithString("comment", mcp.Required(), mcp.Description("Comment text")),
+		util.WithDryRun(),
 	)
 
 	fileContentTool := mcp.NewTool("gitlab_get_file_content",
@@ -146,12 +274,41 @@ func RegisterGitLabTool(s *server.MCPServer) {
 		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
 		mcp.WithString("file_path", mcp.Required(), mcp.Description("Path to the file in the repository")),
 		mcp.WithString("ref", mcp.Required(), mcp.Description("Branch name, tag, or commit SHA")),
+		mcp.WithBoolean("force_refresh", mcp.Description("Look the project up again instead of using its cached metadata")),
+	)
+
+	blameTool := mcp.NewTool("gitlab_blame",
+		mcp.WithDescription("Show who last changed each line of a file, with the commit SHA, author, date and content per line"),
+		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
+		mcp.WithString("file_path", mcp.Required(), mcp.Description("Path to the file in the repository")),
+		mcp.WithString("ref", mcp.Description("Branch name, tag, or commit SHA (default: the project's default branch)")),
+		mcp.WithNumber("start_line", mcp.Description("First line to blame (default: 1)")),
+		mcp.WithNumber("end_line", mcp.Description("Last line to blame (default: end of file)")),
+		mcp.WithBoolean("force_refresh", mcp.Description("Look the project up again instead of using its cached metadata")),
+	)
+
+	diffFileTool := mcp.NewTool("gitlab_diff_file",
+		mcp.WithDescription("Compare one file between two refs of a GitLab repository and return a unified diff"),
+		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
+		mcp.WithString("file_path", mcp.Required(), mcp.Description("Path to the file in the repository")),
+		mcp.WithString("ref_a", mcp.Required(), mcp.Description("Branch name, tag, or commit SHA to compare from")),
+		mcp.WithString("ref_b", mcp.Required(), mcp.Description("Branch name, tag, or commit SHA to compare to")),
 	)
 
 	pipelineTool := mcp.NewTool("gitlab_list_pipelines",
 		mcp.WithDescription("List pipelines for a GitLab project"),
 		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
 		mcp.WithString("status", mcp.DefaultString("all"), mcp.Description("Pipeline status (running/pending/success/failed/canceled/skipped/all)")),
+		util.WithPagePagination(gitlabPagination),
+	)
+
+	waitPipelineTool := mcp.NewTool("gitlab_wait_pipeline",
+		mcp.WithDescription("Wait for a GitLab pipeline to finish, polling until it reaches a terminal state or times out. Returns the final status and failed job names"),
+		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
+		mcp.WithString("pipeline_id", mcp.Description("Pipeline ID to wait for. If not provided, the latest pipeline for ref is used")),
+		mcp.WithString("ref", mcp.Description("Branch name or tag to wait for the latest pipeline of, used when pipeline_id is not provided")),
+		mcp.WithNumber("interval_seconds", mcp.DefaultNumber(15), mcp.Description("Polling interval in seconds")),
+		mcp.WithNumber("timeout_seconds", mcp.DefaultNumber(600), mcp.Description("Maximum time to wait in seconds (capped at 1800)")),
 	)
 
 	commitsTool := mcp.NewTool("gitlab_list_commits",
@@ -159,7 +316,8 @@ func RegisterGitLabTool(s *server.MCPServer) {
 		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
 		mcp.WithString("since", mcp.Required(), mcp.Description("Start date (YYYY-MM-DD)")),
 		mcp.WithString("until", mcp.Description("End date (YYYY-MM-DD). If not provided, defaults to current date")),
-		mcp.WithString("ref", mcp.Required(), mcp.Description("Branch name, tag, or commit SHA")),
+		mcp.WithString("ref", mcp.Description("Branch name, tag, or commit SHA. If not provided, defaults to the project's default branch")),
+		util.WithPagePagination(gitlabPagination),
 	)
 
 	commitDetailsTool := mcp.NewTool("gitlab_get_commit_details",
@@ -173,11 +331,13 @@ func RegisterGitLabTool(s *server.MCPServer) {
 		mcp.WithString("username", mcp.Required(), mcp.Description("GitLab username")),
 		mcp.WithString("since", mcp.Required(), mcp.Description("Start date (YYYY-MM-DD)")),
 		mcp.WithString("until", mcp.Description("End date (YYYY-MM-DD). If not provided, defaults to current date")),
+		util.WithPagePagination(gitlabPagination),
 	)
 
 	listGroupUsersTool := mcp.NewTool("gitlab_list_group_users",
 		mcp.WithDescription("List all users in a GitLab group"),
 		mcp.WithString("group_id", mcp.Required(), mcp.Description("GitLab group ID")),
+		util.WithPagePagination(gitlabPagination),
 	)
 
 	createMRTool := mcp.NewTool("gitlab_create_mr",
@@ -187,27 +347,99 @@ func RegisterGitLabTool(s *server.MCPServer) {
 		mcp.WithString("target_branch", mcp.Required(), mcp.Description("Target branch name")),
 		mcp.WithString("title", mcp.Required(), mcp.Description("Merge request title")),
 		mcp.WithString("description", mcp.Description("Merge request description")),
+		util.WithDryRun(),
+		mcp.WithString("idempotency_key", mcp.Description("Optional unique key; repeating a call with the same key returns the original result instead of creating a duplicate")),
 	)
 
 	cloneRepoTool := mcp.NewTool("gitlab_clone_repo",
 		mcp.WithDescription("Clone or update a GitLab repository locally"),
 		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
 		mcp.WithString("ref", mcp.Description("Branch name or tag (optional, defaults to project's default branch)")),
+		mcp.WithBoolean("force_refresh", mcp.Description("Look the project up again instead of using its cached metadata")),
+		util.WithDryRun(),
+	)
+
+	getSnippetTool := mcp.NewTool("gitlab_get_snippet",
+		mcp.WithDescription("Get a GitLab snippet with its content"),
+		mcp.WithString("snippet_id", mcp.Required(), mcp.Description("Snippet ID")),
+		mcp.WithString("project_path", mcp.Description("Project/repo path, required for project snippets")),
+	)
+
+	createSnippetTool := mcp.NewTool("gitlab_create_snippet",
+		mcp.WithDescription("Create a GitLab snippet. Creates a personal snippet unless project_path is given"),
+		mcp.WithString("title", mcp.Required(), mcp.Description("Snippet title")),
+		mcp.WithString("file_name", mcp.Required(), mcp.Description("File name, e.g. example.go")),
+		mcp.WithString("content", mcp.Required(), mcp.Description("Snippet content")),
+		mcp.WithString("visibility", mcp.DefaultString("private"), mcp.Description("Visibility (private/internal/public)")),
+		mcp.WithString("description", mcp.Description("Snippet description")),
+		mcp.WithString("project_path", mcp.Description("Project/repo path to create a project snippet in")),
+		util.WithDryRun(),
+	)
+
+	listWikiPagesTool := mcp.NewTool("gitlab_list_wiki_pages",
+		mcp.WithDescription("List the pages of a GitLab project's wiki"),
+		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
+	)
+
+	getWikiPageTool := mcp.NewTool("gitlab_get_wiki_page",
+		mcp.WithDescription("Get a GitLab wiki page with its content as Markdown"),
+		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
+		mcp.WithString("slug", mcp.Required(), mcp.Description("Page slug, as listed by gitlab_list_wiki_pages, e.g. home or guides/setup")),
+	)
+
+	compareTool := mcp.NewTool("gitlab_compare",
+		mcp.WithDescription("Compare two branches, tags or commits, returning the commits between them and the combined diff"),
+		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
+		mcp.WithString("from", mcp.Required(), mcp.Description("Base branch, tag or commit SHA")),
+		mcp.WithString("to", mcp.Required(), mcp.Description("Head branch, tag or commit SHA")),
+		mcp.WithNumber("max_diff_chars", mcp.DefaultNumber(defaultMaxDiffChars), mcp.Description("Maximum size of the diff output; longer diffs are truncated (0 for no limit)")),
+	)
+
+	listReleasesTool := mcp.NewTool("gitlab_list_releases",
+		mcp.WithDescription("List releases of a GitLab project, newest first"),
+		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
+		util.WithPagePagination(releasesPagination),
+	)
+
+	createReleaseTool := mcp.NewTool("gitlab_create_release",
+		mcp.WithDescription("Create a release for a tag. The description can be generated from the commits since the previous release"),
+		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
+		mcp.WithString("tag_name", mcp.Required(), mcp.Description("Tag to release; created from ref if it does not exist")),
+		mcp.WithString("name", mcp.Description("Release name (default: tag name)")),
+		mcp.WithString("description", mcp.Description("Release notes in Markdown")),
+		mcp.WithString("ref", mcp.Description("Branch or commit SHA to create the tag from, required when the tag does not exist")),
+		mcp.WithBoolean("generate_description", mcp.Description("Generate the release notes from commits since the previous release when description is empty")),
+		util.WithDryRun(),
 	)
 
 	s.AddTool(listProjectsTool, util.ErrorGuard(listProjectsHandler))
 	s.AddTool(projectTool, util.ErrorGuard(getProjectHandler))
 	s.AddTool(mrListTool, util.ErrorGuard(listMergeRequestsHandler))
+	s.AddTool(myReviewsTool, util.ErrorGuard(myReviewsHandler))
 	s.AddTool(mrDetailsTool, util.ErrorGuard(getMergeRequestHandler))
-	s.AddTool(mrCommentTool, util.ErrorGuard(commentOnMergeRequestHandler))
+	s.AddTool(mrFilesTool, util.ErrorGuard(listMergeRequestFilesHandler))
+	s.AddTool(mrFileDiffTool, util.ErrorGuard(getMergeRequestFileDiffHandler))
+	s.AddTool(mrApprovalRulesTool, util.ErrorGuard(getMergeRequestApprovalRulesHandler))
+	s.AddTool(mrSummarizeTool, util.ErrorGuard(summarizeMergeRequestHandler))
+	s.AddTool(mrCommentTool, util.ErrorGuard(util.DryRunGuard("create merge request note", "POST /projects/{project_path}/merge_requests/{mr_iid}/notes", commentOnMergeRequestHandler)))
 	s.AddTool(fileContentTool, util.ErrorGuard(getFileContentHandler))
+	s.AddTool(blameTool, util.ErrorGuard(blameHandler))
+	s.AddTool(diffFileTool, util.ErrorGuard(diffFileHandler))
 	s.AddTool(pipelineTool, util.ErrorGuard(listPipelinesHandler))
+	s.AddTool(waitPipelineTool, util.ErrorGuard(waitPipelineHandler))
 	s.AddTool(commitsTool, util.ErrorGuard(util.AdaptLegacyHandler(listCommitsHandler)))
 	s.AddTool(commitDetailsTool, util.ErrorGuard(util.AdaptLegacyHandler(getCommitDetailsHandler)))
 	s.AddTool(userEventsTool, util.ErrorGuard(util.AdaptLegacyHandler(listUserEventsHandler)))
 	s.AddTool(listGroupUsersTool, util.ErrorGuard(util.AdaptLegacyHandler(listGroupUsersHandler)))
-	s.AddTool(createMRTool, util.ErrorGuard(util.AdaptLegacyHandler(createMergeRequestHandler)))
-	s.AddTool(cloneRepoTool, util.ErrorGuard(util.AdaptLegacyHandler(cloneRepoHandler)))
+	s.AddTool(createMRTool, util.Idempotent(util.ErrorGuard(util.AdaptLegacyHandler(createMergeRequestHandler))))
+	s.AddTool(cloneRepoTool, util.ErrorGuard(util.DryRunGuard("clone or update repository", "git clone/fetch into the repository cache", util.AdaptLegacyHandler(cloneRepoHandler))))
+	s.AddTool(compareTool, util.ErrorGuard(compareHandler))
+	s.AddTool(listReleasesTool, util.ErrorGuard(listReleasesHandler))
+	s.AddTool(createReleaseTool, util.ErrorGuard(util.DryRunGuard("create release", "POST /projects/{project_path}/releases", createReleaseHandler)))
+	s.AddTool(getSnippetTool, util.ErrorGuard(getSnippetHandler))
+	s.AddTool(createSnippetTool, util.ErrorGuard(util.DryRunGuard("create snippet", "POST /snippets or /projects/{project_path}/snippets", createSnippetHandler)))
+	s.AddTool(listWikiPagesTool, util.ErrorGuard(listWikiPagesHandler))
+	s.AddTool(getWikiPageTool, util.ErrorGuard(getWikiPageHandler))
 }
 
 func listProjectsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -218,18 +450,18 @@ func listProjectsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		Archived: gitlab.Ptr(false),
 		OrderBy:  gitlab.Ptr("last_activity_at"),
 		Sort:     gitlab.Ptr("desc"),
-		ListOptions: gitlab.ListOptions{
-			PerPage: 100,
-		},
 	}
 
 	if search, ok := arguments["search"]; ok {
 		opt.Search = gitlab.Ptr(search.(string))
 	}
 
-	projects, _, err := gitlabClient().Groups.ListGroupProjects(groupID, opt)
+	projects, nextPage, err := paginateGitLab(arguments, gitlabPagination, func(lo gitlab.ListOptions) ([]*gitlab.Project, *gitlab.Response, error) {
+		opt.ListOptions = lo
+		return gitlabClient().Groups.ListGroupProjects(groupID, opt, gitlab.WithContext(ctx))
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to search projects: %v", err)
+		return nil, gitlabError(err, "failed to search projects")
 	}
 
 	var result string
@@ -237,6 +469,7 @@ func listProjectsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		result += fmt.Sprintf("ID: %d\nName: %s\nPath: %s\nDescription: %s\nLast Activity: %s\n\n",
 			project.ID, project.Name, project.PathWithNamespace, project.Description, project.LastActivityAt.Format("2006-01-02 15:04:05"))
 	}
+	result += util.PaginationNote(nextPage, "page")
 
 	return mcp.NewToolResultText(result), nil
 }
@@ -244,23 +477,24 @@ func listProjectsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp
 func getProjectHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	arguments := request.Params.Arguments
 	projectID := arguments["project_path"].(string)
+	forceRefresh, _ := arguments["force_refresh"].(bool)
 
 	// Get project details
-	project, _, err := gitlabClient().Projects.GetProject(projectID, nil)
+	project, err := getGitLabProject(projectID, forceRefresh)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get project: %v", err)
+		return nil, err
 	}
 
 	// Get branches
 	branches, _, err := gitlabClient().Branches.ListBranches(projectID, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list branches: %v", err)
+		return nil, gitlabError(err, "failed to list branches")
 	}
 
 	// Get tags
 	tags, _, err := gitlabClient().Tags.ListTags(projectID, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list tags: %v", err)
+		return nil, gitlabError(err, "failed to list tags")
 	}
 
 	// Build basic project info
@@ -294,15 +528,23 @@ func listMergeRequestsHandler(ctx context.Context, request mcp.CallToolRequest)
 
 	opt := &gitlab.ListProjectMergeRequestsOptions{
 		State: gitlab.String(state),
-		ListOptions: gitlab.ListOptions{
-			PerPage: 100,
-		},
 	}
 
-	mrs, _, err := gitlabClient().MergeRequests.ListProjectMergeRequests(projectID, opt)
+	mrs, nextPage, err := paginateGitLab(arguments, gitlabPagination, func(lo gitlab.ListOptions) ([]*gitlab.MergeRequest, *gitlab.Response, error) {
+		opt.ListOptions = lo
+		return gitlabClient().MergeRequests.ListProjectMergeRequests(projectID, opt, gitlab.WithContext(ctx))
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list merge requests: %v", err)
+		return nil, gitlabError(err, "failed to list merge requests")
 	}
+
+	if rendered, ok, err := util.RenderResult("gitlab_list_mrs", map[string]interface{}{"MergeRequests": mrs}); ok {
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(rendered), nil
+	}
+
 	var result strings.Builder
 	for _, mr := range mrs {
 		result.WriteString(fmt.Sprintf("MR #%d: %s\nState: %s\nAuthor: %s\nURL: %s\nCreated: %s\n",
@@ -352,10 +594,113 @@ func listMergeRequestsHandler(ctx context.Context, request mcp.CallToolRequest)
 		}
 		result.WriteString("\n")
 	}
+	result.WriteString(util.PaginationNote(nextPage, "page"))
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// myReviewsLimit caps how many merge requests gitlab_my_reviews fetches per role
+const myReviewsLimit = 100
+
+func myReviewsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	username, _ := arguments["username"].(string)
+
+	user, err := resolveGitLabUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	listOptions := func() *gitlab.ListMergeRequestsOptions {
+		return &gitlab.ListMergeRequestsOptions{
+			State:       gitlab.Ptr("opened"),
+			Scope:       gitlab.Ptr("all"),
+			OrderBy:     gitlab.Ptr("updated_at"),
+			Sort:        gitlab.Ptr("desc"),
+			ListOptions: gitlab.ListOptions{PerPage: myReviewsLimit},
+		}
+	}
+
+	reviewerOpt := listOptions()
+	reviewerOpt.ReviewerID = gitlab.ReviewerID(user.ID)
+	reviewing, _, err := gitlabClient().MergeRequests.ListMergeRequests(reviewerOpt, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, gitlabError(err, "failed to list merge requests to review")
+	}
+
+	assigneeOpt := listOptions()
+	assigneeOpt.AssigneeID = gitlab.AssigneeID(user.ID)
+	assigned, _, err := gitlabClient().MergeRequests.ListMergeRequests(assigneeOpt, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, gitlabError(err, "failed to list assigned merge requests")
+	}
+
+	// A merge request can be both assigned to and reviewed by the user
+	roles := make(map[int][]string)
+	var mrs []*gitlab.MergeRequest
+	for _, group := range []struct {
+		role string
+		mrs  []*gitlab.MergeRequest
+	}{{"reviewer", reviewing}, {"assignee", assigned}} {
+		for _, mr := range group.mrs {
+			if _, seen := roles[mr.ID]; !seen {
+				mrs = append(mrs, mr)
+			}
+			roles[mr.ID] = append(roles[mr.ID], group.role)
+		}
+	}
+	sort.Slice(mrs, func(i, j int) bool {
+		return mrs[i].UpdatedAt != nil && (mrs[j].UpdatedAt == nil || mrs[i].UpdatedAt.After(*mrs[j].UpdatedAt))
+	})
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Open merge requests for %s: %d\n\n", user.Username, len(mrs)))
+	for _, mr := range mrs {
+		reference := fmt.Sprintf("!%d", mr.IID)
+		if mr.References != nil && mr.References.Full != "" {
+			reference = mr.References.Full
+		}
+		result.WriteString(fmt.Sprintf("%s: %s\n", reference, mr.Title))
+		result.WriteString(fmt.Sprintf("Role: %s\n", strings.Join(roles[mr.ID], ", ")))
+		if mr.Author != nil {
+			result.WriteString(fmt.Sprintf("Author: %s\n", mr.Author.Username))
+		}
+		if mr.Draft {
+			result.WriteString("Draft: true\n")
+		}
+		if mr.UpdatedAt != nil {
+			result.WriteString(fmt.Sprintf("Updated: %s\n", mr.UpdatedAt.Format("2006-01-02 15:04:05")))
+		}
+		result.WriteString(fmt.Sprintf("URL: %s\n\n", mr.WebURL))
+	}
+	if len(reviewing) == myReviewsLimit || len(assigned) == myReviewsLimit {
+		result.WriteString(fmt.Sprintf("Only the %d most recently updated merge requests per role are shown\n", myReviewsLimit))
+	}
 
 	return mcp.NewToolResultText(result.String()), nil
 }
 
+// resolveGitLabUser looks up a user by username, or returns the token's own
+// user when username is empty
+func resolveGitLabUser(ctx context.Context, username string) (*gitlab.User, error) {
+	if username == "" {
+		user, _, err := gitlabClient().Users.CurrentUser(gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, gitlabError(err, "failed to get current user")
+		}
+		return user, nil
+	}
+
+	users, _, err := gitlabClient().Users.ListUsers(&gitlab.ListUsersOptions{Username: gitlab.Ptr(username)}, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, gitlabError(err, "failed to look up user")
+	}
+	if len(users) == 0 {
+		return nil, util.NewToolError(util.ErrCodeNotFound, "GitLab user %s not found", username)
+	}
+	return users[0], nil
+}
+
 func getMergeRequestHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	arguments := request.Params.Arguments
 	projectID := arguments["project_path"].(string)
@@ -363,19 +708,23 @@ func getMergeRequestHandler(ctx context.Context, request mcp.CallToolRequest) (*
 
 	mrIID, err := strconv.Atoi(mrIIDStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid mr_iid: %v", err)
+		return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "invalid mr_iid")
 	}
 
-	// Get MR details
-	mr, _, err := gitlabClient().MergeRequests.GetMergeRequest(projectID, mrIID, nil)
+	mr, changes, err := getMergeRequestWithDiffs(ctx, projectID, mrIID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get merge request: %v", err)
+		return nil, err
 	}
 
-	// Get detailed changes
-	changes, _, err := gitlabClient().MergeRequests.ListMergeRequestDiffs(projectID, mrIID, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get merge request changes: %v", err)
+	// A result template renders full diffs, so summary_only bypasses it
+	summaryOnly, _ := arguments["summary_only"].(bool)
+	if !summaryOnly {
+		if rendered, ok, err := util.RenderResult("gitlab_get_mr_details", map[string]interface{}{"MergeRequest": mr, "Changes": changes}); ok {
+			if err != nil {
+				return nil, err
+			}
+			return mcp.NewToolResultText(rendered), nil
+		}
 	}
 
 	var result strings.Builder
@@ -398,6 +747,11 @@ func getMergeRequestHandler(ctx context.Context, request mcp.CallToolRequest) (*
 		result.WriteString("\n\n")
 	}
 
+	if summaryOnly {
+		writeDiffSummary(&result, changes)
+		return mcp.NewToolResultText(result.String()), nil
+	}
+
 	// Write changes overview
 	result.WriteString("Changes Overview:\n")
 	result.WriteString(fmt.Sprintf("Total files changed: %d\n\n", len(changes)))
@@ -429,6 +783,247 @@ func getMergeRequestHandler(ctx context.Context, request mcp.CallToolRequest) (*
 	return mcp.NewToolResultText(result.String()), nil
 }
 
+// getMergeRequestWithDiffs fetches a merge request and the diffs of its changed files
+func getMergeRequestWithDiffs(ctx context.Context, projectID string, mrIID int) (*gitlab.MergeRequest, []*gitlab.MergeRequestDiff, error) {
+	// Get MR details
+	mr, _, err := gitlabClient().MergeRequests.GetMergeRequest(projectID, mrIID, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, nil, gitlabError(err, "failed to get merge request")
+	}
+
+	// Get detailed changes, every page of them so large merge requests are complete
+	changes, _, err := paginateGitLab(map[string]interface{}{}, mrDiffsPagination, func(opt gitlab.ListOptions) ([]*gitlab.MergeRequestDiff, *gitlab.Response, error) {
+		return gitlabClient().MergeRequests.ListMergeRequestDiffs(projectID, mrIID, &gitlab.ListMergeRequestDiffsOptions{ListOptions: opt}, gitlab.WithContext(ctx))
+	})
+	if err != nil {
+		return nil, nil, gitlabError(err, "failed to get merge request changes")
+	}
+	return mr, changes, nil
+}
+
+func listMergeRequestFilesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	projectID := arguments["project_path"].(string)
+	mrIIDStr := arguments["mr_iid"].(string)
+
+	mrIID, err := strconv.Atoi(mrIIDStr)
+	if err != nil {
+		return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "invalid mr_iid")
+	}
+
+	mr, changes, err := getMergeRequestWithDiffs(ctx, projectID, mrIID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Merge Request #%d: %s\n\n", mr.IID, mr.Title))
+	writeDiffSummary(&result, changes)
+	result.WriteString("\nUse gitlab_get_mr_file_diff to get the diff of one file\n")
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+func getMergeRequestFileDiffHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	projectID := arguments["project_path"].(string)
+	mrIIDStr := arguments["mr_iid"].(string)
+	filePath := strings.TrimPrefix(arguments["file_path"].(string), "/")
+
+	mrIID, err := strconv.Atoi(mrIIDStr)
+	if err != nil {
+		return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "invalid mr_iid")
+	}
+
+	mr, changes, err := getMergeRequestWithDiffs(ctx, projectID, mrIID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Renamed and deleted files are also found by their old path
+	for _, change := range changes {
+		if change.NewPath != filePath && change.OldPath != filePath {
+			continue
+		}
+
+		var result strings.Builder
+		result.WriteString(fmt.Sprintf("File: %s\n", change.NewPath))
+		switch {
+		case change.NewFile:
+			result.WriteString("Status: Added\n")
+		case change.DeletedFile:
+			result.WriteString("Status: Deleted\n")
+		case change.RenamedFile:
+			result.WriteString(fmt.Sprintf("Status: Renamed from %s\n", change.OldPath))
+		default:
+			result.WriteString("Status: Modified\n")
+		}
+
+		if change.Diff == "" {
+			result.WriteString("No diff available (binary file, or a diff too large for GitLab to return)\n")
+		} else {
+			added, removed := countDiffLines(change.Diff)
+			result.WriteString(fmt.Sprintf("Lines: +%d -%d\n", added, removed))
+			result.WriteString("Diff:\n```diff\n")
+			result.WriteString(change.Diff)
+			result.WriteString("\n```\n")
+		}
+		return mcp.NewToolResultText(result.String()), nil
+	}
+
+	return nil, util.NewToolError(util.ErrCodeNotFound, "%s is not changed by merge request !%d (%d files changed, see gitlab_list_mr_files)", filePath, mr.IID, len(changes))
+}
+
+func getMergeRequestApprovalRulesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	projectID := arguments["project_path"].(string)
+	mrIIDStr := arguments["mr_iid"].(string)
+
+	mrIID, err := strconv.Atoi(mrIIDStr)
+	if err != nil {
+		return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "invalid mr_iid")
+	}
+
+	approvals, _, err := gitlabClient().MergeRequestApprovals.GetConfiguration(projectID, mrIID, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, gitlabError(err, "failed to get merge request approvals")
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Merge Request #%d: %s\n", approvals.IID, approvals.Title))
+	result.WriteString(fmt.Sprintf("Approved: %t\n", approvals.Approved))
+	result.WriteString(fmt.Sprintf("Approvals required: %d\n", approvals.ApprovalsRequired))
+	result.WriteString(fmt.Sprintf("Approvals left: %d\n", approvals.ApprovalsLeft))
+	var approvedBy []string
+	for _, approver := range approvals.ApprovedBy {
+		if approver.User != nil {
+			approvedBy = append(approvedBy, approver.User.Username)
+		}
+	}
+	if len(approvedBy) == 0 {
+		approvedBy = []string{"nobody yet"}
+	}
+	result.WriteString(fmt.Sprintf("Approved by: %s\n", strings.Join(approvedBy, ", ")))
+	result.WriteString(fmt.Sprintf("You can approve: %t (already approved: %t)\n", approvals.UserCanApprove, approvals.UserHasApproved))
+
+	// Approval rules need GitLab Premium; without them only the counts above apply
+	state, resp, err := gitlabClient().MergeRequestApprovals.GetApprovalState(projectID, mrIID, gitlab.WithContext(ctx))
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden) {
+			result.WriteString("\nApproval rules: not available for this project\n")
+			return mcp.NewToolResultText(result.String()), nil
+		}
+		return nil, gitlabError(err, "failed to get merge request approval state")
+	}
+	if len(state.Rules) == 0 {
+		result.WriteString("\nApproval rules: none\n")
+		return mcp.NewToolResultText(result.String()), nil
+	}
+
+	result.WriteString("\nApproval rules")
+	if state.ApprovalRulesOverwritten {
+		result.WriteString(" (overridden for this merge request)")
+	}
+	result.WriteString(":\n")
+	for _, rule := range state.Rules {
+		status := "pending"
+		if rule.Approved {
+			status = "approved"
+		}
+		result.WriteString(fmt.Sprintf("- %s (%s): %d of %d approvals, %s\n", rule.Name, rule.RuleType, len(rule.ApprovedBy), rule.ApprovalsRequired, status))
+
+		var eligible []string
+		for _, user := range rule.EligibleApprovers {
+			eligible = append(eligible, user.Username)
+		}
+		if len(eligible) > 0 {
+			result.WriteString(fmt.Sprintf("  Eligible approvers: %s\n", strings.Join(eligible, ", ")))
+		}
+		var ruleApprovedBy []string
+		for _, user := range rule.ApprovedBy {
+			ruleApprovedBy = append(ruleApprovedBy, user.Username)
+		}
+		if len(ruleApprovedBy) > 0 {
+			result.WriteString(fmt.Sprintf("  Approved by: %s\n", strings.Join(ruleApprovedBy, ", ")))
+		}
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// mrSummaryChunkSize is the approximate number of characters of diff sent per summarization request
+const mrSummaryChunkSize = 12000
+
+const mrReviewInstructions = "Write a summary for the reviewers of the merge request in Markdown with these sections: \"Intent\" (what the change does and why), \"Risk Areas\" (the files and changes most likely to break something, and why) and \"Test Coverage Gaps\" (changed behavior without matching test changes). Be specific and reference file paths."
+
+func summarizeMergeRequestHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	projectID := arguments["project_path"].(string)
+	mrIIDStr := arguments["mr_iid"].(string)
+
+	mrIID, err := strconv.Atoi(mrIIDStr)
+	if err != nil {
+		return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "invalid mr_iid")
+	}
+
+	model, _ := arguments["model"].(string)
+	if model == "" {
+		model = config.Get().MRSummaryModel
+	}
+
+	mr, changes, err := getMergeRequestWithDiffs(ctx, projectID, mrIID)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "merge request !%d has no changes to summarize", mr.IID)
+	}
+
+	header := fmt.Sprintf("Merge request !%d: %s\nSource branch: %s\nTarget branch: %s\n", mr.IID, mr.Title, mr.SourceBranch, mr.TargetBranch)
+	if mr.Description != "" {
+		header += "Description:\n" + util.TruncateText(mr.Description, 2000) + "\n"
+	}
+
+	// Group whole file diffs into chunks, cutting down any single diff larger than a chunk
+	var (
+		chunks  []string
+		current strings.Builder
+	)
+	for _, change := range changes {
+		fileDiff := fmt.Sprintf("File: %s\n%s\n", change.NewPath, util.TruncateText(change.Diff, mrSummaryChunkSize))
+		if current.Len() > 0 && current.Len()+len(fileDiff) > mrSummaryChunkSize {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		current.WriteString(fileDiff)
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+
+	var summary string
+	if len(chunks) == 1 {
+		summary, err = summarizeText(ctx, model, fmt.Sprintf("%s\n\n%s\nDiff:\n%s", mrReviewInstructions, header, chunks[0]))
+	} else {
+		// Map: note what each part of the diff changes, then reduce the notes into the summary
+		partials := make([]string, 0, len(chunks))
+		for i, chunk := range chunks {
+			partial, err := summarizeText(ctx, model, fmt.Sprintf(
+				"Part %d of %d of the diff of a merge request follows. List as bullets what it changes, what could break and which changes lack tests, referencing file paths.\n\n%s\nDiff:\n%s",
+				i+1, len(chunks), header, chunk))
+			if err != nil {
+				return nil, err
+			}
+			partials = append(partials, partial)
+		}
+		summary, err = summarizeText(ctx, model, fmt.Sprintf("%s Base it on these notes on each part of the diff.\n\n%s\nNotes:\n%s", mrReviewInstructions, header, strings.Join(partials, "\n\n")))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Merge Request !%d: %s\nURL: %s\nFiles changed: %d\n\n%s", mr.IID, mr.Title, mr.WebURL, len(changes), summary)), nil
+}
+
 func commentOnMergeRequestHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	arguments := request.Params.Arguments
 	projectID := arguments["project_path"].(string)
@@ -437,7 +1032,7 @@ func commentOnMergeRequestHandler(ctx context.Context, request mcp.CallToolReque
 
 	mrIID, err := strconv.Atoi(mrIIDStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid mr_iid: %v", err)
+		return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "invalid mr_iid")
 	}
 
 	opt := &gitlab.CreateMergeRequestNoteOptions{
@@ -446,7 +1041,7 @@ func commentOnMergeRequestHandler(ctx context.Context, request mcp.CallToolReque
 
 	note, _, err := gitlabClient().Notes.CreateMergeRequestNote(projectID, mrIID, opt)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create comment: %v", err)
+		return nil, gitlabError(err, "failed to create comment")
 	}
 
 	result := fmt.Sprintf("Comment posted successfully!\nID: %d\nAuthor: %s\nCreated: %s\nContent: %s",
@@ -464,9 +1059,10 @@ func getFileContentHandler(ctx context.Context, request mcp.CallToolRequest) (*m
 	if value, ok := arguments["ref"]; ok {
 		ref = value.(string)
 	}
+	forceRefresh, _ := arguments["force_refresh"].(bool)
 
 	// Ensure repository is available locally with the specified ref
-	localPath, err := repoCache.ensureRepo(projectPath, ref)
+	localPath, err := repoCache.ensureRepo(projectPath, ref, forceRefresh)
 	if err != nil {
 		return nil, err
 	}
@@ -475,7 +1071,7 @@ func getFileContentHandler(ctx context.Context, request mcp.CallToolRequest) (*m
 	cmd := exec.Command("git", "-C", localPath, "show", fmt.Sprintf("%s:%s", ref, filePath))
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get file content: %v", err)
+		return nil, util.WrapToolError(util.ErrCodeNotFound, err, fmt.Sprintf("file '%s' not found at %s", filePath, ref))
 	}
 
 	var result strings.Builder
@@ -487,7 +1083,210 @@ func getFileContentHandler(ctx context.Context, request mcp.CallToolRequest) (*m
 	return mcp.NewToolResultText(result.String()), nil
 }
 
-func listPipelinesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+// blameLine is one line of git blame output
+type blameLine struct {
+	number  int
+	sha     string
+	author  string
+	date    time.Time
+	content string
+}
+
+func blameHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	projectPath := arguments["project_path"].(string)
+	filePath := arguments["file_path"].(string)
+	ref, _ := arguments["ref"].(string)
+
+	startLine, endLine := 1, 0
+	if value, ok := arguments["start_line"].(float64); ok {
+		startLine = int(value)
+	}
+	if value, ok := arguments["end_line"].(float64); ok {
+		endLine = int(value)
+	}
+	if startLine < 1 || (endLine != 0 && endLine < startLine) {
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "invalid line range %d-%d", startLine, endLine)
+	}
+	forceRefresh, _ := arguments["force_refresh"].(bool)
+
+	localPath, err := repoCache.ensureRepo(projectPath, ref, forceRefresh)
+	if err != nil {
+		return nil, err
+	}
+	if ref == "" {
+		// The mirror's HEAD is the project's default branch
+		ref = "HEAD"
+	}
+
+	content, err := exec.CommandContext(ctx, "git", "-C", localPath, "show", fmt.Sprintf("%s:%s", ref, filePath)).Output()
+	if err != nil {
+		return nil, util.WrapToolError(util.ErrCodeNotFound, err, fmt.Sprintf("file '%s' not found at %s", filePath, ref))
+	}
+	if isBinaryContent(content) {
+		return mcp.NewToolResultText(fmt.Sprintf("File: %s\nRef: %s\n%s is a binary file, blame is not available", filePath, ref, filePath)), nil
+	}
+
+	lineRange := fmt.Sprintf("%d,", startLine)
+	if endLine != 0 {
+		lineRange += strconv.Itoa(endLine)
+	}
+	cmd := exec.CommandContext(ctx, "git", "-C", localPath, "blame", "--porcelain", "-L", lineRange, ref, "--", filePath)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if strings.Contains(message, "has only") {
+			return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, message)
+		}
+		return nil, fmt.Errorf("git blame failed: %s", message)
+	}
+
+	lines := parseBlamePorcelain(string(output))
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("File: %s\n", filePath))
+	result.WriteString(fmt.Sprintf("Ref: %s\n\n", ref))
+	for _, line := range lines {
+		result.WriteString(fmt.Sprintf("%5d | %.8s | %s | %s | %s\n",
+			line.number, line.sha, line.author, line.date.Format("2006-01-02"), line.content))
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+func diffFileHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	projectPath := arguments["project_path"].(string)
+	filePath := arguments["file_path"].(string)
+	refA := arguments["ref_a"].(string)
+	refB := arguments["ref_b"].(string)
+
+	localPath, err := repoCache.ensureRepo(projectPath, refA, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := exec.CommandContext(ctx, "git", "-C", localPath, "rev-parse", "--verify", refB).Run(); err != nil {
+		return nil, util.WrapToolError(util.ErrCodeNotFound, err, fmt.Sprintf("reference '%s' not found in repository", refB))
+	}
+
+	// A side where the file does not exist is compared as an empty file
+	blobA, existsA, err := fileBlob(ctx, localPath, refA, filePath)
+	if err != nil {
+		return nil, err
+	}
+	blobB, existsB, err := fileBlob(ctx, localPath, refB, filePath)
+	if err != nil {
+		return nil, err
+	}
+	if !existsA && !existsB {
+		return nil, util.NewToolError(util.ErrCodeNotFound, "file '%s' not found at %s or %s", filePath, refA, refB)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "-C", localPath, "diff", "--no-color", blobA, blobB)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("File: %s\n", filePath))
+	result.WriteString(fmt.Sprintf("Refs: %s..%s\n", refA, refB))
+	switch {
+	case !existsA:
+		result.WriteString(fmt.Sprintf("The file does not exist at %s\n", refA))
+	case !existsB:
+		result.WriteString(fmt.Sprintf("The file does not exist at %s\n", refB))
+	}
+	result.WriteString("\n")
+	if len(output) == 0 {
+		result.WriteString("No differences\n")
+	} else {
+		// Name the missing side by the file path rather than the empty blob
+		diff := strings.NewReplacer("a/"+blobA, "a/"+filePath, "b/"+blobB, "b/"+filePath).Replace(string(output))
+		result.WriteString(diff)
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// fileBlob returns the object name of a file at ref for git diff, or the empty
+// blob and false when the file does not exist there
+func fileBlob(ctx context.Context, localPath, ref, filePath string) (string, bool, error) {
+	object := fmt.Sprintf("%s:%s", ref, filePath)
+	if exec.CommandContext(ctx, "git", "-C", localPath, "cat-file", "-e", object).Run() == nil {
+		return object, true, nil
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "-C", localPath, "hash-object", "-w", "--stdin")
+	cmd.Stdin = strings.NewReader("")
+	emptyBlob, err := cmd.Output()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create empty blob: %v", err)
+	}
+	return strings.TrimSpace(string(emptyBlob)), false, nil
+}
+
+// parseBlamePorcelain parses the output of git blame --porcelain. Commit details
+// are only printed the first time a commit appears, so they are remembered by SHA.
+func parseBlamePorcelain(output string) []blameLine {
+	type commitInfo struct {
+		author string
+		date   time.Time
+	}
+	commits := make(map[string]*commitInfo)
+
+	var lines []blameLine
+	var current *blameLine
+	for _, text := range strings.Split(output, "\n") {
+		if current == nil {
+			fields := strings.Fields(text)
+			if len(fields) < 3 {
+				continue
+			}
+			number, err := strconv.Atoi(fields[2])
+			if err != nil {
+				continue
+			}
+			current = &blameLine{number: number, sha: fields[0]}
+			if commits[current.sha] == nil {
+				commits[current.sha] = &commitInfo{}
+			}
+			continue
+		}
+
+		info := commits[current.sha]
+		switch {
+		case strings.HasPrefix(text, "\t"):
+			current.content = text[1:]
+			current.author = info.author
+			current.date = info.date
+			lines = append(lines, *current)
+			current = nil
+		case strings.HasPrefix(text, "author "):
+			info.author = strings.TrimPrefix(text, "author ")
+		case strings.HasPrefix(text, "author-time "):
+			if seconds, err := strconv.ParseInt(strings.TrimPrefix(text, "author-time "), 10, 64); err == nil {
+				info.date = time.Unix(seconds, 0).UTC()
+			}
+		}
+	}
+	return lines
+}
+
+// isBinaryContent applies git's heuristic: a file with a NUL byte in its first
+// 8000 bytes is binary
+func isBinaryContent(content []byte) bool {
+	if len(content) > 8000 {
+		content = content[:8000]
+	}
+	return bytes.IndexByte(content, 0) >= 0
+}
+
+func listPipelinesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	arguments := request.Params.Arguments
 	projectID := arguments["project_path"].(string)
 	status := arguments["status"].(string)
@@ -497,9 +1296,12 @@ func listPipelinesHandler(ctx context.Context, request mcp.CallToolRequest) (*mc
 		opt.Status = gitlab.Ptr(gitlab.BuildStateValue(status))
 	}
 
-	pipelines, _, err := gitlabClient().Pipelines.ListProjectPipelines(projectID, opt)
+	pipelines, nextPage, err := paginateGitLab(arguments, gitlabPagination, func(lo gitlab.ListOptions) ([]*gitlab.PipelineInfo, *gitlab.Response, error) {
+		opt.ListOptions = lo
+		return gitlabClient().Pipelines.ListProjectPipelines(projectID, opt, gitlab.WithContext(ctx))
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list pipelines: %v", err)
+		return nil, gitlabError(err, "failed to list pipelines")
 	}
 
 	var result strings.Builder
@@ -513,15 +1315,161 @@ func listPipelinesHandler(ctx context.Context, request mcp.CallToolRequest) (*mc
 		result.WriteString(fmt.Sprintf("Created: %s\n", pipeline.CreatedAt.Format("2006-01-02 15:04:05")))
 		result.WriteString(fmt.Sprintf("URL: %s\n\n", pipeline.WebURL))
 	}
+	result.WriteString(util.PaginationNote(nextPage, "page"))
 
 	return mcp.NewToolResultText(result.String()), nil
 }
 
+const maxPipelineWait = 30 * time.Minute
+
+// pipelineTerminalStates are the pipeline statuses after which a pipeline will not change on its own
+var pipelineTerminalStates = map[string]bool{
+	"success":  true,
+	"failed":   true,
+	"canceled": true,
+	"skipped":  true,
+	"manual":   true,
+}
+
+func waitPipelineHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	projectID := arguments["project_path"].(string)
+
+	interval := 15 * time.Second
+	if value, ok := arguments["interval_seconds"].(float64); ok && value > 0 {
+		interval = time.Duration(value * float64(time.Second))
+	}
+
+	timeout := 10 * time.Minute
+	if value, ok := arguments["timeout_seconds"].(float64); ok && value > 0 {
+		timeout = time.Duration(value * float64(time.Second))
+	}
+	if timeout > maxPipelineWait {
+		timeout = maxPipelineWait
+	}
+
+	var pipelineID int
+	if value, ok := arguments["pipeline_id"].(string); ok && value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "invalid pipeline_id")
+		}
+		pipelineID = id
+	} else {
+		ref, ok := arguments["ref"].(string)
+		if !ok || ref == "" {
+			return nil, util.NewToolError(util.ErrCodeInvalidArgument, "either pipeline_id or ref is required")
+		}
+
+		pipelines, _, err := gitlabClient().Pipelines.ListProjectPipelines(projectID, &gitlab.ListProjectPipelinesOptions{
+			Ref:         gitlab.Ptr(ref),
+			OrderBy:     gitlab.Ptr("id"),
+			Sort:        gitlab.Ptr("desc"),
+			ListOptions: gitlab.ListOptions{PerPage: 1},
+		}, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, gitlabError(err, "failed to list pipelines")
+		}
+		if len(pipelines) == 0 {
+			return nil, util.NewToolError(util.ErrCodeNotFound, "no pipelines found for ref %s", ref)
+		}
+		pipelineID = pipelines[0].ID
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		pipeline, _, err := gitlabClient().Pipelines.GetPipeline(projectID, pipelineID, gitlab.WithContext(ctx))
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("stopped waiting for pipeline #%d: %v", pipelineID, ctx.Err())
+			}
+			return nil, gitlabError(err, "failed to get pipeline")
+		}
+
+		if pipelineTerminalStates[pipeline.Status] {
+			var result strings.Builder
+			result.WriteString(fmt.Sprintf("Pipeline #%d\n", pipeline.ID))
+			result.WriteString(fmt.Sprintf("Status: %s\n", pipeline.Status))
+			result.WriteString(fmt.Sprintf("Ref: %s\n", pipeline.Ref))
+			result.WriteString(fmt.Sprintf("SHA: %s\n", pipeline.SHA))
+			result.WriteString(fmt.Sprintf("URL: %s\n", pipeline.WebURL))
+
+			if pipeline.Status == "failed" {
+				jobs, _, err := gitlabClient().Jobs.ListPipelineJobs(projectID, pipelineID, &gitlab.ListJobsOptions{
+					Scope:       &[]gitlab.BuildStateValue{gitlab.Failed},
+					ListOptions: gitlab.ListOptions{PerPage: 100},
+				}, gitlab.WithContext(ctx))
+				if err != nil {
+					return nil, gitlabError(err, "failed to list failed jobs")
+				}
+
+				result.WriteString("\nFailed Jobs:\n")
+				for _, job := range jobs {
+					result.WriteString(fmt.Sprintf("- %s (stage: %s): %s\n", job.Name, job.Stage, job.WebURL))
+				}
+			}
+
+			return mcp.NewToolResultText(result.String()), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return mcp.NewToolResultText(fmt.Sprintf("Timed out waiting for pipeline #%d\nLast Status: %s\nURL: %s\n",
+				pipeline.ID, pipeline.Status, pipeline.WebURL)), nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeDiffSummary lists changed files with their added and removed line counts
+func writeDiffSummary(result *strings.Builder, changes []*gitlab.MergeRequestDiff) {
+	var totalAdded, totalRemoved int
+
+	result.WriteString("Changes Summary:\n")
+	for _, change := range changes {
+		added, removed := countDiffLines(change.Diff)
+		totalAdded += added
+		totalRemoved += removed
+
+		status := "modified"
+		switch {
+		case change.NewFile:
+			status = "added"
+		case change.DeletedFile:
+			status = "deleted"
+		case change.RenamedFile:
+			status = fmt.Sprintf("renamed from %s", change.OldPath)
+		}
+		result.WriteString(fmt.Sprintf("- %s (%s) +%d -%d\n", change.NewPath, status, added, removed))
+	}
+
+	result.WriteString(fmt.Sprintf("\nTotal: %d files changed, +%d -%d\n", len(changes), totalAdded, totalRemoved))
+}
+
+// countDiffLines counts the added and removed lines of a unified diff
+func countDiffLines(diff string) (added, removed int) {
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return added, removed
+}
+
 func listCommitsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
 	projectID := arguments["project_path"].(string)
 	since, ok := arguments["since"].(string)
 	if !ok {
-		return nil, fmt.Errorf("missing required argument: since")
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "missing required argument: since")
 	}
 
 	until := time.Now().Format("2006-01-02")
@@ -529,19 +1477,19 @@ func listCommitsHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 		until = value.(string)
 	}
 
-	ref := "develop"
-	if value, ok := arguments["ref"]; ok {
-		ref = value.(string)
+	ref, _ := arguments["ref"].(string)
+	if ref == "" {
+		ref = projectDefaultBranch(projectID)
 	}
 
 	sinceTime, err := time.Parse("2006-01-02", since)
 	if err != nil {
-		return nil, fmt.Errorf("invalid since date: %v", err)
+		return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "invalid since date")
 	}
 
 	untilTime, err := time.Parse("2006-01-02 15:04:05", until+" 23:00:00")
 	if err != nil {
-		return nil, fmt.Errorf("invalid until date: %v", err)
+		return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "invalid until date")
 	}
 
 	opt := &gitlab.ListCommitsOptions{
@@ -550,9 +1498,12 @@ func listCommitsHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 		RefName: gitlab.Ptr(ref),
 	}
 
-	commits, _, err := gitlabClient().Commits.ListCommits(projectID, opt)
+	commits, nextPage, err := paginateGitLab(arguments, gitlabPagination, func(lo gitlab.ListOptions) ([]*gitlab.Commit, *gitlab.Response, error) {
+		opt.ListOptions = lo
+		return gitlabClient().Commits.ListCommits(projectID, opt)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list commits: %v", err)
+		return nil, gitlabError(err, "failed to list commits")
 	}
 
 	var result strings.Builder
@@ -573,17 +1524,28 @@ func listCommitsHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 
 		}
 	}
+	result.WriteString(util.PaginationNote(nextPage, "page"))
 
 	return mcp.NewToolResultText(result.String()), nil
 }
 
+// projectDefaultBranch returns the default branch of a project, falling back to
+// GITLAB_DEFAULT_BRANCH when the project cannot be fetched
+func projectDefaultBranch(projectID string) string {
+	project, err := getGitLabProject(projectID, false)
+	if err != nil || project.DefaultBranch == "" {
+		return config.Get().GitLabDefaultBranch
+	}
+	return project.DefaultBranch
+}
+
 func getCommitDetailsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
 	projectID := arguments["project_path"].(string)
 	commitSHA := arguments["commit_sha"].(string)
 
 	commit, _, err := gitlabClient().Commits.GetCommit(projectID, commitSHA, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get commit details: %v", err)
+		return nil, gitlabError(err, "failed to get commit details")
 	}
 
 	opt := &gitlab.GetCommitDiffOptions{
@@ -594,7 +1556,7 @@ func getCommitDetailsHandler(arguments map[string]interface{}) (*mcp.CallToolRes
 
 	diffs, _, err := gitlabClient().Commits.GetCommitDiff(projectID, commitSHA, opt)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get commit diffs: %v", err)
+		return nil, gitlabError(err, "failed to get commit diffs")
 	}
 
 	var result strings.Builder
@@ -628,6 +1590,65 @@ func getCommitDetailsHandler(arguments map[string]interface{}) (*mcp.CallToolRes
 	return mcp.NewToolResultText(result.String()), nil
 }
 
+// defaultMaxDiffChars caps diff output so large comparisons stay within the client's context
+const defaultMaxDiffChars = 50000
+
+func compareHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	projectID := arguments["project_path"].(string)
+	from := arguments["from"].(string)
+	to := arguments["to"].(string)
+
+	maxDiffChars := defaultMaxDiffChars
+	if value, ok := arguments["max_diff_chars"].(float64); ok {
+		maxDiffChars = int(value)
+	}
+
+	compare, _, err := gitlabClient().Repositories.Compare(projectID, &gitlab.CompareOptions{
+		From: gitlab.Ptr(from),
+		To:   gitlab.Ptr(to),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, gitlabError(err, "failed to compare refs")
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Comparing %s...%s in project %s\n", from, to, projectID))
+	if compare.WebURL != "" {
+		result.WriteString(fmt.Sprintf("URL: %s\n", compare.WebURL))
+	}
+	if compare.CompareSameRef {
+		result.WriteString("Both refs point to the same commit\n")
+	}
+	if compare.CompareTimeout {
+		result.WriteString("Warning: the comparison timed out on the server, results may be incomplete\n")
+	}
+
+	result.WriteString(fmt.Sprintf("\nCommits (%d):\n", len(compare.Commits)))
+	for _, commit := range compare.Commits {
+		result.WriteString(fmt.Sprintf("- %s %s (%s, %s)\n",
+			commit.ShortID, commit.Title, commit.AuthorName, commit.CommittedDate.Format("2006-01-02 15:04:05")))
+	}
+
+	var diffs strings.Builder
+	for _, diff := range compare.Diffs {
+		diffs.WriteString(fmt.Sprintf("File: %s\n", diff.NewPath))
+		diffs.WriteString(fmt.Sprintf("Status: %s\n", getDiffStatus(diff)))
+
+		if diff.Diff != "" {
+			diffs.WriteString("```diff\n")
+			diffs.WriteString(diff.Diff)
+			diffs.WriteString("\n```\n")
+		}
+		diffs.WriteString("\n")
+	}
+
+	result.WriteString(fmt.Sprintf("\nDiffs (%d files):\n", len(compare.Diffs)))
+	result.WriteString(util.TruncateText(diffs.String(), maxDiffChars))
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
 func getDiffStatus(diff *gitlab.Diff) string {
 	if diff.NewFile {
 		return "Added"
@@ -645,7 +1666,7 @@ func listUserEventsHandler(arguments map[string]interface{}) (*mcp.CallToolResul
 	username := arguments["username"].(string)
 	since, ok := arguments["since"].(string)
 	if !ok {
-		return nil, fmt.Errorf("missing required argument: since")
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "missing required argument: since")
 	}
 
 	until := time.Now().Format("2006-01-02")
@@ -655,25 +1676,25 @@ func listUserEventsHandler(arguments map[string]interface{}) (*mcp.CallToolResul
 
 	sinceTime, err := time.Parse("2006-01-02", since)
 	if err != nil {
-		return nil, fmt.Errorf("invalid since date: %v", err)
+		return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "invalid since date")
 	}
 
 	untilTime, err := time.Parse("2006-01-02 15:04:05", until+" 23:59:59")
 	if err != nil {
-		return nil, fmt.Errorf("invalid until date: %v", err)
+		return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "invalid until date")
 	}
 
 	opt := &gitlab.ListContributionEventsOptions{
 		After:  gitlab.Ptr(gitlab.ISOTime(sinceTime)),
 		Before: gitlab.Ptr(gitlab.ISOTime(untilTime)),
-		ListOptions: gitlab.ListOptions{
-			PerPage: 100,
-		},
 	}
 
-	events, _, err := gitlabClient().Users.ListUserContributionEvents(username, opt)
+	events, nextPage, err := paginateGitLab(arguments, gitlabPagination, func(lo gitlab.ListOptions) ([]*gitlab.ContributionEvent, *gitlab.Response, error) {
+		opt.ListOptions = lo
+		return gitlabClient().Users.ListUserContributionEvents(username, opt)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list user events: %v", err)
+		return nil, gitlabError(err, "failed to list user events")
 	}
 
 	var result strings.Builder
@@ -706,6 +1727,7 @@ func listUserEventsHandler(arguments map[string]interface{}) (*mcp.CallToolResul
 
 		result.WriteString("\n")
 	}
+	result.WriteString(util.PaginationNote(nextPage, "page"))
 
 	return mcp.NewToolResultText(result.String()), nil
 }
@@ -713,15 +1735,14 @@ func listUserEventsHandler(arguments map[string]interface{}) (*mcp.CallToolResul
 func listGroupUsersHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
 	groupID := arguments["group_id"].(string)
 
-	opt := &gitlab.ListGroupMembersOptions{
-		ListOptions: gitlab.ListOptions{
-			PerPage: 100,
-		},
-	}
+	opt := &gitlab.ListGroupMembersOptions{}
 
-	members, _, err := gitlabClient().Groups.ListGroupMembers(groupID, opt)
+	members, nextPage, err := paginateGitLab(arguments, gitlabPagination, func(lo gitlab.ListOptions) ([]*gitlab.GroupMember, *gitlab.Response, error) {
+		opt.ListOptions = lo
+		return gitlabClient().Groups.ListGroupMembers(groupID, opt)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list group members: %v", err)
+		return nil, gitlabError(err, "failed to list group members")
 	}
 
 	var result strings.Builder
@@ -738,6 +1759,7 @@ func listGroupUsersHandler(arguments map[string]interface{}) (*mcp.CallToolResul
 		}
 		result.WriteString("\n")
 	}
+	result.WriteString(util.PaginationNote(nextPage, "page"))
 
 	return mcp.NewToolResultText(result.String()), nil
 }
@@ -777,9 +1799,13 @@ func createMergeRequestHandler(arguments map[string]interface{}) (*mcp.CallToolR
 		opt.Description = gitlab.String(description.(string))
 	}
 
+	if util.IsDryRun(arguments) {
+		return util.DryRunResult("create merge request", fmt.Sprintf("POST /projects/%s/merge_requests", url.PathEscape(projectID)), opt), nil
+	}
+
 	mr, _, err := gitlabClient().MergeRequests.CreateMergeRequest(projectID, opt)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create merge request: %v", err)
+		return nil, gitlabError(err, "failed to create merge request")
 	}
 
 	result := strings.Builder{}
@@ -806,8 +1832,9 @@ func cloneRepoHandler(arguments map[string]interface{}) (*mcp.CallToolResult, er
 	if value, ok := arguments["ref"]; ok {
 		ref = value.(string)
 	}
+	forceRefresh, _ := arguments["force_refresh"].(bool)
 
-	localPath, err := repoCache.ensureRepo(projectPath, ref)
+	localPath, err := repoCache.ensureRepo(projectPath, ref, forceRefresh)
 	if err != nil {
 		return nil, err
 	}
@@ -827,3 +1854,327 @@ func cloneRepoHandler(arguments map[string]interface{}) (*mcp.CallToolResult, er
 
 	return mcp.NewToolResultText(result.String()), nil
 }
+
+func getSnippetHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	snippetID, err := strconv.Atoi(arguments["snippet_id"].(string))
+	if err != nil {
+		return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "invalid snippet_id")
+	}
+	projectPath, _ := arguments["project_path"].(string)
+
+	var snippet *gitlab.Snippet
+	var content []byte
+	if projectPath != "" {
+		snippet, _, err = gitlabClient().ProjectSnippets.GetSnippet(projectPath, snippetID, gitlab.WithContext(ctx))
+		if err == nil {
+			content, _, err = gitlabClient().ProjectSnippets.SnippetContent(projectPath, snippetID, gitlab.WithContext(ctx))
+		}
+	} else {
+		snippet, _, err = gitlabClient().Snippets.GetSnippet(snippetID, gitlab.WithContext(ctx))
+		if err == nil {
+			content, _, err = gitlabClient().Snippets.SnippetContent(snippetID, gitlab.WithContext(ctx))
+		}
+	}
+	if err != nil {
+		return nil, gitlabError(err, "failed to get snippet")
+	}
+
+	var result strings.Builder
+	writeSnippetDetails(&result, snippet)
+	result.WriteString("\nContent:\n")
+	result.Write(content)
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+func createSnippetHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	title := arguments["title"].(string)
+	fileName := arguments["file_name"].(string)
+	content := arguments["content"].(string)
+
+	visibility := "private"
+	if value, ok := arguments["visibility"].(string); ok && value != "" {
+		visibility = value
+	}
+	switch gitlab.VisibilityValue(visibility) {
+	case gitlab.PrivateVisibility, gitlab.InternalVisibility, gitlab.PublicVisibility:
+	default:
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "invalid visibility %q: use private, internal or public", visibility)
+	}
+
+	var description *string
+	if value, ok := arguments["description"].(string); ok && value != "" {
+		description = gitlab.Ptr(value)
+	}
+
+	var snippet *gitlab.Snippet
+	var err error
+	if projectPath, ok := arguments["project_path"].(string); ok && projectPath != "" {
+		snippet, _, err = gitlabClient().ProjectSnippets.CreateSnippet(projectPath, &gitlab.CreateProjectSnippetOptions{
+			Title:       gitlab.Ptr(title),
+			FileName:    gitlab.Ptr(fileName),
+			Content:     gitlab.Ptr(content),
+			Description: description,
+			Visibility:  gitlab.Ptr(gitlab.VisibilityValue(visibility)),
+		}, gitlab.WithContext(ctx))
+	} else {
+		snippet, _, err = gitlabClient().Snippets.CreateSnippet(&gitlab.CreateSnippetOptions{
+			Title:       gitlab.Ptr(title),
+			FileName:    gitlab.Ptr(fileName),
+			Content:     gitlab.Ptr(content),
+			Description: description,
+			Visibility:  gitlab.Ptr(gitlab.VisibilityValue(visibility)),
+		}, gitlab.WithContext(ctx))
+	}
+	if err != nil {
+		return nil, gitlabError(err, "failed to create snippet")
+	}
+
+	var result strings.Builder
+	result.WriteString("Snippet created successfully!\n\n")
+	writeSnippetDetails(&result, snippet)
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// writeSnippetDetails writes the snippet metadata, including its web and raw URLs
+func writeSnippetDetails(result *strings.Builder, snippet *gitlab.Snippet) {
+	result.WriteString(fmt.Sprintf("Snippet #%d: %s\n", snippet.ID, snippet.Title))
+	result.WriteString(fmt.Sprintf("File: %s\n", snippet.FileName))
+	result.WriteString(fmt.Sprintf("Visibility: %s\n", snippet.Visibility))
+	result.WriteString(fmt.Sprintf("Author: %s\n", snippet.Author.Username))
+	if snippet.CreatedAt != nil {
+		result.WriteString(fmt.Sprintf("Created: %s\n", snippet.CreatedAt.Format("2006-01-02 15:04:05")))
+	}
+	if snippet.Description != "" {
+		result.WriteString(fmt.Sprintf("Description: %s\n", snippet.Description))
+	}
+	result.WriteString(fmt.Sprintf("URL: %s\n", snippet.WebURL))
+	result.WriteString(fmt.Sprintf("Raw URL: %s\n", snippet.RawURL))
+}
+
+func listReleasesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	projectID := arguments["project_path"].(string)
+
+	releases, nextPage, err := paginateGitLab(arguments, releasesPagination, func(lo gitlab.ListOptions) ([]*gitlab.Release, *gitlab.Response, error) {
+		return gitlabClient().Releases.ListReleases(projectID, &gitlab.ListReleasesOptions{
+			ListOptions: lo,
+			OrderBy:     gitlab.Ptr("released_at"),
+			Sort:        gitlab.Ptr("desc"),
+		}, gitlab.WithContext(ctx))
+	})
+	if err != nil {
+		return nil, gitlabError(err, "failed to list releases")
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Releases for project %s:\n\n", projectID))
+
+	for _, release := range releases {
+		writeReleaseDetails(&result, release)
+		result.WriteString("\n")
+	}
+
+	if len(releases) == 0 {
+		result.WriteString("No releases found\n")
+	}
+	result.WriteString(util.PaginationNote(nextPage, "page"))
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+func createReleaseHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	projectID := arguments["project_path"].(string)
+	tagName := arguments["tag_name"].(string)
+
+	name := tagName
+	if value, ok := arguments["name"].(string); ok && value != "" {
+		name = value
+	}
+
+	opt := &gitlab.CreateReleaseOptions{
+		Name:    gitlab.Ptr(name),
+		TagName: gitlab.Ptr(tagName),
+	}
+
+	ref, _ := arguments["ref"].(string)
+	if ref != "" {
+		opt.Ref = gitlab.Ptr(ref)
+	}
+
+	description, _ := arguments["description"].(string)
+	if generate, _ := arguments["generate_description"].(bool); generate && description == "" {
+		commitsRef := ref
+		if commitsRef == "" {
+			commitsRef = tagName
+		}
+
+		var err error
+		description, err = releaseNotesSincePreviousRelease(ctx, projectID, commitsRef)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if description != "" {
+		opt.Description = gitlab.Ptr(description)
+	}
+
+	release, _, err := gitlabClient().Releases.CreateRelease(projectID, opt, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, gitlabError(err, "failed to create release")
+	}
+
+	var result strings.Builder
+	result.WriteString("Release created successfully!\n\n")
+	writeReleaseDetails(&result, release)
+	if release.Description != "" {
+		result.WriteString("\nDescription:\n")
+		result.WriteString(release.Description)
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// releaseNotesSincePreviousRelease builds Markdown release notes from the commits
+// on ref since the most recent existing release
+func releaseNotesSincePreviousRelease(ctx context.Context, projectID, ref string) (string, error) {
+	opt := &gitlab.ListCommitsOptions{
+		RefName: gitlab.Ptr(ref),
+	}
+
+	previous, _, err := gitlabClient().Releases.ListReleases(projectID, &gitlab.ListReleasesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 1},
+		OrderBy:     gitlab.Ptr("released_at"),
+		Sort:        gitlab.Ptr("desc"),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return "", gitlabError(err, "failed to find previous release")
+	}
+
+	var previousTag string
+	if len(previous) > 0 && previous[0].Commit.CommittedDate != nil {
+		previousTag = previous[0].TagName
+		// Since is inclusive, skip the released commit itself
+		opt.Since = gitlab.Ptr(previous[0].Commit.CommittedDate.Add(time.Second))
+	}
+
+	commits, nextPage, err := paginateGitLab(map[string]interface{}{}, releaseNotesPagination, func(lo gitlab.ListOptions) ([]*gitlab.Commit, *gitlab.Response, error) {
+		opt.ListOptions = lo
+		return gitlabClient().Commits.ListCommits(projectID, opt, gitlab.WithContext(ctx))
+	})
+	if err != nil {
+		return "", gitlabError(err, "failed to list commits")
+	}
+
+	var notes strings.Builder
+	if previousTag != "" {
+		notes.WriteString(fmt.Sprintf("## Changes since %s\n\n", previousTag))
+	} else {
+		notes.WriteString("## Changes\n\n")
+	}
+
+	for _, commit := range commits {
+		// Merge commits repeat the titles of the commits they merge
+		if len(commit.ParentIDs) > 1 {
+			continue
+		}
+		notes.WriteString(fmt.Sprintf("- %s (%s)\n", commit.Title, commit.ShortID))
+	}
+
+	if len(commits) == 0 {
+		notes.WriteString("No changes\n")
+	}
+	if nextPage != 0 {
+		notes.WriteString(fmt.Sprintf("\nOnly the latest %d commits are listed\n", len(commits)))
+	}
+
+	return notes.String(), nil
+}
+
+// writeReleaseDetails writes the release metadata, its URL and assets
+func writeReleaseDetails(result *strings.Builder, release *gitlab.Release) {
+	result.WriteString(fmt.Sprintf("Release: %s\n", release.Name))
+	result.WriteString(fmt.Sprintf("Tag: %s\n", release.TagName))
+	result.WriteString(fmt.Sprintf("Author: %s\n", release.Author.Username))
+	if release.ReleasedAt != nil {
+		result.WriteString(fmt.Sprintf("Released: %s\n", release.ReleasedAt.Format("2006-01-02 15:04:05")))
+	}
+	if release.Commit.ShortID != "" {
+		result.WriteString(fmt.Sprintf("Commit: %s\n", release.Commit.ShortID))
+	}
+	result.WriteString(fmt.Sprintf("URL: %s\n", release.Links.Self))
+
+	if release.Assets.Count > 0 {
+		result.WriteString(fmt.Sprintf("Assets (%d):\n", release.Assets.Count))
+		for _, source := range release.Assets.Sources {
+			result.WriteString(fmt.Sprintf("- Source (%s): %s\n", source.Format, source.URL))
+		}
+		for _, link := range release.Assets.Links {
+			result.WriteString(fmt.Sprintf("- %s: %s\n", link.Name, link.URL))
+		}
+	}
+}
+
+func listWikiPagesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	projectPath := request.Params.Arguments["project_path"].(string)
+
+	pages, resp, err := gitlabClient().Wikis.ListWikis(projectPath, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden) {
+			return mcp.NewToolResultText(fmt.Sprintf("Project %s has no wiki, or it is disabled", projectPath)), nil
+		}
+		return nil, gitlabError(err, "failed to list wiki pages")
+	}
+
+	if len(pages) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("The wiki of %s has no pages", projectPath)), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Wiki pages of %s (%d):\n\n", projectPath, len(pages)))
+	for _, page := range pages {
+		result.WriteString(fmt.Sprintf("- %s\n  Slug: %s\n  Format: %s\n", page.Title, page.Slug, page.Format))
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+func getWikiPageHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	projectPath := arguments["project_path"].(string)
+	slug := arguments["slug"].(string)
+
+	page, resp, err := gitlabClient().Wikis.GetWikiPage(projectPath, slug, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, util.NewToolError(util.ErrCodeNotFound, "wiki page %s not found in %s; the project may have no wiki, use gitlab_list_wiki_pages to see its pages", slug, projectPath)
+		}
+		return nil, gitlabError(err, "failed to get wiki page")
+	}
+
+	// Markdown is returned as is; other markups are rendered by GitLab and
+	// converted to Markdown
+	content := page.Content
+	if page.Format != gitlab.WikiFormatMarkdown {
+		rendered, _, err := gitlabClient().Wikis.GetWikiPage(projectPath, slug, &gitlab.GetWikiPageOptions{RenderHTML: gitlab.Ptr(true)}, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, gitlabError(err, "failed to render wiki page")
+		}
+		if content, err = htmltomarkdownnnn.ConvertString(rendered.Content); err != nil {
+			return nil, fmt.Errorf("failed to convert wiki page to Markdown: %v", err)
+		}
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Title: %s\n", page.Title))
+	result.WriteString(fmt.Sprintf("Slug: %s\n", page.Slug))
+	result.WriteString(fmt.Sprintf("Format: %s\n", page.Format))
+	result.WriteString("\nContent:\n")
+	result.WriteString(content)
+
+	return mcp.NewToolResultText(result.String()), nil
+}
@@ -2,12 +2,14 @@ package tools
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
 	"strings"
 	"sync"
 
+	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
+	"github.com/athapong/aio-mcp/config"
 	"github.com/athapong/aio-mcp/services"
 	"github.com/athapong/aio-mcp/util"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -24,12 +26,21 @@ func RegisterGmailTools(s *server.MCPServer) {
 	)
 	s.AddTool(searchTool, util.ErrorGuard(util.AdaptLegacyHandler(gmailSearchHandler)))
 
+	// Get thread tool
+	getThreadTool := mcp.NewTool("gmail_get_thread",
+		mcp.WithDescription("Get all messages in a Gmail thread in order"),
+		mcp.WithString("thread_id", mcp.Required(), mcp.Description("The ID of the thread")),
+		mcp.WithBoolean("include_body", mcp.Description("Include the decoded body of each message instead of only the snippet")),
+	)
+	s.AddTool(getThreadTool, util.ErrorGuard(util.AdaptLegacyHandler(gmailGetThreadHandler)))
+
 	// Move to spam tool
 	spamTool := mcp.NewTool("gmail_move_to_spam",
 		mcp.WithDescription("Move specific emails to spam folder in Gmail by message IDs"),
 		mcp.WithString("message_ids", mcp.Required(), mcp.Description("Comma-separated list of message IDs to move to spam")),
+		util.WithDryRun(),
 	)
-	s.AddTool(spamTool, util.ErrorGuard(util.AdaptLegacyHandler(gmailMoveToSpamHandler)))
+	s.AddTool(spamTool, util.ErrorGuard(util.DryRunGuard("move emails to spam", "POST /gmail/v1/users/me/messages/{id}/modify", util.AdaptLegacyHandler(gmailMoveToSpamHandler))))
 
 	// Add create filter tool
 	createFilterTool := mcp.NewTool("gmail_create_filter",
@@ -43,8 +54,9 @@ func RegisterGmailTools(s *server.MCPServer) {
 		mcp.WithBoolean("mark_important", mcp.Description("Mark matching messages as important")),
 		mcp.WithBoolean("mark_read", mcp.Description("Mark matching messages as read")),
 		mcp.WithBoolean("archive", mcp.Description("Archive matching messages")),
+		util.WithDryRun(),
 	)
-	s.AddTool(createFilterTool, util.ErrorGuard(util.AdaptLegacyHandler(gmailCreateFilterHandler)))
+	s.AddTool(createFilterTool, util.ErrorGuard(util.DryRunGuard("create Gmail filter", "POST /gmail/v1/users/me/settings/filters", util.AdaptLegacyHandler(gmailCreateFilterHandler))))
 
 	// List filters tool
 	listFiltersTool := mcp.NewTool("gmail_list_filters",
@@ -62,26 +74,28 @@ func RegisterGmailTools(s *server.MCPServer) {
 	deleteFilterTool := mcp.NewTool("gmail_delete_filter",
 		mcp.WithDescription("Delete a Gmail filter by its ID"),
 		mcp.WithString("filter_id", mcp.Required(), mcp.Description("The ID of the filter to delete")),
+		util.WithDryRun(),
 	)
-	s.AddTool(deleteFilterTool, util.ErrorGuard(util.AdaptLegacyHandler(gmailDeleteFilterHandler)))
+	s.AddTool(deleteFilterTool, util.ErrorGuard(util.DryRunGuard("delete Gmail filter", "DELETE /gmail/v1/users/me/settings/filters/{filter_id}", util.AdaptLegacyHandler(gmailDeleteFilterHandler))))
 
 	// Add delete label tool
 	deleteLabelTool := mcp.NewTool("gmail_delete_label",
 		mcp.WithDescription("Delete a Gmail label by its ID"),
 		mcp.WithString("label_id", mcp.Required(), mcp.Description("The ID of the label to delete")),
+		util.WithDryRun(),
 	)
-	s.AddTool(deleteLabelTool, util.ErrorGuard(util.AdaptLegacyHandler(gmailDeleteLabelHandler)))
+	s.AddTool(deleteLabelTool, util.ErrorGuard(util.DryRunGuard("delete Gmail label", "DELETE /gmail/v1/users/me/labels/{label_id}", util.AdaptLegacyHandler(gmailDeleteLabelHandler))))
 }
 
 var gmailService = sync.OnceValue(func() *gmail.Service {
 	ctx := context.Background()
 
-	tokenFile := os.Getenv("GOOGLE_TOKEN_FILE")
+	tokenFile := config.Get().GoogleTokenFile
 	if tokenFile == "" {
 		panic("GOOGLE_TOKEN_FILE environment variable must be set")
 	}
 
-	credentialsFile := os.Getenv("GOOGLE_CREDENTIALS_FILE")
+	credentialsFile := config.Get().GoogleCredentialsFile
 	if credentialsFile == "" {
 		panic("GOOGLE_CREDENTIALS_FILE environment variable must be set")
 	}
@@ -117,7 +131,7 @@ func gmailSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 	for _, msg := range resp.Messages {
 		message, err := gmailService().Users.Messages.Get(user, msg.Id).Do()
 		if err != nil {
-			log.Printf("Failed to get message %s: %v", msg.Id, err)
+			slog.Error("Failed to get message", "id", msg.Id, "error", err)
 			continue
 		}
 
@@ -144,6 +158,98 @@ func gmailSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 	return mcp.NewToolResultText(result.String()), nil
 }
 
+func gmailGetThreadHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	threadID, ok := arguments["thread_id"].(string)
+	if !ok || threadID == "" {
+		return mcp.NewToolResultError("thread_id must be a string"), nil
+	}
+	includeBody, _ := arguments["include_body"].(bool)
+
+	thread, err := gmailService().Users.Threads.Get("me", threadID).Format("full").Do()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get thread: %v", err)), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Thread %s (%d messages):\n\n", thread.Id, len(thread.Messages)))
+
+	for _, message := range thread.Messages {
+		details := make(map[string]string)
+		if message.Payload != nil {
+			for _, header := range message.Payload.Headers {
+				switch header.Name {
+				case "From":
+					details["from"] = header.Value
+				case "To":
+					details["to"] = header.Value
+				case "Subject":
+					details["subject"] = header.Value
+				case "Date":
+					details["date"] = header.Value
+				}
+			}
+		}
+
+		result.WriteString(fmt.Sprintf("Message ID: %s\n", message.Id))
+		result.WriteString(fmt.Sprintf("From: %s\n", details["from"]))
+		result.WriteString(fmt.Sprintf("To: %s\n", details["to"]))
+		result.WriteString(fmt.Sprintf("Subject: %s\n", details["subject"]))
+		result.WriteString(fmt.Sprintf("Date: %s\n", details["date"]))
+		if includeBody {
+			result.WriteString(fmt.Sprintf("Body:\n%s\n", gmailMessageBody(message.Payload)))
+		} else {
+			result.WriteString(fmt.Sprintf("Snippet: %s\n", message.Snippet))
+		}
+		result.WriteString("-------------------\n")
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// gmailMessageBody returns the decoded text/plain body of a message, falling back
+// to the text/html body converted to Markdown
+func gmailMessageBody(payload *gmail.MessagePart) string {
+	if text := findMessagePart(payload, "text/plain"); text != "" {
+		return text
+	}
+
+	htmlBody := findMessagePart(payload, "text/html")
+	if htmlBody == "" {
+		return ""
+	}
+	markdown, err := htmltomarkdown.ConvertString(htmlBody)
+	if err != nil {
+		return htmlBody
+	}
+	return markdown
+}
+
+// findMessagePart returns the decoded data of the first part with the given MIME
+// type, searching nested multipart parts depth first
+func findMessagePart(part *gmail.MessagePart, mimeType string) string {
+	if part == nil {
+		return ""
+	}
+
+	if part.MimeType == mimeType && part.Body != nil && part.Body.Data != "" {
+		data, err := base64.URLEncoding.DecodeString(part.Body.Data)
+		if err != nil {
+			// Some messages omit padding
+			data, err = base64.RawURLEncoding.DecodeString(part.Body.Data)
+		}
+		if err == nil {
+			return string(data)
+		}
+	}
+
+	for _, child := range part.Parts {
+		if data := findMessagePart(child, mimeType); data != "" {
+			return data
+		}
+	}
+	return ""
+}
+
 func gmailMoveToSpamHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
 	messageIdsStr, ok := arguments["message_ids"].(string)
 	if !ok {
@@ -5,9 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
-	"os"
+	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/athapong/aio-mcp/config"
+	"github.com/athapong/aio-mcp/services"
 	"github.com/athapong/aio-mcp/util"
+	"github.com/google/uuid"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"googlemaps.github.io/maps"
@@ -36,9 +41,21 @@ func RegisterGoogleMapTools(s *server.MCPServer) {
 	placeDetailsTool := mcp.NewTool("maps_place_details",
 		mcp.WithDescription("Get detailed information about a specific place"),
 		mcp.WithString("place_id", mcp.Required(), mcp.Description("Google Maps place ID")),
+		mcp.WithString("session_token", mcp.Description("Session token from maps_place_autocomplete, ends the autocomplete session")),
 	)
 	s.AddTool(placeDetailsTool, util.ErrorGuard(util.AdaptLegacyHandler(placeDetailsHandler)))
 
+	// Place autocomplete tool
+	placeAutocompleteTool := mcp.NewTool("maps_place_autocomplete",
+		mcp.WithDescription("Get place predictions for a partial address or place name. Pass the returned session_token to maps_place_details to bill the lookup as one session"),
+		mcp.WithString("input", mcp.Required(), mcp.Description("Partial text to complete")),
+		mcp.WithString("location", mcp.Description("Bias results around this point, as 'lat,lng'")),
+		mcp.WithNumber("radius", mcp.Description("Bias radius in meters around location")),
+		mcp.WithString("types", mcp.Description("Restrict results to a type: geocode, address, establishment, (regions) or (cities)")),
+		mcp.WithString("session_token", mcp.Description("Session token from a previous autocomplete call; a new one is created if omitted")),
+	)
+	s.AddTool(placeAutocompleteTool, util.ErrorGuard(util.AdaptLegacyHandler(placeAutocompleteHandler)))
+
 	// Directions tool
 	directionsTool := mcp.NewTool("maps_directions",
 		mcp.WithDescription("Get directions between locations"),
@@ -47,18 +64,20 @@ func RegisterGoogleMapTools(s *server.MCPServer) {
 		mcp.WithString("mode", mcp.Description("Travel mode: driving (default), walking, bicycling, transit")),
 		mcp.WithString("waypoints", mcp.Description("Optional waypoints separated by '|' (e.g. 'place_id:ChIJ...|place_id:ChIJ...')")),
 		mcp.WithBoolean("alternatives", mcp.Description("Return alternative routes if available")),
+		mcp.WithString("departure_time", mcp.Description("Departure time for traffic-aware durations: now (default), an RFC 3339 time or a Unix timestamp in seconds; must not be in the past")),
+		mcp.WithString("traffic_model", mcp.Description("Traffic model for driving durations: best_guess (default), pessimistic or optimistic")),
 	)
 	s.AddTool(directionsTool, util.ErrorGuard(util.AdaptLegacyHandler(directionsHandler)))
 }
 
 // getGoogleMapsClient creates and returns a Google Maps client
 func getGoogleMapsClient() (*maps.Client, error) {
-	apiKey := os.Getenv("GOOGLE_MAPS_API_KEY")
+	apiKey := config.Get().GoogleMapsAPIKey
 	if apiKey == "" {
 		return nil, fmt.Errorf("GOOGLE_MAPS_API_KEY environment variable not set")
 	}
 
-	return maps.NewClient(maps.WithAPIKey(apiKey))
+	return maps.NewClient(maps.WithAPIKey(apiKey), maps.WithHTTPClient(services.RateLimitedHTTPClient("google_maps", &http.Client{})))
 }
 
 // locationSearchHandler handles location search requests
@@ -233,8 +252,14 @@ func placeDetailsHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
+	sessionToken, err := parseSessionToken(arguments)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	req := &maps.PlaceDetailsRequest{
-		PlaceID: placeID,
+		PlaceID:      placeID,
+		SessionToken: sessionToken,
 		Fields: []maps.PlaceDetailsFieldMask{
 			maps.PlaceDetailsFieldMaskName,
 			maps.PlaceDetailsFieldMaskFormattedAddress,
@@ -282,6 +307,90 @@ func placeDetailsHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 	return mcp.NewToolResultText(string(jsonData)), nil
 }
 
+// placeAutocompleteHandler returns place predictions for partial input
+func placeAutocompleteHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	input, ok := arguments["input"].(string)
+	if !ok || input == "" {
+		return mcp.NewToolResultError("input is required and must be a string"), nil
+	}
+
+	client, err := getGoogleMapsClient()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	sessionToken, err := parseSessionToken(arguments)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if uuid.UUID(sessionToken) == uuid.Nil {
+		sessionToken = maps.NewPlaceAutocompleteSessionToken()
+	}
+
+	req := &maps.PlaceAutocompleteRequest{
+		Input:        input,
+		SessionToken: sessionToken,
+	}
+
+	if location, ok := arguments["location"].(string); ok && location != "" {
+		latLng, err := maps.ParseLatLng(location)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid location: %v", err)), nil
+		}
+		req.Location = &latLng
+	}
+
+	if radius, ok := arguments["radius"].(float64); ok && radius > 0 {
+		req.Radius = uint(radius)
+	}
+
+	if types, ok := arguments["types"].(string); ok && types != "" {
+		placeType, err := maps.ParseAutocompletePlaceType(types)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid types: %v", err)), nil
+		}
+		req.Types = placeType
+	}
+
+	resp, err := client.PlaceAutocomplete(context.Background(), req)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Google Maps API error: %v", err)), nil
+	}
+
+	predictions := make([]map[string]interface{}, 0, len(resp.Predictions))
+	for _, prediction := range resp.Predictions {
+		predictions = append(predictions, map[string]interface{}{
+			"place_id":    prediction.PlaceID,
+			"description": prediction.Description,
+			"types":       prediction.Types,
+		})
+	}
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"session_token": uuid.UUID(sessionToken).String(),
+		"predictions":   predictions,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal JSON: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// parseSessionToken reads the optional session_token argument
+func parseSessionToken(arguments map[string]interface{}) (maps.PlaceAutocompleteSessionToken, error) {
+	value, ok := arguments["session_token"].(string)
+	if !ok || value == "" {
+		return maps.PlaceAutocompleteSessionToken(uuid.Nil), nil
+	}
+
+	token, err := uuid.Parse(value)
+	if err != nil {
+		return maps.PlaceAutocompleteSessionToken(uuid.Nil), fmt.Errorf("invalid session_token: %v", err)
+	}
+	return maps.PlaceAutocompleteSessionToken(token), nil
+}
+
 // directionsHandler handles requests for directions between two locations
 func directionsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
 	// Extract required parameters
@@ -306,6 +415,27 @@ func directionsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, e
 		}
 	}
 
+	departure := time.Now()
+	departureTime := "now"
+	if value, ok := arguments["departure_time"].(string); ok && value != "" && value != "now" {
+		parsed, err := parseDepartureTime(value)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		departure = parsed
+		departureTime = strconv.FormatInt(parsed.Unix(), 10)
+	}
+
+	var trafficModel maps.TrafficModel
+	if value, ok := arguments["traffic_model"].(string); ok && value != "" {
+		switch maps.TrafficModel(value) {
+		case maps.TrafficModelBestGuess, maps.TrafficModelPessimistic, maps.TrafficModelOptimistic:
+			trafficModel = maps.TrafficModel(value)
+		default:
+			return mcp.NewToolResultError("Invalid traffic_model. Must be one of: best_guess, pessimistic, optimistic"), nil
+		}
+	}
+
 	// Create Google Maps client
 	client, err := getGoogleMapsClient()
 	if err != nil {
@@ -317,7 +447,8 @@ func directionsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, e
 		Origin:        origin,
 		Destination:   destination,
 		Mode:          maps.TravelModeDriving,
-		DepartureTime: "now",
+		DepartureTime: departureTime,
+		TrafficModel:  trafficModel,
 	}
 
 	// Add waypoints if provided
@@ -349,12 +480,13 @@ func directionsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, e
 
 		// Calculate total distance and duration
 		var totalDistance int
-		var totalDuration float64
+		var totalDuration, totalTrafficDuration float64
 		var steps []map[string]interface{}
 
 		for _, leg := range route.Legs {
 			totalDistance += leg.Distance.Meters
 			totalDuration += leg.Duration.Seconds()
+			totalTrafficDuration += leg.DurationInTraffic.Seconds()
 
 			for _, step := range leg.Steps {
 				stepInfo := map[string]interface{}{
@@ -369,20 +501,6 @@ func directionsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, e
 				steps = append(steps, stepInfo)
 			}
 		}
-		// Format as hours and minutes for better readability
-		hours := int(totalDuration / 3600)
-		minutes := int(math.Mod(totalDuration, 3600) / 60)
-		durationText := ""
-		durationText = ""
-		if hours > 0 {
-			durationText = fmt.Sprintf("%d hours", hours)
-			if minutes > 0 {
-				durationText += fmt.Sprintf(" %d minutes", minutes)
-			}
-		} else {
-			durationText = fmt.Sprintf("%d minutes", minutes)
-		}
-
 		// Add distance and duration info
 		routeInfo["distance"] = map[string]interface{}{
 			"meters": totalDistance,
@@ -390,7 +508,17 @@ func directionsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, e
 		}
 		routeInfo["duration"] = map[string]interface{}{
 			"seconds": totalDuration,
-			"text":    durationText,
+			"text":    formatDurationText(totalDuration),
+		}
+		routeInfo["eta"] = departure.Add(time.Duration(totalDuration) * time.Second).Format(time.RFC3339)
+
+		// Traffic-aware durations are only returned for driving with a departure time
+		if totalTrafficDuration > 0 {
+			routeInfo["duration_in_traffic"] = map[string]interface{}{
+				"seconds": totalTrafficDuration,
+				"text":    formatDurationText(totalTrafficDuration),
+			}
+			routeInfo["eta_in_traffic"] = departure.Add(time.Duration(totalTrafficDuration) * time.Second).Format(time.RFC3339)
 		}
 		routeInfo["steps"] = steps
 		routeInfo["encoded_overview_polyline"] = route.OverviewPolyline.Points
@@ -401,10 +529,14 @@ func directionsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, e
 	}
 
 	data := map[string]interface{}{
-		"origin":      origin,
-		"destination": destination,
-		"mode":        mode,
-		"routes":      formattedRoutes,
+		"origin":         origin,
+		"destination":    destination,
+		"mode":           mode,
+		"departure_time": departure.Format(time.RFC3339),
+		"routes":         formattedRoutes,
+	}
+	if trafficModel != "" {
+		data["traffic_model"] = trafficModel
 	}
 
 	jsonData, err := json.Marshal(data)
@@ -414,3 +546,33 @@ func directionsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, e
 
 	return mcp.NewToolResultText(string(jsonData)), nil
 }
+
+// parseDepartureTime parses an RFC 3339 time or a Unix timestamp in seconds,
+// rejecting times in the past which the Directions API does not accept
+func parseDepartureTime(value string) (time.Time, error) {
+	departure, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		seconds, parseErr := strconv.ParseInt(value, 10, 64)
+		if parseErr != nil {
+			return time.Time{}, fmt.Errorf("invalid departure_time %q: use now, an RFC 3339 time or a Unix timestamp", value)
+		}
+		departure = time.Unix(seconds, 0)
+	}
+	if departure.Before(time.Now().Add(-time.Minute)) {
+		return time.Time{}, fmt.Errorf("departure_time %s is in the past", departure.Format(time.RFC3339))
+	}
+	return departure, nil
+}
+
+// formatDurationText formats a duration in seconds as hours and minutes
+func formatDurationText(seconds float64) string {
+	hours := int(seconds / 3600)
+	minutes := int(math.Mod(seconds, 3600) / 60)
+	if hours == 0 {
+		return fmt.Sprintf("%d minutes", minutes)
+	}
+	if minutes == 0 {
+		return fmt.Sprintf("%d hours", hours)
+	}
+	return fmt.Sprintf("%d hours %d minutes", hours, minutes)
+}
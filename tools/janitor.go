@@ -0,0 +1,218 @@
+package tools
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/athapong/aio-mcp/config"
+	"github.com/athapong/aio-mcp/util"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// cleanupReport describes what a cleanup run removed
+type cleanupReport struct {
+	ScreenshotsRemoved int
+	ReposRemoved       int
+	BytesReclaimed     int64
+}
+
+// RegisterCleanupTool registers a tool to trigger cleanup of generated files manually
+func RegisterCleanupTool(s *server.MCPServer) {
+	tool := mcp.NewTool("cleanup_files",
+		mcp.WithDescription("Delete old screenshots and evict cloned GitLab repositories over the cache size limit, reporting reclaimed space"),
+		util.WithDryRun(),
+	)
+	s.AddTool(tool, util.ErrorGuard(util.AdaptLegacyHandler(cleanupHandler)))
+}
+
+func cleanupHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	dryRun := util.IsDryRun(arguments)
+	report, err := cleanupGeneratedFiles(dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return util.DryRunResult("clean up generated files", "local screenshots and repository cache", map[string]interface{}{
+			"screenshots_removed": report.ScreenshotsRemoved,
+			"repos_removed":       report.ReposRemoved,
+			"bytes_reclaimed":     report.BytesReclaimed,
+		}), nil
+	}
+
+	result := fmt.Sprintf("Removed %d screenshot(s) and %d cached repositories, reclaimed %.2f MB",
+		report.ScreenshotsRemoved, report.ReposRemoved, float64(report.BytesReclaimed)/(1024*1024))
+	if config.Get().OutputDir == "" {
+		result += "\nScreenshots are only cleaned up when OUTPUT_DIR is set"
+	}
+	return mcp.NewToolResultText(result), nil
+}
+
+// StartJanitor runs a cleanup in the background on startup, keeping the
+// repository cache within GITLAB_REPO_CACHE_MAX_MB, and then every
+// CLEANUP_INTERVAL when it is set
+func StartJanitor() {
+	run := func() {
+		report, err := cleanupGeneratedFiles(false)
+		if err != nil {
+			slog.Error("Cleanup failed", "error", err)
+			return
+		}
+		if report.ScreenshotsRemoved > 0 || report.ReposRemoved > 0 {
+			slog.Info("Cleanup removed generated files",
+				"screenshots", report.ScreenshotsRemoved, "repos", report.ReposRemoved, "bytes_reclaimed", report.BytesReclaimed)
+		}
+	}
+
+	interval := config.Get().CleanupInterval
+	go func() {
+		run()
+		if interval <= 0 {
+			return
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			run()
+		}
+	}()
+}
+
+// cleanupGeneratedFiles removes old screenshots and evicts cached repositories.
+// With dryRun set it only reports what would be removed.
+func cleanupGeneratedFiles(dryRun bool) (cleanupReport, error) {
+	var report cleanupReport
+	cfg := config.Get()
+
+	if err := cleanupScreenshots(cfg.ScreenshotMaxAge, dryRun, &report); err != nil {
+		return report, err
+	}
+	if err := cleanupRepoCache(cfg.GitLabRepoCacheMaxMB*1024*1024, dryRun, &report); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// cleanupScreenshots removes screenshots in the output directory older than
+// maxAge. Without OUTPUT_DIR screenshots are saved to the working directory,
+// which may hold the user's own files, so nothing is removed.
+func cleanupScreenshots(maxAge time.Duration, dryRun bool, report *cleanupReport) error {
+	if config.Get().OutputDir == "" {
+		return nil
+	}
+
+	pattern, err := util.OutputPath("", "screenshot_*.png")
+	if err != nil {
+		return err
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to list screenshots: %v", err)
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if !dryRun {
+			if err := os.Remove(path); err != nil {
+				slog.Error("Failed to remove screenshot", "path", path, "error", err)
+				continue
+			}
+		}
+		report.ScreenshotsRemoved++
+		report.BytesReclaimed += info.Size()
+	}
+
+	return nil
+}
+
+// cleanupRepoCache evicts the least recently modified cloned repositories until
+// the cache fits within maxBytes
+func cleanupRepoCache(maxBytes int64, dryRun bool, report *cleanupReport) error {
+	repoCache.mu.Lock()
+	defer repoCache.mu.Unlock()
+
+	entries, err := os.ReadDir(repoCache.BaseDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read repository cache: %v", err)
+	}
+
+	type cachedRepo struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+
+	var (
+		repos []cachedRepo
+		total int64
+	)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(repoCache.BaseDir, entry.Name())
+		size := dirSize(path)
+		repos = append(repos, cachedRepo{path: path, size: size, modTime: info.ModTime()})
+		total += size
+	}
+
+	sort.Slice(repos, func(i, j int) bool {
+		return repos[i].modTime.Before(repos[j].modTime)
+	})
+
+	for _, repo := range repos {
+		if total <= maxBytes {
+			break
+		}
+		if !dryRun {
+			if err := os.RemoveAll(repo.path); err != nil {
+				slog.Error("Failed to remove cached repository", "path", repo.path, "error", err)
+				continue
+			}
+			for projectPath, localPath := range repoCache.Repos {
+				if localPath == repo.path {
+					delete(repoCache.Repos, projectPath)
+				}
+			}
+		}
+		total -= repo.size
+		report.ReposRemoved++
+		report.BytesReclaimed += repo.size
+	}
+
+	return nil
+}
+
+func dirSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && !d.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json" // added for unmarshalling raw issue
 	"fmt"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -36,6 +38,14 @@ func RegisterJiraTool(s *server.MCPServer) {
 		mcp.WithString("board_id", mcp.Required(), mcp.Description("Numeric ID of the Jira board (can be found in board URL)")),
 	)
 
+	// Sprint burndown tool
+	jiraSprintBurndownTool := mcp.NewTool("jira_sprint_burndown",
+		mcp.WithDescription("Compute the daily burndown of a sprint: remaining and completed story points from the sprint start to today or its end, for charting. Lists issues without an estimate"),
+		mcp.WithString("board_id", mcp.Required(), mcp.Description("Numeric ID of the Jira board (can be found in board URL)")),
+		mcp.WithString("sprint_id", mcp.Description("Sprint ID from jira_list_sprints (default: the board's active sprint)")),
+		mcp.WithString("points_field", mcp.Description("Name of the story point field (default: Story Points, or Story point estimate)")),
+	)
+
 	// Create issue tool
 	jiraCreateIssueTool := mcp.NewTool("jira_create_issue",
 		mcp.WithDescription("Create a new Jira issue with specified details. Returns the created issue's key, ID, and URL"),
@@ -43,6 +53,15 @@ func RegisterJiraTool(s *server.MCPServer) {
 		mcp.WithString("summary", mcp.Required(), mcp.Description("Brief title or headline of the issue")),
 		mcp.WithString("description", mcp.Required(), mcp.Description("Detailed explanation of the issue")),
 		mcp.WithString("issue_type", mcp.Required(), mcp.Description("Type of issue to create (common types: Bug, Task, Story, Epic)")),
+		mcp.WithString("idempotency_key", mcp.Description("Optional unique key; repeating a call with the same key returns the original result instead of creating a duplicate")),
+		util.WithDryRun(),
+	)
+
+	// Bulk create issues tool
+	jiraBulkCreateTool := mcp.NewTool("jira_bulk_create",
+		mcp.WithDescription("Create many Jira issues in one call. Returns a table of created issue keys and per-issue errors; issues that fail do not stop the others"),
+		mcp.WithString("issues", mcp.Required(), mcp.Description(`JSON array of issues, each with project_key, summary, issue_type and optional description, labels (array of strings) and assignee (account ID), e.g. [{"project_key":"KP","summary":"Add login page","issue_type":"Story","labels":["frontend"]}]`)),
+		util.WithDryRun(),
 	)
 
 	// Update issue tool
@@ -51,6 +70,7 @@ func RegisterJiraTool(s *server.MCPServer) {
 		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The unique identifier of the issue to update (e.g., KP-2)")),
 		mcp.WithString("summary", mcp.Description("New title for the issue (optional)")),
 		mcp.WithString("description", mcp.Description("New description for the issue (optional)")),
+		util.WithDryRun(),
 	)
 
 	// Add status list tool
@@ -65,14 +85,17 @@ func RegisterJiraTool(s *server.MCPServer) {
 		mcp.WithString("issue_key", mcp.Required(), mcp.Description("The issue to transition (e.g., KP-123)")),
 		mcp.WithString("transition_id", mcp.Required(), mcp.Description("Transition ID from available transitions list")),
 		mcp.WithString("comment", mcp.Description("Optional comment to add with transition")),
+		util.WithDryRun(),
 	)
 
 	s.AddTool(jiraSearchTool, util.ErrorGuard(util.AdaptLegacyHandler(jiraSearchHandler)))
 	s.AddTool(jiraListSprintTool, util.ErrorGuard(util.AdaptLegacyHandler(jiraListSprintHandler)))
-	s.AddTool(jiraCreateIssueTool, util.ErrorGuard(util.AdaptLegacyHandler(jiraCreateIssueHandler)))
-	s.AddTool(jiraUpdateIssueTool, util.ErrorGuard(util.AdaptLegacyHandler(jiraUpdateIssueHandler)))
+	s.AddTool(jiraSprintBurndownTool, util.ErrorGuard(util.AdaptLegacyHandler(jiraSprintBurndownHandler)))
+	s.AddTool(jiraCreateIssueTool, util.Idempotent(util.ErrorGuard(util.AdaptLegacyHandler(jiraCreateIssueHandler))))
+	s.AddTool(jiraBulkCreateTool, util.ErrorGuard(util.AdaptLegacyHandler(jiraBulkCreateHandler)))
+	s.AddTool(jiraUpdateIssueTool, util.ErrorGuard(util.DryRunGuard("update Jira issue", "PUT /rest/api/2/issue/{issue_key}", util.AdaptLegacyHandler(jiraUpdateIssueHandler))))
 	s.AddTool(jiraStatusListTool, util.ErrorGuard(util.AdaptLegacyHandler(jiraGetStatusesHandler)))
-	s.AddTool(jiraTransitionTool, util.ErrorGuard(util.AdaptLegacyHandler(jiraTransitionIssueHandler)))
+	s.AddTool(jiraTransitionTool, util.ErrorGuard(util.DryRunGuard("transition Jira issue", "POST /rest/api/2/issue/{issue_key}/transitions", util.AdaptLegacyHandler(jiraTransitionIssueHandler))))
 }
 
 func jiraUpdateIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
@@ -80,7 +103,7 @@ func jiraUpdateIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResu
 
 	issueKey, ok := arguments["issue_key"].(string)
 	if !ok {
-		return nil, fmt.Errorf("issue_key argument is required")
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "issue_key argument is required")
 	}
 
 	// Create update payload
@@ -103,9 +126,9 @@ func jiraUpdateIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResu
 	response, err := client.Issue.Update(ctx, issueKey, true, payload, nil, nil)
 	if err != nil {
 		if response != nil {
-			return nil, fmt.Errorf("failed to update issue: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			return nil, util.NewToolError(util.CodeFromStatus(response.Code), "failed to update issue: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
 		}
-		return nil, fmt.Errorf("failed to update issue: %v", err)
+		return nil, util.WrapToolError(util.ErrCodeUpstream, err, "failed to update issue")
 	}
 
 	return mcp.NewToolResultText("Issue updated successfully!"), nil
@@ -116,27 +139,24 @@ func jiraCreateIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResu
 
 	projectKey, ok := arguments["project_key"].(string)
 	if !ok {
-		return nil, fmt.Errorf("project_key argument is required")
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "project_key argument is required")
 	}
 
 	summary, ok := arguments["summary"].(string)
 	if !ok {
-		return nil, fmt.Errorf("summary argument is required")
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "summary argument is required")
 	}
 
 	description, ok := arguments["description"].(string)
 	if !ok {
-		return nil, fmt.Errorf("description argument is required")
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "description argument is required")
 	}
 
 	issueType, ok := arguments["issue_type"].(string)
 	if !ok {
-		return nil, fmt.Errorf("issue_type argument is required")
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "issue_type argument is required")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
-	defer cancel()
-
 	var payload = models.IssueSchemeV2{
 		Fields: &models.IssueFieldsSchemeV2{
 			Summary:     summary,
@@ -146,27 +166,176 @@ func jiraCreateIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResu
 		},
 	}
 
+	if util.IsDryRun(arguments) {
+		return util.DryRunResult("create Jira issue", "POST /rest/api/2/issue", payload), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
 	issue, response, err := client.Issue.Create(ctx, &payload, nil)
 	if err != nil {
 		if response != nil {
-			return nil, fmt.Errorf("failed to create issue: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			return nil, util.NewToolError(util.CodeFromStatus(response.Code), "failed to create issue: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
 		}
-		return nil, fmt.Errorf("failed to create issue: %v", err)
+		return nil, util.WrapToolError(util.ErrCodeUpstream, err, "failed to create issue")
 	}
 
 	result := fmt.Sprintf("Issue created successfully!\nKey: %s\nID: %s\nURL: %s", issue.Key, issue.ID, issue.Self)
 	return mcp.NewToolResultText(result), nil
 }
 
+// jiraBulkCreateLimit is the maximum number of issues the bulk create endpoint accepts per request
+const jiraBulkCreateLimit = 50
+
+// jiraIssueSpec is one issue of a jira_bulk_create call
+type jiraIssueSpec struct {
+	ProjectKey  string   `json:"project_key"`
+	Summary     string   `json:"summary"`
+	Description string   `json:"description"`
+	IssueType   string   `json:"issue_type"`
+	Labels      []string `json:"labels"`
+	Assignee    string   `json:"assignee"`
+}
+
+func jiraBulkCreateHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	issuesJSON, _ := arguments["issues"].(string)
+
+	var specs []jiraIssueSpec
+	if err := json.Unmarshal([]byte(issuesJSON), &specs); err != nil {
+		return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "issues must be a JSON array of issue objects")
+	}
+	if len(specs) == 0 {
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "issues must contain at least one issue")
+	}
+
+	keys := make([]string, len(specs))
+	errs := make([]string, len(specs))
+
+	// Validate every issue up front so one bad entry does not cost a request
+	var pending []int
+	var payloads []*models.IssueBulkSchemeV2
+	for i, spec := range specs {
+		var missing []string
+		for field, value := range map[string]string{"project_key": spec.ProjectKey, "summary": spec.Summary, "issue_type": spec.IssueType} {
+			if value == "" {
+				missing = append(missing, field)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			errs[i] = "missing " + strings.Join(missing, ", ")
+			continue
+		}
+
+		fields := &models.IssueFieldsSchemeV2{
+			Summary:     spec.Summary,
+			Project:     &models.ProjectScheme{Key: spec.ProjectKey},
+			Description: spec.Description,
+			IssueType:   &models.IssueTypeScheme{Name: spec.IssueType},
+			Labels:      spec.Labels,
+		}
+		if spec.Assignee != "" {
+			fields.Assignee = &models.UserScheme{AccountID: spec.Assignee}
+		}
+
+		pending = append(pending, i)
+		payloads = append(payloads, &models.IssueBulkSchemeV2{Payload: &models.IssueSchemeV2{Fields: fields}})
+	}
+
+	if util.IsDryRun(arguments) {
+		issues := make([]*models.IssueSchemeV2, 0, len(payloads))
+		for _, payload := range payloads {
+			issues = append(issues, payload.Payload)
+		}
+		return util.DryRunResult(fmt.Sprintf("create %d Jira issues (%d invalid)", len(issues), len(specs)-len(issues)), "POST /rest/api/2/issue/bulk", issues), nil
+	}
+
+	client := services.JiraClient()
+	for start := 0; start < len(payloads); start += jiraBulkCreateLimit {
+		end := min(start+jiraBulkCreateLimit, len(payloads))
+		batch := pending[start:end]
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		created, response, err := client.Issue.Creates(ctx, payloads[start:end])
+		cancel()
+
+		// When no issue could be created Jira fails the request but still
+		// reports the per-issue errors in the body
+		if err != nil && response != nil {
+			var body models.IssueBulkResponseScheme
+			if json.Unmarshal(response.Bytes.Bytes(), &body) == nil && len(body.Errors) > 0 {
+				created = &body
+			}
+		}
+		if created == nil {
+			message := err.Error()
+			if response != nil {
+				message = fmt.Sprintf("%s (status %d)", response.Bytes.String(), response.Code)
+			}
+			for _, i := range batch {
+				errs[i] = message
+			}
+			continue
+		}
+
+		failed := make(map[int]bool, len(created.Errors))
+		for _, failure := range created.Errors {
+			if failure.FailedElementNumber < 0 || failure.FailedElementNumber >= len(batch) {
+				continue
+			}
+			i := batch[failure.FailedElementNumber]
+			failed[failure.FailedElementNumber] = true
+			errs[i] = strings.Join(failure.ElementErrors.ErrorMessages, "; ")
+			if errs[i] == "" {
+				errs[i] = fmt.Sprintf("failed with status %d", failure.Status)
+			}
+		}
+
+		// Created issues are returned in request order, skipping the failed ones
+		next := 0
+		for n, i := range batch {
+			if failed[n] {
+				continue
+			}
+			if next < len(created.Issues) {
+				keys[i] = created.Issues[next].Key
+				next++
+			} else if errs[i] == "" {
+				errs[i] = "not created"
+			}
+		}
+	}
+
+	var result strings.Builder
+	createdCount := 0
+	result.WriteString("| # | Summary | Key | Error |\n|---|---|---|---|\n")
+	for i, spec := range specs {
+		if keys[i] != "" {
+			createdCount++
+		}
+		result.WriteString(fmt.Sprintf("| %d | %s | %s | %s |\n", i+1, escapeTableCell(spec.Summary), keys[i], escapeTableCell(errs[i])))
+	}
+	result.WriteString(fmt.Sprintf("\nCreated %d of %d issues", createdCount, len(specs)))
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// escapeTableCell keeps a value on one line inside a markdown table cell
+func escapeTableCell(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	return strings.ReplaceAll(value, "\n", " ")
+}
+
 func jiraListSprintHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
 	boardIDStr, ok := arguments["board_id"].(string)
 	if !ok {
-		return nil, fmt.Errorf("board_id argument is required")
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "board_id argument is required")
 	}
 
 	boardID, err := strconv.Atoi(boardIDStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid board_id: %v", err)
+		return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "invalid board_id")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
@@ -175,9 +344,9 @@ func jiraListSprintHandler(arguments map[string]interface{}) (*mcp.CallToolResul
 	sprints, response, err := services.AgileClient().Board.Sprints(ctx, boardID, 0, 50, []string{"active", "future"})
 	if err != nil {
 		if response != nil {
-			return nil, fmt.Errorf("failed to get sprints: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			return nil, util.NewToolError(util.CodeFromStatus(response.Code), "failed to get sprints: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
 		}
-		return nil, fmt.Errorf("failed to get sprints: %v", err)
+		return nil, util.WrapToolError(util.ErrCodeUpstream, err, "failed to get sprints")
 	}
 
 	if len(sprints.Values) == 0 {
@@ -192,13 +361,236 @@ func jiraListSprintHandler(arguments map[string]interface{}) (*mcp.CallToolResul
 	return mcp.NewToolResultText(result), nil
 }
 
+// jiraTimeLayout is the format of timestamps in Jira REST responses
+const jiraTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+// burndownIssue is a sprint issue with its estimate and, once done, when it was completed
+type burndownIssue struct {
+	key       string
+	summary   string
+	points    float64
+	estimated bool
+	done      *time.Time
+}
+
+func jiraSprintBurndownHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	boardID, err := strconv.Atoi(fmt.Sprint(arguments["board_id"]))
+	if err != nil {
+		return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "invalid board_id")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	sprint, err := burndownSprint(ctx, boardID, arguments["sprint_id"])
+	if err != nil {
+		return nil, err
+	}
+	if sprint.StartDate.IsZero() {
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "sprint %s has not started", sprint.Name)
+	}
+
+	fieldName, _ := arguments["points_field"].(string)
+	fieldID, fieldName, err := storyPointsField(ctx, fieldName)
+	if err != nil {
+		return nil, err
+	}
+
+	issues, err := burndownIssues(ctx, sprint.ID, fieldID)
+	if err != nil {
+		return nil, err
+	}
+
+	end := sprint.EndDate
+	if !sprint.CompleteDate.IsZero() {
+		end = sprint.CompleteDate
+	}
+	if now := time.Now(); end.IsZero() || now.Before(end) {
+		end = now
+	}
+
+	var total float64
+	var unestimated []string
+	for _, issue := range issues {
+		total += issue.points
+		if !issue.estimated {
+			unestimated = append(unestimated, fmt.Sprintf("- %s: %s", issue.key, issue.summary))
+		}
+	}
+
+	start := time.Date(sprint.StartDate.Year(), sprint.StartDate.Month(), sprint.StartDate.Day(), 0, 0, 0, 0, sprint.StartDate.Location())
+	sprintDays := sprint.EndDate.Sub(start).Hours() / 24
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Sprint: %s (ID: %d, %s)\n", sprint.Name, sprint.ID, sprint.State))
+	result.WriteString(fmt.Sprintf("Dates: %s to %s\n", sprint.StartDate.Format("2006-01-02"), sprint.EndDate.Format("2006-01-02")))
+	result.WriteString(fmt.Sprintf("Points field: %s\n", fieldName))
+	result.WriteString(fmt.Sprintf("Issues: %d, total points: %g\n\n", len(issues), total))
+
+	result.WriteString("| Date | Remaining | Completed | Ideal |\n|---|---|---|---|\n")
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		dayEnd := day.AddDate(0, 0, 1)
+		var completed float64
+		for _, issue := range issues {
+			if issue.done != nil && issue.done.Before(dayEnd) {
+				completed += issue.points
+			}
+		}
+
+		ideal := total
+		if sprintDays > 0 {
+			ideal = math.Max(0, total*(1-dayEnd.Sub(start).Hours()/24/sprintDays))
+		}
+		result.WriteString(fmt.Sprintf("| %s | %g | %g | %.1f |\n", day.Format("2006-01-02"), total-completed, completed, ideal))
+	}
+
+	result.WriteString("\nThe series covers the issues currently in the sprint; an issue counts as completed when it moved to its current done status.\n")
+	if len(unestimated) > 0 {
+		result.WriteString(fmt.Sprintf("\nIssues without %s (%d, counted as 0):\n%s\n", fieldName, len(unestimated), strings.Join(unestimated, "\n")))
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// burndownSprint returns the sprint with the given ID, or the board's active sprint
+func burndownSprint(ctx context.Context, boardID int, sprintArg interface{}) (*models.SprintScheme, error) {
+	if sprintIDStr, ok := sprintArg.(string); ok && sprintIDStr != "" {
+		sprintID, err := strconv.Atoi(sprintIDStr)
+		if err != nil {
+			return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "invalid sprint_id")
+		}
+		sprint, response, err := services.AgileClient().Sprint.Get(ctx, sprintID)
+		if err != nil {
+			if response != nil {
+				return nil, util.NewToolError(util.CodeFromStatus(response.Code), "failed to get sprint: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, util.WrapToolError(util.ErrCodeUpstream, err, "failed to get sprint")
+		}
+		return sprint, nil
+	}
+
+	sprints, response, err := services.AgileClient().Board.Sprints(ctx, boardID, 0, 1, []string{"active"})
+	if err != nil {
+		if response != nil {
+			return nil, util.NewToolError(util.CodeFromStatus(response.Code), "failed to get sprints: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return nil, util.WrapToolError(util.ErrCodeUpstream, err, "failed to get sprints")
+	}
+	if len(sprints.Values) == 0 {
+		return nil, util.NewToolError(util.ErrCodeNotFound, "board %d has no active sprint, pass sprint_id", boardID)
+	}
+	return (*models.SprintScheme)(sprints.Values[0]), nil
+}
+
+// storyPointsField finds the ID of the story point field by name, trying the
+// names Jira uses for company-managed and team-managed projects by default
+func storyPointsField(ctx context.Context, name string) (string, string, error) {
+	names := []string{"Story Points", "Story point estimate"}
+	if name != "" {
+		names = []string{name}
+	}
+
+	fields, response, err := services.JiraClient().Issue.Field.Gets(ctx)
+	if err != nil {
+		if response != nil {
+			return "", "", util.NewToolError(util.CodeFromStatus(response.Code), "failed to get field definitions: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+		}
+		return "", "", util.WrapToolError(util.ErrCodeUpstream, err, "failed to get field definitions")
+	}
+
+	for _, candidate := range names {
+		for _, field := range fields {
+			if strings.EqualFold(field.Name, candidate) {
+				return field.ID, field.Name, nil
+			}
+		}
+	}
+	return "", "", util.NewToolError(util.ErrCodeNotFound, "no story point field named %s, pass points_field", strings.Join(names, " or "))
+}
+
+// burndownIssues lists the issues of a sprint with their estimate and, for
+// issues in a done status, the time of their last move into that status
+func burndownIssues(ctx context.Context, sprintID int, pointsField string) ([]*burndownIssue, error) {
+	const pageSize = 100
+	jql := fmt.Sprintf("sprint = %d", sprintID)
+	fields := []string{"summary", "status", "resolutiondate", pointsField}
+
+	var issues []*burndownIssue
+	for startAt := 0; ; startAt += pageSize {
+		_, response, err := services.JiraClient().Issue.Search.Get(ctx, jql, fields, []string{"changelog"}, startAt, pageSize, "")
+		if err != nil {
+			if response != nil {
+				return nil, util.NewToolError(util.CodeFromStatus(response.Code), "failed to search sprint issues: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			}
+			return nil, util.WrapToolError(util.ErrCodeUpstream, err, "failed to search sprint issues")
+		}
+
+		// Story points are a custom field, so the page is decoded from the raw response
+		var page struct {
+			Total  int `json:"total"`
+			Issues []struct {
+				Key       string                       `json:"key"`
+				Fields    map[string]interface{}       `json:"fields"`
+				Changelog *models.IssueChangelogScheme `json:"changelog"`
+			} `json:"issues"`
+		}
+		if err := json.Unmarshal(response.Bytes.Bytes(), &page); err != nil {
+			return nil, util.WrapToolError(util.ErrCodeUpstream, err, "failed to decode sprint issues")
+		}
+
+		for _, raw := range page.Issues {
+			issue := &burndownIssue{key: raw.Key}
+			issue.summary, _ = raw.Fields["summary"].(string)
+			issue.points, issue.estimated = raw.Fields[pointsField].(float64)
+
+			status, _ := raw.Fields["status"].(map[string]interface{})
+			statusName, _ := status["name"].(string)
+			category, _ := status["statusCategory"].(map[string]interface{})
+			if key, _ := category["key"].(string); key == "done" {
+				issue.done = doneTime(raw.Changelog, statusName, raw.Fields["resolutiondate"])
+			}
+			issues = append(issues, issue)
+		}
+
+		if len(page.Issues) == 0 || startAt+len(page.Issues) >= page.Total {
+			return issues, nil
+		}
+	}
+}
+
+// doneTime returns when an issue last moved into its current done status,
+// falling back to its resolution date when the changelog does not show it
+func doneTime(changelog *models.IssueChangelogScheme, status string, resolutionDate interface{}) *time.Time {
+	var done *time.Time
+	if changelog != nil {
+		for _, history := range changelog.Histories {
+			for _, item := range history.Items {
+				if item.Field != "status" || item.ToString != status {
+					continue
+				}
+				if created, err := time.Parse(jiraTimeLayout, history.Created); err == nil && (done == nil || created.After(*done)) {
+					done = &created
+				}
+			}
+		}
+	}
+	if done == nil {
+		if value, ok := resolutionDate.(string); ok {
+			if resolved, err := time.Parse(jiraTimeLayout, value); err == nil {
+				done = &resolved
+			}
+		}
+	}
+	return done
+}
+
 func jiraSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
 	client := services.JiraClient()
 
 	// Get search text from arguments
 	jql, ok := arguments["jql"].(string)
 	if !ok {
-		return nil, fmt.Errorf("jql argument is required")
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "jql argument is required")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
@@ -207,9 +599,16 @@ func jiraSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult, e
 	searchResult, response, err := client.Issue.Search.Get(ctx, jql, nil, nil, 0, 30, "")
 	if err != nil {
 		if response != nil {
-			return nil, fmt.Errorf("failed to search issues: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			return nil, util.NewToolError(util.CodeFromStatus(response.Code), "failed to search issues: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
 		}
-		return nil, fmt.Errorf("failed to search issues: %v", err)
+		return nil, util.WrapToolError(util.ErrCodeUpstream, err, "failed to search issues")
+	}
+
+	if rendered, ok, err := util.RenderResult("jira_search_issue", map[string]interface{}{"Issues": searchResult.Issues}); ok {
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(rendered), nil
 	}
 
 	if len(searchResult.Issues) == 0 {
@@ -296,7 +695,7 @@ func jiraIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResult, er
 	// Get issue key from arguments
 	issueKey, ok := arguments["issue_key"].(string)
 	if !ok {
-		return nil, fmt.Errorf("issue_key argument is required")
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "issue_key argument is required")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
@@ -306,9 +705,9 @@ func jiraIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResult, er
 	issue, response, err := client.Issue.Get(ctx, issueKey, []string{"*all"}, []string{"transitions"})
 	if err != nil {
 		if response != nil {
-			return nil, fmt.Errorf("failed to get issue: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			return nil, util.NewToolError(util.CodeFromStatus(response.Code), "failed to get issue: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
 		}
-		return nil, fmt.Errorf("failed to get issue: %v", err)
+		return nil, util.WrapToolError(util.ErrCodeUpstream, err, "failed to get issue")
 	}
 
 	// Build subtasks string if they exist
@@ -346,20 +745,27 @@ func jiraIssueHandler(arguments map[string]interface{}) (*mcp.CallToolResult, er
 	var rawIssue map[string]interface{}
 	err = json.Unmarshal([]byte(response.Bytes.String()), &rawIssue)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal raw issue: %v", err)
+		return nil, util.WrapToolError(util.ErrCodeUpstream, err, "failed to unmarshal raw issue")
 	}
 	fieldsData, ok := rawIssue["fields"].(map[string]interface{})
 	if !ok {
-		return nil, fmt.Errorf("raw issue fields not found")
+		return nil, util.NewToolError(util.ErrCodeUpstream, "raw issue fields not found")
+	}
+
+	if rendered, ok, err := util.RenderResult("jira_get_issue", map[string]interface{}{"Issue": issue, "Fields": fieldsData}); ok {
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(rendered), nil
 	}
 
 	// Retrieve field definitions for mapping custom field IDs to friendly names
 	fieldsDef, resp2, err2 := client.Issue.Field.Gets(ctx)
 	if err2 != nil {
 		if resp2 != nil {
-			return nil, fmt.Errorf("failed to get field definitions: %s (endpoint: %s)", resp2.Bytes.String(), resp2.Endpoint)
+			return nil, util.NewToolError(util.CodeFromStatus(resp2.Code), "failed to get field definitions: %s (endpoint: %s)", resp2.Bytes.String(), resp2.Endpoint)
 		}
-		return nil, fmt.Errorf("failed to get field definitions: %v", err2)
+		return nil, util.WrapToolError(util.ErrCodeUpstream, err2, "failed to get field definitions")
 	}
 	// Define the custom field names to display
 	desiredCustom := map[string]bool{
@@ -434,7 +840,7 @@ func jiraGetStatusesHandler(arguments map[string]interface{}) (*mcp.CallToolResu
 
 	projectKey, ok := arguments["project_key"].(string)
 	if !ok {
-		return nil, fmt.Errorf("project_key argument is required")
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "project_key argument is required")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
@@ -443,9 +849,9 @@ func jiraGetStatusesHandler(arguments map[string]interface{}) (*mcp.CallToolResu
 	issueTypes, response, err := client.Project.Statuses(ctx, projectKey)
 	if err != nil {
 		if response != nil {
-			return nil, fmt.Errorf("failed to get statuses: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
+			return nil, util.NewToolError(util.CodeFromStatus(response.Code), "failed to get statuses: %s (endpoint: %s)", response.Bytes.String(), response.Endpoint)
 		}
-		return nil, fmt.Errorf("failed to get statuses: %v", err)
+		return nil, util.WrapToolError(util.ErrCodeUpstream, err, "failed to get statuses")
 	}
 
 	if len(issueTypes) == 0 {
@@ -469,12 +875,12 @@ func jiraTransitionIssueHandler(arguments map[string]interface{}) (*mcp.CallTool
 
 	issueKey, ok := arguments["issue_key"].(string)
 	if !ok || issueKey == "" {
-		return nil, fmt.Errorf("valid issue_key is required")
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "valid issue_key is required")
 	}
 
 	transitionID, ok := arguments["transition_id"].(string)
 	if !ok || transitionID == "" {
-		return nil, fmt.Errorf("valid transition_id is required")
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "valid transition_id is required")
 	}
 
 	var options *models.IssueMoveOptionsV2
@@ -490,11 +896,11 @@ func jiraTransitionIssueHandler(arguments map[string]interface{}) (*mcp.CallTool
 	response, err := client.Issue.Move(ctx, issueKey, transitionID, options)
 	if err != nil {
 		if response != nil {
-			return nil, fmt.Errorf("transition failed: %s (endpoint: %s)",
+			return nil, util.NewToolError(util.CodeFromStatus(response.Code), "transition failed: %s (endpoint: %s)",
 				response.Bytes.String(),
 				response.Endpoint)
 		}
-		return nil, fmt.Errorf("transition failed: %v", err)
+		return nil, util.WrapToolError(util.ErrCodeUpstream, err, "transition failed")
 	}
 
 	return mcp.NewToolResultText("Issue transition completed successfully"), nil
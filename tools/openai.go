@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/athapong/aio-mcp/services"
+	"github.com/athapong/aio-mcp/util"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/sashabaranov/go-openai"
+)
+
+func RegisterOpenAITool(s *server.MCPServer) {
+	chatTool := mcp.NewTool("chat_completion",
+		mcp.WithDescription("Send a chat completion request to the configured OpenAI-compatible endpoint (OPENAI_BASE_URL) and return the response"),
+		mcp.WithString("model", mcp.Required(), mcp.Description("Model name to use, e.g. gpt-4o-mini")),
+		mcp.WithString("messages", mcp.Description("JSON array of messages, e.g. [{\"role\":\"user\",\"content\":\"Hello\"}]. Either messages or prompt is required")),
+		mcp.WithString("prompt", mcp.Description("Single user prompt, used when messages is not provided")),
+		mcp.WithNumber("temperature", mcp.Description("Sampling temperature")),
+		mcp.WithNumber("max_tokens", mcp.Description("Maximum number of tokens to generate")),
+		mcp.WithBoolean("include_usage", mcp.Description("Append token usage (prompt/completion/total) and model to the result")),
+	)
+
+	s.AddTool(chatTool, util.ErrorGuard(chatCompletionHandler))
+}
+
+func chatCompletionHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	model, ok := arguments["model"].(string)
+	if !ok || model == "" {
+		return nil, fmt.Errorf("model argument is required")
+	}
+
+	var messages []openai.ChatCompletionMessage
+	if messagesJSON, ok := arguments["messages"].(string); ok && messagesJSON != "" {
+		var parsed []Message
+		if err := json.Unmarshal([]byte(messagesJSON), &parsed); err != nil {
+			return nil, fmt.Errorf("invalid messages: %v", err)
+		}
+		for _, message := range parsed {
+			messages = append(messages, openai.ChatCompletionMessage{
+				Role:    message.Role,
+				Content: message.Content,
+			})
+		}
+	} else if prompt, ok := arguments["prompt"].(string); ok && prompt != "" {
+		messages = append(messages, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleUser,
+			Content: prompt,
+		})
+	}
+
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("either messages or prompt is required")
+	}
+
+	req := openai.ChatCompletionRequest{
+		Model:    model,
+		Messages: messages,
+	}
+	if temperature, ok := arguments["temperature"].(float64); ok {
+		req.Temperature = float32(temperature)
+	}
+	if maxTokens, ok := arguments["max_tokens"].(float64); ok {
+		req.MaxTokens = int(maxTokens)
+	}
+
+	resp, err := services.DefaultOpenAIClient().CreateChatCompletion(ctx, req)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to generate content: %s", err)), nil
+	}
+
+	if len(resp.Choices) == 0 {
+		return mcp.NewToolResultError("no response from model"), nil
+	}
+
+	content := resp.Choices[0].Message.Content
+	if includeUsage, _ := arguments["include_usage"].(bool); includeUsage {
+		content += usageFooter(resp.Model, int64(resp.Usage.PromptTokens), int64(resp.Usage.CompletionTokens), int64(resp.Usage.TotalTokens))
+	}
+
+	return mcp.NewToolResultText(content), nil
+}
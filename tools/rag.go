@@ -1,12 +1,25 @@
 package tools
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"math"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/athapong/aio-mcp/config"
 	"github.com/athapong/aio-mcp/services"
 	"github.com/athapong/aio-mcp/util"
 	"github.com/google/uuid"
@@ -15,8 +28,50 @@ import (
 	"github.com/pkoukk/tiktoken-go"
 	"github.com/qdrant/go-client/qdrant"
 	"github.com/sashabaranov/go-openai"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// defaultEmbeddingModel is the embedding model used when a RAG tool is not
+// given one, RAG_DEFAULT_MODEL or codesmart.embedding
+func defaultEmbeddingModel() string {
+	return config.Get().RAGDefaultModel
+}
+
+// withCollection adds the collection argument to a RAG tool definition. It is
+// optional when RAG_DEFAULT_COLLECTION is set, with the description noting it.
+func withCollection(description string) mcp.ToolOption {
+	if defaultCollection := config.Get().RAGDefaultCollection; defaultCollection != "" {
+		return mcp.WithString("collection", mcp.Description(fmt.Sprintf("%s (default: %s)", description, defaultCollection)))
+	}
+	return mcp.WithString("collection", mcp.Required(), mcp.Description(description))
+}
+
+// collectionArgument reads the collection argument, falling back to RAG_DEFAULT_COLLECTION
+func collectionArgument(arguments map[string]interface{}) (string, error) {
+	if collection, ok := arguments["collection"].(string); ok && collection != "" {
+		return collection, nil
+	}
+	if defaultCollection := config.Get().RAGDefaultCollection; defaultCollection != "" {
+		return defaultCollection, nil
+	}
+	return "", util.NewToolError(util.ErrCodeInvalidArgument, "collection argument is required, or set RAG_DEFAULT_COLLECTION")
+}
+
+// embeddingModelsMu guards embeddingModelDimensions, which grows with the models
+// configured in RAG_EMBEDDING_MODELS and those registered by the dimensions argument
+var embeddingModelsMu sync.RWMutex
+
+// loadConfiguredEmbeddingModels merges RAG_EMBEDDING_MODELS into the built-in models
+var loadConfiguredEmbeddingModels = sync.OnceFunc(func() {
+	embeddingModelsMu.Lock()
+	defer embeddingModelsMu.Unlock()
+	for model, dimensions := range config.Get().RAGEmbeddingModels {
+		embeddingModelDimensions[openai.EmbeddingModel(model)] = dimensions
+	}
+})
+
 // Update model dimensions mapping to include commonly used compatible models
 var embeddingModelDimensions = map[openai.EmbeddingModel]uint64{
 	openai.AdaEmbeddingV2:  1536,
@@ -27,40 +82,138 @@ var embeddingModelDimensions = map[openai.EmbeddingModel]uint64{
 	"codesmart.embedding":  1536, // CodeSmart embedding model
 }
 
+// qdrantError wraps a Qdrant gRPC error, deriving the error code from the gRPC status
+func qdrantError(err error, message string) error {
+	code := util.ErrCodeUpstream
+	switch status.Code(err) {
+	case codes.NotFound:
+		code = util.ErrCodeNotFound
+	case codes.InvalidArgument, codes.AlreadyExists:
+		code = util.ErrCodeInvalidArgument
+	case codes.Unauthenticated, codes.PermissionDenied:
+		code = util.ErrCodeUnauthorized
+	case codes.ResourceExhausted:
+		code = util.ErrCodeRateLimited
+	}
+	return util.WrapToolError(code, err, message)
+}
+
+// openAIError wraps an OpenAI API error, deriving the error code from the HTTP status
+func openAIError(err error, message string) error {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return util.UpstreamError(err, apiErr.HTTPStatusCode, message)
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return util.UpstreamError(err, reqErr.HTTPStatusCode, message)
+	}
+	return util.WrapToolError(util.ErrCodeUpstream, err, message)
+}
+
 // Update validation function to work with EmbeddingModel
 func validateEmbeddingModel(modelStr string) (openai.EmbeddingModel, uint64, error) {
 	model := openai.EmbeddingModel(modelStr)
-	if dimensions, ok := embeddingModelDimensions[model]; ok {
+	if dimensions, ok := embeddingModelDimension(model); ok {
 		return model, dimensions, nil
 	}
-	return "", 0, fmt.Errorf("unsupported embedding model: %s. Supported models: %s",
+	return "", 0, util.NewToolError(util.ErrCodeInvalidArgument, "unsupported embedding model: %s. Supported models: %s",
 		modelStr,
-		"text-embedding-ada-002, text-embedding-3-small, text-embedding-3-large, baai/bge-base-en, baai/bge-large-en, codesmart.embedding")
+		strings.Join(supportedEmbeddingModels(), ", "))
 }
 
-var qdrantClient = sync.OnceValue(func() *qdrant.Client {
+// supportedEmbeddingModels returns the names of the embedding models in
+// embeddingModelDimensions, sorted
+func supportedEmbeddingModels() []string {
+	loadConfiguredEmbeddingModels()
+	embeddingModelsMu.RLock()
+	defer embeddingModelsMu.RUnlock()
 
-	host := os.Getenv("QDRANT_HOST")
-	port := os.Getenv("QDRANT_PORT")
-	apiKey := os.Getenv("QDRANT_API_KEY")
-	if host == "" || port == "" || apiKey == "" {
-		panic("QDRANT_HOST, QDRANT_PORT, or QDRANT_API_KEY is not set, please set it in MCP Config")
+	models := make([]string, 0, len(embeddingModelDimensions))
+	for model := range embeddingModelDimensions {
+		models = append(models, string(model))
 	}
+	sort.Strings(models)
+	return models
+}
+
+// embeddingModelDimension returns the vector size of a supported embedding model
+func embeddingModelDimension(model openai.EmbeddingModel) (uint64, bool) {
+	loadConfiguredEmbeddingModels()
+	embeddingModelsMu.RLock()
+	defer embeddingModelsMu.RUnlock()
+
+	dimensions, ok := embeddingModelDimensions[model]
+	return dimensions, ok
+}
+
+// resolveEmbeddingModel returns the embedding model named by the model argument,
+// or defaultEmbeddingModel, and its vector size. A dimensions argument registers a
+// model that is not supported yet, and must match the size of one that is.
+func resolveEmbeddingModel(arguments map[string]interface{}) (string, uint64, error) {
+	modelStr := defaultEmbeddingModel()
+	if modelArg, ok := arguments["model"].(string); ok && modelArg != "" {
+		modelStr = modelArg
+	}
+
+	if value, ok := arguments["dimensions"].(float64); ok {
+		if value <= 0 || value != math.Trunc(value) {
+			return "", 0, util.NewToolError(util.ErrCodeInvalidArgument, "dimensions must be a positive integer, got %v", value)
+		}
+		dimensions := uint64(value)
+
+		loadConfiguredEmbeddingModels()
+		embeddingModelsMu.Lock()
+		known, ok := embeddingModelDimensions[openai.EmbeddingModel(modelStr)]
+		if !ok {
+			embeddingModelDimensions[openai.EmbeddingModel(modelStr)] = dimensions
+		}
+		embeddingModelsMu.Unlock()
 
-	portInt, err := strconv.Atoi(port)
+		if ok && known != dimensions {
+			return "", 0, util.NewToolError(util.ErrCodeInvalidArgument, "embedding model %s has %d dimensions, not %d", modelStr, known, dimensions)
+		}
+	}
+
+	model, dimensions, err := validateEmbeddingModel(modelStr)
 	if err != nil {
-		panic(fmt.Sprintf("failed to parse QDRANT_PORT: %v", err))
+		return "", 0, err
+	}
+	return string(model), dimensions, nil
+}
+
+func listModelsHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	var result strings.Builder
+	result.WriteString("Supported embedding models:\n")
+	for _, model := range supportedEmbeddingModels() {
+		dimensions, _ := embeddingModelDimension(openai.EmbeddingModel(model))
+		result.WriteString(fmt.Sprintf("- %s: %d dimensions", model, dimensions))
+		if model == defaultEmbeddingModel() {
+			result.WriteString(" (default)")
+		}
+		result.WriteString("\n")
 	}
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+var qdrantClient = sync.OnceValue(func() *qdrant.Client {
 
-	if apiKey == "" {
-		panic("QDRANT_API_KEY is not set")
+	cfg := config.Get()
+	host := cfg.QdrantHost
+	port := cfg.QdrantPort
+	apiKey := cfg.QdrantAPIKey
+	if host == "" || port == 0 || apiKey == "" {
+		panic("QDRANT_HOST, QDRANT_PORT, or QDRANT_API_KEY is not set, please set it in MCP Config")
 	}
 
 	client, err := qdrant.NewClient(&qdrant.Config{
 		Host:   host,
-		Port:   portInt,
+		Port:   port,
 		APIKey: apiKey,
 		UseTLS: true,
+		GrpcOptions: []grpc.DialOption{
+			grpc.WithChainUnaryInterceptor(services.RateLimitUnaryInterceptor("qdrant")),
+		},
 	})
 
 	if err != nil {
@@ -73,27 +226,52 @@ var qdrantClient = sync.OnceValue(func() *qdrant.Client {
 func RegisterRagTools(s *server.MCPServer) {
 	indexContentTool := mcp.NewTool("RAG_memory_index_content",
 		mcp.WithDescription("Index a content into memory, can be inserted or updated"),
-		mcp.WithString("collection", mcp.Required(), mcp.Description("Memory collection name")),
+		withCollection("Memory collection name"),
 		mcp.WithString("filePath", mcp.Required(), mcp.Description("content file path")),
 		mcp.WithString("payload", mcp.Required(), mcp.Description("Plain text payload")),
-		mcp.WithString("model", mcp.Description("Embedding model to use (default: text-embedding-3-large)")),
+		mcp.WithString("model", mcp.Description("Embedding model to use (default: "+defaultEmbeddingModel()+")")),
+		mcp.WithNumber("dimensions", mcp.Description("Vector size of the model; registers a model that is not in the supported list (see RAG_memory_list_models)")),
+		mcp.WithString("vector_name", mcp.Description("Named vector of the collection to store the embeddings in, for collections created with vector_name")),
+		mcp.WithBoolean("continue_on_error", mcp.Description("Index the chunks that embed successfully and report the failed ones instead of failing the whole call")),
+		withContextualize(),
+		util.WithDryRun(),
 	)
 
 	indexFileTool := mcp.NewTool("RAG_memory_index_file",
 		mcp.WithDescription("Index a local file into memory"),
-		mcp.WithString("collection", mcp.Required(), mcp.Description("Memory collection name")),
+		withCollection("Memory collection name"),
 		mcp.WithString("filePath", mcp.Required(), mcp.Description("Path to the local file to be indexed")),
+		mcp.WithBoolean("force", mcp.Description("Re-index the file even if its content is unchanged since the last run")),
+		withContextualize(),
+		util.WithDryRun(),
+	)
+
+	indexDirectoryTool := mcp.NewTool("RAG_memory_index_directory",
+		mcp.WithDescription("Index all text files in a local directory into memory"),
+		withCollection("Memory collection name"),
+		mcp.WithString("path", mcp.Required(), mcp.Description("Path to the directory to index")),
+		mcp.WithString("extensions", mcp.Description("Comma separated file extensions to index, e.g. .md,.go (default: RAG_INDEX_EXTENSIONS, or all text files)")),
+		mcp.WithString("include", mcp.Description("Comma separated glob patterns matched against the file name or path relative to the directory; only matching files are indexed")),
+		mcp.WithString("exclude", mcp.Description("Comma separated glob patterns for files and directories to skip, e.g. vendor,*_test.go")),
+		mcp.WithBoolean("force", mcp.Description("Re-index files even if their content is unchanged since the last run")),
+		mcp.WithBoolean("continue_on_error", mcp.Description("Index the chunks of a file that embed successfully and report the failed ones instead of failing the whole file")),
+		withContextualize(),
+		util.WithDryRun(),
 	)
 
 	createCollectionTool := mcp.NewTool("RAG_memory_create_collection",
 		mcp.WithDescription("Create a new vector collection in memory"),
-		mcp.WithString("collection", mcp.Required(), mcp.Description("Memory collection name")),
-		mcp.WithString("model", mcp.Description("Embedding model to use (default: text-embedding-3-large)")),
+		withCollection("Memory collection name"),
+		mcp.WithString("model", mcp.Description("Embedding model to use (default: "+defaultEmbeddingModel()+")")),
+		mcp.WithNumber("dimensions", mcp.Description("Vector size of the model; registers a model that is not in the supported list (see RAG_memory_list_models)")),
+		mcp.WithString("vector_name", mcp.Description("Create named vectors instead of a single vector: a name sized for model, or comma separated name=model pairs to hold several models, e.g. fast=text-embedding-3-small,precise=text-embedding-3-large")),
+		util.WithDryRun(),
 	)
 
 	deleteCollectionTool := mcp.NewTool("RAG_memory_delete_collection",
 		mcp.WithDescription("Delete a vector collection in memory"),
 		mcp.WithString("collection", mcp.Required(), mcp.Description("Memory collection name")),
+		util.WithDryRun(),
 	)
 
 	listCollectionTool := mcp.NewTool("RAG_memory_list_collections",
@@ -102,28 +280,334 @@ func RegisterRagTools(s *server.MCPServer) {
 
 	searchTool := mcp.NewTool("RAG_memory_search",
 		mcp.WithDescription("Search for memory in a collection based on a query"),
-		mcp.WithString("collection", mcp.Required(), mcp.Description("Memory collection name")),
+		withCollection("Memory collection name"),
 		mcp.WithString("query", mcp.Required(), mcp.Description("search query, should be a keyword")),
-		mcp.WithString("model", mcp.Description("Embedding model to use (default: text-embedding-3-large)")),
+		mcp.WithString("model", mcp.Description("Embedding model to use (default: "+defaultEmbeddingModel()+")")),
+		mcp.WithString("vector_name", mcp.Description("Named vector to search, for collections created with vector_name; use the model it was indexed with")),
 	)
 
 	deleteIndexByFilePathTool := mcp.NewTool("RAG_memory_delete_index_by_filepath",
 		mcp.WithDescription("Delete a vector index by filePath"),
-		mcp.WithString("collection", mcp.Required(), mcp.Description("Memory collection name")),
+		withCollection("Memory collection name"),
 		mcp.WithString("filePath", mcp.Required(), mcp.Description("Path to the local file to be deleted")),
+		util.WithDryRun(),
 	)
 
-	s.AddTool(createCollectionTool, util.ErrorGuard(util.AdaptLegacyHandler(createCollectionHandler)))
+	s.AddTool(createCollectionTool, util.ErrorGuard(util.DryRunGuard("create collection", "PUT /collections/{collection}", util.AdaptLegacyHandler(createCollectionHandler))))
 	s.AddTool(deleteCollectionTool, util.ErrorGuard(util.AdaptLegacyHandler(deleteCollectionHandler)))
 	s.AddTool(listCollectionTool, util.ErrorGuard(util.AdaptLegacyHandler(listCollectionHandler)))
-	s.AddTool(indexContentTool, util.ErrorGuard(util.AdaptLegacyHandler(indexContentHandler)))
+	s.AddTool(indexContentTool, util.ErrorGuard(util.DryRunGuard("index content", "PUT /collections/{collection}/points", util.AdaptLegacyHandler(indexContentHandler))))
 	s.AddTool(searchTool, util.ErrorGuard(util.AdaptLegacyHandler(vectorSearchHandler)))
-	s.AddTool(indexFileTool, util.ErrorGuard(util.AdaptLegacyHandler(indexFileHandler)))
+	s.AddTool(indexFileTool, util.ErrorGuard(util.DryRunGuard("index file", "PUT /collections/{collection}/points", util.AdaptLegacyHandler(indexFileHandler))))
+	s.AddTool(indexDirectoryTool, util.ErrorGuard(util.DryRunGuard("index directory", "PUT /collections/{collection}/points", indexDirectoryHandler)))
 	s.AddTool(deleteIndexByFilePathTool, util.ErrorGuard(util.AdaptLegacyHandler(deleteIndexByFilePathHandler)))
+
+	deleteByFilterTool := mcp.NewTool("RAG_memory_delete_by_filter",
+		mcp.WithDescription("Delete the points of a collection whose payload matches a filter, e.g. a model, a metadata tag or a chunkIndex range"),
+		withCollection("Memory collection name"),
+		mcp.WithString("filter", mcp.Required(), mcp.Description(`JSON object of payload conditions that must all match, e.g. {"model": "text-embedding-3-large", "chunkIndex": {"gte": 10}}. Values match exactly; arrays match any element; objects with gt/gte/lt/lte match a numeric range`)),
+		util.WithDryRun(),
+	)
+	s.AddTool(deleteByFilterTool, util.ErrorGuard(util.AdaptLegacyHandler(deleteByFilterHandler)))
+
+	copyCollectionTool := mcp.NewTool("RAG_memory_copy_collection",
+		mcp.WithDescription("Copy all points of a collection into a new collection with the same vector configuration. Set delete_source to rename the collection"),
+		mcp.WithString("source", mcp.Required(), mcp.Description("Collection to copy from")),
+		mcp.WithString("target", mcp.Required(), mcp.Description("New collection to create; must not exist")),
+		mcp.WithBoolean("delete_source", mcp.Description("Delete the source collection after a successful copy")),
+		mcp.WithNumber("batch_size", mcp.Description("Number of points copied per batch (default: 256)")),
+		util.WithDryRun(),
+	)
+	s.AddTool(copyCollectionTool, util.ErrorGuard(copyCollectionHandler))
+
+	listModelsTool := mcp.NewTool("RAG_memory_list_models",
+		mcp.WithDescription("List the supported embedding models with their vector dimensions, marking the default"),
+	)
+	s.AddTool(listModelsTool, util.ErrorGuard(util.AdaptLegacyHandler(listModelsHandler)))
+}
+
+// payloadFilter builds a Qdrant filter requiring every condition to match. String,
+// integer and boolean values match exactly, arrays match any of their values and
+// objects with gt/gte/lt/lte keys match a numeric range.
+func payloadFilter(conditions map[string]interface{}) (*qdrant.Filter, error) {
+	keys := make([]string, 0, len(conditions))
+	for key := range conditions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	filter := &qdrant.Filter{}
+	for _, key := range keys {
+		switch value := conditions[key].(type) {
+		case string:
+			filter.Must = append(filter.Must, qdrant.NewMatch(key, value))
+		case bool:
+			filter.Must = append(filter.Must, qdrant.NewMatchBool(key, value))
+		case float64:
+			if value != math.Trunc(value) {
+				return nil, fmt.Errorf("condition %s: only integer values can be matched exactly, use a range", key)
+			}
+			filter.Must = append(filter.Must, qdrant.NewMatchInt(key, int64(value)))
+		case []interface{}:
+			condition, err := matchAnyCondition(key, value)
+			if err != nil {
+				return nil, err
+			}
+			filter.Must = append(filter.Must, condition)
+		case map[string]interface{}:
+			rangeVal := &qdrant.Range{}
+			for op, bound := range value {
+				number, ok := bound.(float64)
+				if !ok {
+					return nil, fmt.Errorf("condition %s: range bound %s must be a number", key, op)
+				}
+				switch op {
+				case "gt":
+					rangeVal.Gt = &number
+				case "gte":
+					rangeVal.Gte = &number
+				case "lt":
+					rangeVal.Lt = &number
+				case "lte":
+					rangeVal.Lte = &number
+				default:
+					return nil, fmt.Errorf("condition %s: unknown range operator %s, use gt, gte, lt or lte", key, op)
+				}
+			}
+			filter.Must = append(filter.Must, qdrant.NewRange(key, rangeVal))
+		default:
+			return nil, fmt.Errorf("condition %s: unsupported value %v", key, value)
+		}
+	}
+
+	if len(filter.Must) == 0 {
+		return nil, fmt.Errorf("filter must contain at least one condition")
+	}
+	return filter, nil
+}
+
+// matchAnyCondition matches a payload field against any of a list of strings or integers
+func matchAnyCondition(key string, values []interface{}) (*qdrant.Condition, error) {
+	var keywords []string
+	var ints []int64
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			keywords = append(keywords, v)
+		case float64:
+			ints = append(ints, int64(v))
+		default:
+			return nil, fmt.Errorf("condition %s: list values must be strings or integers", key)
+		}
+	}
+
+	switch {
+	case len(keywords) > 0 && len(ints) > 0:
+		return nil, fmt.Errorf("condition %s: list values must all be strings or all be integers", key)
+	case len(keywords) > 0:
+		return qdrant.NewMatchKeywords(key, keywords...), nil
+	case len(ints) > 0:
+		return qdrant.NewMatchInts(key, ints...), nil
+	default:
+		return nil, fmt.Errorf("condition %s: list must not be empty", key)
+	}
+}
+
+func copyCollectionHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	source := arguments["source"].(string)
+	target := arguments["target"].(string)
+	deleteSource, _ := arguments["delete_source"].(bool)
+
+	batchSize := uint32(256)
+	if value, ok := arguments["batch_size"].(float64); ok && value > 0 {
+		batchSize = uint32(value)
+	}
+
+	if source == target {
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "source and target must be different collections")
+	}
+
+	sourceInfo, err := qdrantClient().GetCollectionInfo(ctx, source)
+	if err != nil {
+		return nil, qdrantError(err, fmt.Sprintf("failed to get collection %s", source))
+	}
+
+	exists, err := qdrantClient().CollectionExists(ctx, target)
+	if err != nil {
+		return nil, qdrantError(err, fmt.Sprintf("failed to check collection %s", target))
+	}
+	if exists {
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "collection %s already exists", target)
+	}
+
+	if util.IsDryRun(arguments) {
+		return util.DryRunResult("copy collection", fmt.Sprintf("PUT /collections/%s", target), map[string]interface{}{
+			"source":        source,
+			"target":        target,
+			"points_count":  sourceInfo.GetPointsCount(),
+			"delete_source": deleteSource,
+		}), nil
+	}
+
+	params := sourceInfo.GetConfig().GetParams()
+	err = qdrantClient().CreateCollection(ctx, &qdrant.CreateCollection{
+		CollectionName:      target,
+		VectorsConfig:       params.GetVectorsConfig(),
+		SparseVectorsConfig: params.GetSparseVectorsConfig(),
+		OnDiskPayload:       &params.OnDiskPayload,
+	})
+	if err != nil {
+		return nil, qdrantError(err, fmt.Sprintf("failed to create collection %s", target))
+	}
+
+	copied := 0
+	var offset *qdrant.PointId
+	waitUpsert := true
+	for {
+		resp, err := qdrantClient().GetPointsClient().Scroll(ctx, &qdrant.ScrollPoints{
+			CollectionName: source,
+			Offset:         offset,
+			Limit:          &batchSize,
+			WithPayload:    qdrant.NewWithPayload(true),
+			WithVectors:    qdrant.NewWithVectors(true),
+		})
+		if err != nil {
+			return nil, qdrantError(err, fmt.Sprintf("failed to read points from %s after copying %d", source, copied))
+		}
+
+		points := make([]*qdrant.PointStruct, 0, len(resp.GetResult()))
+		for _, point := range resp.GetResult() {
+			points = append(points, &qdrant.PointStruct{
+				Id:      point.GetId(),
+				Payload: point.GetPayload(),
+				Vectors: vectorsFromOutput(point.GetVectors()),
+			})
+		}
+
+		if len(points) > 0 {
+			_, err = qdrantClient().Upsert(ctx, &qdrant.UpsertPoints{
+				CollectionName: target,
+				Wait:           &waitUpsert,
+				Points:         points,
+			})
+			if err != nil {
+				return nil, qdrantError(err, fmt.Sprintf("failed to write points to %s after copying %d", target, copied))
+			}
+			copied += len(points)
+		}
+
+		offset = resp.GetNextPageOffset()
+		if offset == nil {
+			break
+		}
+	}
+
+	result := fmt.Sprintf("Successfully copied %d points from %s to %s", copied, source, target)
+
+	if deleteSource {
+		if err := qdrantClient().DeleteCollection(ctx, source); err != nil {
+			return nil, qdrantError(err, fmt.Sprintf("copied %d points to %s but failed to delete %s", copied, target, source))
+		}
+		result += fmt.Sprintf("\nDeleted source collection %s", source)
+	}
+
+	return mcp.NewToolResultText(result), nil
+}
+
+// vectorsFromOutput converts the vectors returned by a read into the form
+// accepted by an upsert
+func vectorsFromOutput(output *qdrant.VectorsOutput) *qdrant.Vectors {
+	if named := output.GetVectors(); named != nil {
+		vectors := make(map[string]*qdrant.Vector, len(named.GetVectors()))
+		for name, vector := range named.GetVectors() {
+			vectors[name] = vectorFromOutput(vector)
+		}
+		return qdrant.NewVectorsMap(vectors)
+	}
+	return &qdrant.Vectors{
+		VectorsOptions: &qdrant.Vectors_Vector{Vector: vectorFromOutput(output.GetVector())},
+	}
+}
+
+func vectorFromOutput(output *qdrant.VectorOutput) *qdrant.Vector {
+	switch {
+	case output.GetDense() != nil:
+		return qdrant.NewVectorDense(output.GetDense().GetData())
+	case output.GetSparse() != nil:
+		return qdrant.NewVectorSparse(output.GetSparse().GetIndices(), output.GetSparse().GetValues())
+	case output.GetMultiDense() != nil:
+		multi := make([][]float32, 0, len(output.GetMultiDense().GetVectors()))
+		for _, vector := range output.GetMultiDense().GetVectors() {
+			multi = append(multi, vector.GetData())
+		}
+		return qdrant.NewVectorMulti(multi)
+	case output.GetIndices() != nil:
+		// Servers before 1.12 only fill the deprecated fields
+		return qdrant.NewVectorSparse(output.GetIndices().GetData(), output.GetData())
+	default:
+		return qdrant.NewVectorDense(output.GetData())
+	}
+}
+
+func deleteByFilterHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	collection, err := collectionArgument(arguments)
+	if err != nil {
+		return nil, err
+	}
+	filterStr := arguments["filter"].(string)
+	ctx := context.Background()
+
+	var conditions map[string]interface{}
+	if err := json.Unmarshal([]byte(filterStr), &conditions); err != nil {
+		return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "filter must be a JSON object")
+	}
+
+	filter, err := payloadFilter(conditions)
+	if err != nil {
+		return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "invalid filter")
+	}
+
+	exact := true
+	count, err := qdrantClient().Count(ctx, &qdrant.CountPoints{
+		CollectionName: collection,
+		Filter:         filter,
+		Exact:          &exact,
+	})
+	if err != nil {
+		return nil, qdrantError(err, "failed to count matching points")
+	}
+
+	if util.IsDryRun(arguments) {
+		return util.DryRunResult("delete points by filter", fmt.Sprintf("POST /collections/%s/points/delete", collection), map[string]interface{}{
+			"filter":       conditions,
+			"points_count": count,
+		}), nil
+	}
+
+	if count == 0 {
+		return mcp.NewToolResultText("No points matched the filter, nothing deleted"), nil
+	}
+
+	waitDelete := true
+	deleteResp, err := qdrantClient().Delete(ctx, &qdrant.DeletePoints{
+		CollectionName: collection,
+		Wait:           &waitDelete,
+		Points:         qdrant.NewPointsSelectorFilter(filter),
+	})
+	if err != nil {
+		return nil, qdrantError(err, "failed to delete points")
+	}
+
+	result := fmt.Sprintf("Successfully deleted %d points from collection %s\nOperation ID: %d\nStatus: %s", count, collection, deleteResp.OperationId, deleteResp.Status)
+	return mcp.NewToolResultText(result), nil
 }
 
 func deleteIndexByFilePathHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	collection := arguments["collection"].(string)
+	collection, err := collectionArgument(arguments)
+	if err != nil {
+		return nil, err
+	}
 	filePath := arguments["filePath"].(string)
 	ctx := context.Background()
 
@@ -149,12 +633,26 @@ func deleteIndexByFilePathHandler(arguments map[string]interface{}) (*mcp.CallTo
 		},
 	}
 
+	if util.IsDryRun(arguments) {
+		count, err := qdrantClient().Count(ctx, &qdrant.CountPoints{
+			CollectionName: collection,
+			Filter:         pointsSelector.GetFilter(),
+		})
+		if err != nil {
+			return nil, qdrantError(err, fmt.Sprintf("failed to count points for filePath %s", filePath))
+		}
+		return util.DryRunResult("delete points by filePath", fmt.Sprintf("POST /collections/%s/points/delete", collection), map[string]interface{}{
+			"filePath":     filePath,
+			"points_count": count,
+		}), nil
+	}
+
 	deleteResp, err := qdrantClient().Delete(ctx, &qdrant.DeletePoints{
 		CollectionName: collection,
 		Points:         pointsSelector,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to delete points for filePath %s: %v", filePath, err)
+		return nil, qdrantError(err, fmt.Sprintf("failed to delete points for filePath %s", filePath))
 	}
 
 	result := fmt.Sprintf("Successfully deleted points for filePath: %s\nOperation ID: %d\nStatus: %s", filePath, deleteResp.OperationId, deleteResp.Status)
@@ -162,46 +660,294 @@ func deleteIndexByFilePathHandler(arguments map[string]interface{}) (*mcp.CallTo
 }
 
 func indexFileHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	collection := arguments["collection"].(string)
+	collection, err := collectionArgument(arguments)
+	if err != nil {
+		return nil, err
+	}
 	filePath := arguments["filePath"].(string)
+	force, _ := arguments["force"].(bool)
+
+	report, err := indexFile(context.Background(), collection, filePath, defaultEmbeddingModel(), force, indexOptions{
+		contextualize: shouldContextualize(arguments),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if report.chunks == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("Skipped %s: content unchanged since last index (use force to re-index)\nRe-indexed: 0, Skipped: 1", filePath)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Indexed %s (%d chunks)\nRe-indexed: 1, Skipped: 0", filePath, report.chunks)), nil
+}
+
+func indexDirectoryHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+
+	collection, err := collectionArgument(arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	root, ok := arguments["path"].(string)
+	if !ok || root == "" {
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "path argument is required")
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "failed to read directory")
+	}
+	if !info.IsDir() {
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "%s is not a directory", root)
+	}
+
+	extensions := config.Get().RAGIndexExtensions
+	if extArg, ok := arguments["extensions"].(string); ok && extArg != "" {
+		extensions = strings.Split(extArg, ",")
+	}
+	includes := splitPatterns(arguments["include"])
+	excludes := splitPatterns(arguments["exclude"])
+	force, _ := arguments["force"].(bool)
+	continueOnError, _ := arguments["continue_on_error"].(bool)
+	opts := indexOptions{
+		partial:       continueOnError,
+		contextualize: shouldContextualize(arguments),
+	}
+
+	var files []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, _ := filepath.Rel(root, path)
+
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || matchesAnyPattern(excludes, relPath)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !d.Type().IsRegular() || matchesAnyPattern(excludes, relPath) {
+			return nil
+		}
+		if len(includes) > 0 && !matchesAnyPattern(includes, relPath) {
+			return nil
+		}
+		if !hasIndexedExtension(path, extensions) {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory %s: %w", root, err)
+	}
+
+	var result strings.Builder
+	var indexed, skipped, partial, failed int
+	for _, file := range files {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		binary, err := isBinaryFile(file)
+		if err != nil {
+			failed++
+			result.WriteString(fmt.Sprintf("%s: error: %v\n", file, err))
+			continue
+		}
+		if binary {
+			continue
+		}
+
+		report, err := indexFile(ctx, collection, file, defaultEmbeddingModel(), force, opts)
+		switch {
+		case err != nil:
+			failed++
+			result.WriteString(fmt.Sprintf("%s: error: %v\n", file, err))
+		case report.chunks == 0:
+			skipped++
+			result.WriteString(fmt.Sprintf("%s: unchanged, skipped\n", file))
+		case len(report.failedChunks) > 0:
+			indexed++
+			partial++
+			result.WriteString(fmt.Sprintf("%s: %d chunks, %d failed\n", file, report.chunks, len(report.failedChunks)))
+			for _, failure := range report.failedChunks {
+				result.WriteString(fmt.Sprintf("  %s\n", failure))
+			}
+		default:
+			indexed++
+			result.WriteString(fmt.Sprintf("%s: %d chunks\n", file, report.chunks))
+		}
+	}
+
+	result.WriteString(fmt.Sprintf("\nIndexed: %d, Skipped: %d, Partial: %d, Errors: %d", indexed, skipped, partial, failed))
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// splitPatterns splits a comma separated list of glob patterns
+func splitPatterns(value interface{}) []string {
+	str, _ := value.(string)
+	var patterns []string
+	for _, pattern := range strings.Split(str, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// matchesAnyPattern reports whether the file name or relative path matches one of the patterns
+func matchesAnyPattern(patterns []string, relPath string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, filepath.Base(relPath)); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, filepath.ToSlash(relPath)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasIndexedExtension reports whether path has one of the extensions; an empty
+// list accepts every file
+func hasIndexedExtension(path string, extensions []string) bool {
+	if len(extensions) == 0 {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, allowed := range extensions {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if !strings.HasPrefix(allowed, ".") {
+			allowed = "." + allowed
+		}
+		if ext == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// isBinaryFile reports whether the start of the file contains a NUL byte
+func isBinaryFile(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	buf := make([]byte, 8000)
+	n, err := file.Read(buf)
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	return bytes.IndexByte(buf[:n], 0) >= 0, nil
+}
 
+// indexFile indexes a local file and returns the number of chunks created. Files
+// whose content hash matches the one stored at the last index are skipped and
+// report 0 chunks, unless force is set.
+func indexFile(ctx context.Context, collection, filePath, modelStr string, force bool, opts indexOptions) (*upsertReport, error) {
 	// Read the file content
 	content, err := os.ReadFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %v", err)
+		return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "failed to read file")
 	}
 
-	// Prepare arguments for vectorUpsertHandler
-	upsertArgs := map[string]interface{}{
-		"collection": collection,
-		"filePath":   filePath,
-		"payload":    string(content), // Convert content to string
+	hash := sha256.Sum256(content)
+	contentHash := hex.EncodeToString(hash[:])
+
+	if !force && storedContentHash(ctx, collection, filePath) == contentHash {
+		return &upsertReport{}, nil
+	}
+
+	report, err := upsertContent(ctx, collection, filePath, string(content), modelStr, map[string]any{
+		"contentHash": contentHash,
+	}, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	// Call vectorUpsertHandler
-	return indexContentHandler(upsertArgs)
+	// Drop the hash of a partially indexed file so the next run indexes it again
+	if len(report.failedChunks) > 0 {
+		waitDelete := true
+		_, err := qdrantClient().DeletePayload(ctx, &qdrant.DeletePayloadPoints{
+			CollectionName: collection,
+			Wait:           &waitDelete,
+			Keys:           []string{"contentHash"},
+			PointsSelector: qdrant.NewPointsSelector(chunkPointID(filePath, 0)),
+		})
+		if err != nil {
+			return nil, qdrantError(err, "failed to clear content hash of partially indexed file")
+		}
+	}
+	return report, nil
+}
+
+// storedContentHash returns the content hash recorded on the first chunk of an
+// indexed file, or "" when the file has not been indexed with a hash
+func storedContentHash(ctx context.Context, collection, filePath string) string {
+	points, err := qdrantClient().Get(ctx, &qdrant.GetPoints{
+		CollectionName: collection,
+		Ids:            []*qdrant.PointId{chunkPointID(filePath, 0)},
+		WithPayload:    qdrant.NewWithPayload(true),
+	})
+	if err != nil || len(points) == 0 {
+		return ""
+	}
+	return points[0].Payload["contentHash"].GetStringValue()
+}
+
+// parseNamedVectors parses the vector_name argument of a new collection: either
+// names that all use defaultModel, or comma separated name=model pairs
+func parseNamedVectors(spec, defaultModel string) (map[string]string, error) {
+	vectors := make(map[string]string)
+	for _, entry := range strings.Split(spec, ",") {
+		name, model, hasModel := strings.Cut(strings.TrimSpace(entry), "=")
+		name, model = strings.TrimSpace(name), strings.TrimSpace(model)
+		if !hasModel {
+			model = defaultModel
+		}
+		if name == "" || model == "" {
+			return nil, util.NewToolError(util.ErrCodeInvalidArgument, "invalid vector_name entry %q: expected name or name=model", entry)
+		}
+		if _, exists := vectors[name]; exists {
+			return nil, util.NewToolError(util.ErrCodeInvalidArgument, "vector %s is listed twice", name)
+		}
+		if _, _, err := validateEmbeddingModel(model); err != nil {
+			return nil, err
+		}
+		vectors[name] = model
+	}
+	return vectors, nil
+}
+
+// chunkPointID derives a stable point ID from the file path and chunk index so
+// re-indexing overwrites earlier chunks
+func chunkPointID(filePath string, chunkIndex int) *qdrant.PointId {
+	return qdrant.NewIDUUID(uuid.NewSHA1(uuid.NameSpaceURL, []byte(filePath+strconv.Itoa(chunkIndex))).String())
 }
 
 func listCollectionHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
 	ctx := context.Background()
 	collections, err := qdrantClient().ListCollections(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list collections: %w", err)
+		return nil, qdrantError(err, "failed to list collections")
 	}
 	return mcp.NewToolResultText(fmt.Sprintf("Collections: %v", collections)), nil
 }
 
-// Update createCollectionHandler to always use codesmart.embedding
+// createCollectionHandler creates a collection sized for the given embedding model, defaulting to defaultEmbeddingModel
 func createCollectionHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	collection := arguments["collection"].(string)
-	modelStr := "codesmart.embedding" // Always use codesmart.embedding as default
-
-	if modelArg, ok := arguments["model"].(string); ok && modelArg != "" {
-		embModel, _, err := validateEmbeddingModel(modelArg)
-		if err != nil {
-			return nil, err
-		}
-		modelStr = string(embModel)
+	collection, err := collectionArgument(arguments)
+	if err != nil {
+		return nil, err
+	}
+	modelStr, dimensions, err := resolveEmbeddingModel(arguments)
+	if err != nil {
+		return nil, err
 	}
 
 	ctx := context.Background()
@@ -209,30 +955,52 @@ func createCollectionHandler(arguments map[string]interface{}) (*mcp.CallToolRes
 	// Check if collection already exists
 	collectionInfo, err := qdrantClient().GetCollectionInfo(ctx, collection)
 	if err == nil && collectionInfo != nil {
-		return nil, fmt.Errorf("collection %s already exists", collection)
+		return nil, util.NewToolError(util.ErrCodeInvalidArgument, "collection %s already exists", collection)
 	}
 
-	// Get dimensions for the model
-	dimensions := embeddingModelDimensions[openai.EmbeddingModel(modelStr)]
-
 	// Create collection with configuration for the selected model
+	vectorsConfig := qdrant.NewVectorsConfig(&qdrant.VectorParams{
+		Size:     dimensions,
+		Distance: qdrant.Distance_Cosine,
+	})
+	var namedVectors map[string]string
+	if spec, ok := arguments["vector_name"].(string); ok && strings.TrimSpace(spec) != "" {
+		if namedVectors, err = parseNamedVectors(spec, modelStr); err != nil {
+			return nil, err
+		}
+		params := make(map[string]*qdrant.VectorParams, len(namedVectors))
+		for name, model := range namedVectors {
+			_, size, _ := validateEmbeddingModel(model)
+			params[name] = &qdrant.VectorParams{Size: size, Distance: qdrant.Distance_Cosine}
+		}
+		vectorsConfig = qdrant.NewVectorsConfigMap(params)
+	}
+
 	err = qdrantClient().CreateCollection(ctx, &qdrant.CreateCollection{
 		CollectionName: collection,
-		VectorsConfig: &qdrant.VectorsConfig{
-			Config: &qdrant.VectorsConfig_Params{
-				Params: &qdrant.VectorParams{
-					Size:     dimensions,
-					Distance: qdrant.Distance_Cosine,
-				},
-			},
-		},
+		VectorsConfig:  vectorsConfig,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create collection: %v", err)
+		return nil, qdrantError(err, "failed to create collection")
 	}
 
-	result := fmt.Sprintf("Successfully created collection: %s with model: %s", collection, modelStr)
-	return mcp.NewToolResultText(result), nil
+	if len(namedVectors) == 0 {
+		result := fmt.Sprintf("Successfully created collection: %s with model: %s", collection, modelStr)
+		return mcp.NewToolResultText(result), nil
+	}
+
+	names := make([]string, 0, len(namedVectors))
+	for name := range namedVectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Successfully created collection: %s with named vectors:\n", collection))
+	for _, name := range names {
+		result.WriteString(fmt.Sprintf("- %s: %s\n", name, namedVectors[name]))
+	}
+	return mcp.NewToolResultText(result.String()), nil
 }
 
 func deleteCollectionHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
@@ -242,67 +1010,147 @@ func deleteCollectionHandler(arguments map[string]interface{}) (*mcp.CallToolRes
 	// Check if collection exists
 	collectionInfo, err := qdrantClient().GetCollectionInfo(ctx, collection)
 	if err != nil || collectionInfo == nil {
-		return nil, fmt.Errorf("collection %s does not exist", collection)
+		return nil, util.NewToolError(util.ErrCodeNotFound, "collection %s does not exist", collection)
+	}
+
+	if util.IsDryRun(arguments) {
+		return util.DryRunResult("delete collection", "DELETE /collections/"+collection, map[string]interface{}{
+			"collection":   collection,
+			"points_count": collectionInfo.GetPointsCount(),
+		}), nil
 	}
 
 	// Delete collection
 	err = qdrantClient().DeleteCollection(ctx, collection)
 	if err != nil {
-		return nil, fmt.Errorf("failed to delete collection: %v", err)
+		return nil, qdrantError(err, "failed to delete collection")
 	}
 
 	result := fmt.Sprintf("Successfully deleted collection: %s", collection)
 	return mcp.NewToolResultText(result), nil
 }
 
-// Update indexContentHandler to use codesmart.embedding by default
+// Update indexContentHandler to use the default embedding model
 func indexContentHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	collection := arguments["collection"].(string)
+	collection, err := collectionArgument(arguments)
+	if err != nil {
+		return nil, err
+	}
 	filePath := arguments["filePath"].(string)
 	payload := arguments["payload"].(string)
 
-	// Always default to codesmart.embedding
-	modelStr := "codesmart.embedding"
-	if modelArg, ok := arguments["model"].(string); ok && modelArg != "" {
-		embModel, _, err := validateEmbeddingModel(modelArg)
-		if err != nil {
-			return nil, err
-		}
-		modelStr = string(embModel)
+	modelStr, _, err := resolveEmbeddingModel(arguments)
+	if err != nil {
+		return nil, err
 	}
 
+	continueOnError, _ := arguments["continue_on_error"].(bool)
+
+	vectorName, _ := arguments["vector_name"].(string)
+
+	report, err := upsertContent(context.Background(), collection, filePath, payload, modelStr, nil, indexOptions{
+		partial:       continueOnError,
+		contextualize: shouldContextualize(arguments),
+		vectorName:    strings.TrimSpace(vectorName),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := fmt.Sprintf("Successfully upserted\nOperation ID: %d\nStatus: %s\nModel: %s", report.result.OperationId, report.result.Status, modelStr)
+	if len(report.failedChunks) > 0 {
+		result += fmt.Sprintf("\n\nIndexed %d chunks, %d failed:\n%s", report.chunks, len(report.failedChunks), strings.Join(report.failedChunks, "\n"))
+	}
+
+	return mcp.NewToolResultText(result), nil
+}
+
+// Embedding calls are retried with exponential backoff on rate limits and
+// transient upstream errors
+const (
+	embeddingAttempts   = 4
+	embeddingRetryDelay = 500 * time.Millisecond
+)
+
+// indexOptions controls how upsertContent chunks and embeds content
+type indexOptions struct {
+	partial       bool   // skip chunks that fail to embed instead of failing the upsert
+	contextualize bool   // prefix each chunk of a multi-chunk document with LLM-generated context
+	vectorName    string // store embeddings in this named vector instead of the default vector
+}
+
+// withContextualize adds the contextualize argument to an index tool definition
+func withContextualize() mcp.ToolOption {
+	return mcp.WithBoolean("contextualize", mcp.Description("Prefix each chunk of a multi-chunk document with LLM-generated context to improve retrieval. Disable for cheaper, faster indexing of raw chunks (default: true)"))
+}
+
+// shouldContextualize reads the contextualize argument, which defaults to true
+func shouldContextualize(arguments map[string]interface{}) bool {
+	contextualize, ok := arguments["contextualize"].(bool)
+	return !ok || contextualize
+}
+
+// upsertReport is the outcome of upsertContent
+type upsertReport struct {
+	result       *qdrant.UpdateResult
+	chunks       int      // number of chunks written
+	failedChunks []string // chunks skipped in partial mode, with their errors
+}
+
+// upsertContent chunks and embeds payload and upserts the chunks into collection,
+// keyed by filePath. Metadata is merged into every chunk's payload. In partial
+// mode a chunk that still fails to embed after retries is recorded in the report
+// and skipped instead of failing the whole upsert.
+func upsertContent(ctx context.Context, collection, filePath, payload, modelStr string, metadata map[string]any, opts indexOptions) (*upsertReport, error) {
 	// Split content into chunks
-	chunks, err := splitIntoChunks(payload, filePath) // Implement chunking logic
+	chunks, err := splitIntoChunks(payload, opts.contextualize)
 	if err != nil {
-		return nil, fmt.Errorf("failed to split into chunks: %v", err)
+		return nil, fmt.Errorf("failed to split into chunks: %w", err)
 	}
 
+	report := &upsertReport{}
 	var points []*qdrant.PointStruct
 	for i, chunk := range chunks {
 		// Generate embeddings for each chunk using selected model
-		resp, err := services.DefaultOpenAIClient().CreateEmbeddings(context.Background(), openai.EmbeddingRequest{
-			Input: []string{chunk},
-			Model: openai.EmbeddingModel(modelStr),
-		})
+		embedding, err := createEmbedding(ctx, modelStr, chunk)
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate embeddings: %v", err)
+			if !opts.partial || ctx.Err() != nil {
+				return nil, err
+			}
+			report.failedChunks = append(report.failedChunks, fmt.Sprintf("chunk %d: %v", i, err))
+			continue
 		}
 
-		// Create point for each chunk
+		pointPayload := map[string]any{
+			"filePath":   filePath,
+			"content":    chunk,
+			"chunkIndex": i,
+			"model":      modelStr, // Store the model used for embedding
+		}
+		for key, value := range metadata {
+			pointPayload[key] = value
+		}
+
+		// Create point for each chunk. Each named vector gets its own points, so
+		// indexing a file for one model leaves the embeddings of the others intact.
 		point := &qdrant.PointStruct{
-			Id:      qdrant.NewIDUUID(uuid.NewSHA1(uuid.NameSpaceURL, []byte(filePath+strconv.Itoa(i))).String()),
-			Vectors: qdrant.NewVectors(resp.Data[0].Embedding...),
-			Payload: qdrant.NewValueMap(map[string]any{
-				"filePath":   filePath,
-				"content":    chunk,
-				"chunkIndex": i,
-				"model":      modelStr, // Store the model used for embedding
-			}),
+			Id:      chunkPointID(filePath, i),
+			Vectors: qdrant.NewVectors(embedding...),
+			Payload: qdrant.NewValueMap(pointPayload),
+		}
+		if opts.vectorName != "" {
+			pointPayload["vector_name"] = opts.vectorName
+			point.Id = chunkPointID(opts.vectorName+":"+filePath, i)
+			point.Vectors = qdrant.NewVectorsMap(map[string]*qdrant.Vector{opts.vectorName: qdrant.NewVector(embedding...)})
+			point.Payload = qdrant.NewValueMap(pointPayload)
 		}
 		points = append(points, point)
 	}
 
-	ctx := context.Background()
+	if len(points) == 0 {
+		return nil, util.NewToolError(util.ErrCodeUpstream, "failed to embed any of %d chunks: %s", len(chunks), strings.Join(report.failedChunks, "; "))
+	}
+
 	waitUpsert := true
 
 	// Upsert all chunks
@@ -312,15 +1160,38 @@ func indexContentHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 		Points:         points,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to upsert points: %v", err)
+		return nil, qdrantError(err, "failed to upsert points")
 	}
 
-	result := fmt.Sprintf("Successfully upserted\nOperation ID: %d\nStatus: %s", upsertResp.OperationId, upsertResp.Status)
+	report.result = upsertResp
+	report.chunks = len(points)
+	return report, nil
+}
 
-	return mcp.NewToolResultText(result), nil
+// createEmbedding embeds text with the given model, retrying transient failures
+func createEmbedding(ctx context.Context, modelStr, text string) ([]float32, error) {
+	var embedding []float32
+	err := util.Retry(ctx, embeddingAttempts, embeddingRetryDelay, func() error {
+		resp, err := services.EmbeddingOpenAIClient().CreateEmbeddings(ctx, openai.EmbeddingRequest{
+			Input: []string{text},
+			Model: openai.EmbeddingModel(modelStr),
+		})
+		if err != nil {
+			return openAIError(err, "failed to generate embeddings")
+		}
+		if len(resp.Data) == 0 {
+			return util.NewToolError(util.ErrCodeUpstream, "no embedding returned")
+		}
+		embedding = resp.Data[0].Embedding
+		return nil
+	})
+	return embedding, err
 }
 
-func splitIntoChunks(content string, _ string) ([]string, error) {
+// splitIntoChunks splits content into overlapping token windows. When contextualize
+// is set and there is more than one chunk, each chunk is prefixed with context
+// generated by the chat model, which costs one LLM call per chunk.
+func splitIntoChunks(content string, contextualize bool) ([]string, error) {
 	const (
 		maxTokensPerChunk = 512
 		overlapTokens     = 50
@@ -361,7 +1232,7 @@ func splitIntoChunks(content string, _ string) ([]string, error) {
 	}
 
 	// If there's only one chunk, return it without context
-	if len(rawChunks) == 1 {
+	if len(rawChunks) == 1 || !contextualize {
 		return rawChunks, nil
 	}
 
@@ -369,7 +1240,7 @@ func splitIntoChunks(content string, _ string) ([]string, error) {
 	for _, chunkText := range rawChunks {
 		contextualizedChunk, err := generateContext(content, chunkText)
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate context: %v", err)
+			return nil, fmt.Errorf("failed to generate context: %w", err)
 		}
 		chunks = append(chunks, contextualizedChunk)
 	}
@@ -385,33 +1256,43 @@ Here is the chunk we want to situate within the whole document:
 Please give a short succinct context to situate this chunk within the overall document for the purposes of improving search retrieval of the chunk. Answer only with the succinct context and nothing else.
 	`, fullText, chunkText)
 
-	// Use codesmart model instead of GPT
-	model := "codesmart"
-
-	resp, err := services.DefaultOpenAIClient().CreateChatCompletion(
-		context.Background(),
-		openai.ChatCompletionRequest{
-			Model: model,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
+	model := config.Get().RAGContextModel
+
+	var resp openai.ChatCompletionResponse
+	err := util.Retry(context.Background(), embeddingAttempts, embeddingRetryDelay, func() error {
+		var err error
+		resp, err = services.DefaultOpenAIClient().CreateChatCompletion(
+			context.Background(),
+			openai.ChatCompletionRequest{
+				Model: model,
+				Messages: []openai.ChatCompletionMessage{
+					{
+						Role:    openai.ChatMessageRoleUser,
+						Content: prompt,
+					},
 				},
 			},
-		},
-	)
+		)
+		if err != nil {
+			return openAIError(err, "failed to generate context")
+		}
+		return nil
+	})
 
 	if err != nil {
-		return "", fmt.Errorf("failed to generate context: %v", err)
+		return "", err
 	}
 
 	context := resp.Choices[0].Message.Content
 	return fmt.Sprintf("Context: \n%s;\n\nChunk: \n%s", context, chunkText), nil
 }
 
-// Update vectorSearchHandler to use codesmart.embedding by default
+// Update vectorSearchHandler to use the default embedding model
 func vectorSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	collection := arguments["collection"].(string)
+	collection, err := collectionArgument(arguments)
+	if err != nil {
+		return nil, err
+	}
 	query := arguments["query"].(string)
 
 	ctx := context.Background()
@@ -419,11 +1300,11 @@ func vectorSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 	// Check if collection exists and get info
 	collectionInfo, err := qdrantClient().GetCollectionInfo(ctx, collection)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get collection info: %v", err)
+		return nil, qdrantError(err, "failed to get collection info")
 	}
 
-	// Always default to codesmart.embedding
-	modelStr := "codesmart.embedding"
+	// Default to RAG_DEFAULT_MODEL
+	modelStr := defaultEmbeddingModel()
 	if modelArg, ok := arguments["model"].(string); ok && modelArg != "" {
 		embModel, _, err := validateEmbeddingModel(modelArg)
 		if err != nil {
@@ -433,12 +1314,12 @@ func vectorSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 	}
 
 	// Generate embedding for the query using selected model
-	resp, err := services.DefaultOpenAIClient().CreateEmbeddings(context.Background(), openai.EmbeddingRequest{
+	resp, err := services.EmbeddingOpenAIClient().CreateEmbeddings(context.Background(), openai.EmbeddingRequest{
 		Input: []string{query},
 		Model: openai.EmbeddingModel(modelStr),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate embeddings for query: %v", err)
+		return nil, openAIError(err, "failed to generate embeddings for query")
 	}
 
 	// Lower score threshold and add limit
@@ -446,7 +1327,7 @@ func vectorSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 	limit := uint64(10)            // Limit results to 10
 
 	// Search Qdrant with debug info
-	searchResult, err := qdrantClient().Query(ctx, &qdrant.QueryPoints{
+	queryPoints := &qdrant.QueryPoints{
 		CollectionName: collection,
 		Query:          qdrant.NewQuery(resp.Data[0].Embedding...), // Use Query instead of Vector
 		Limit:          &limit,
@@ -456,19 +1337,28 @@ func vectorSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 				Enable: true,
 			},
 		},
-	})
+	}
+	vectorName, _ := arguments["vector_name"].(string)
+	if vectorName = strings.TrimSpace(vectorName); vectorName != "" {
+		queryPoints.Using = &vectorName
+	}
+	searchResult, err := qdrantClient().Query(ctx, queryPoints)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search in Qdrant: %v", err)
+		return nil, qdrantError(err, "failed to search in Qdrant")
 	}
 
 	// Add debug info to results
 	var resultText string
-	resultText = fmt.Sprintf("Search Results for Collection: %s\nTotal points in collection: %d\nQuery: %s\nModel: %s\nScore threshold: %f\n\n",
+	resultText = fmt.Sprintf("Search Results for Collection: %s\nTotal points in collection: %d\nQuery: %s\nModel: %s\nScore threshold: %f\n",
 		collection,
 		collectionInfo.PointsCount,
 		query,
 		modelStr,
 		scoreThreshold)
+	if vectorName != "" {
+		resultText += fmt.Sprintf("Vector: %s\n", vectorName)
+	}
+	resultText += "\n"
 
 	if len(searchResult) == 0 {
 		resultText += "No results found that match the query with the current threshold.\n"
@@ -495,11 +1385,15 @@ func vectorSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 			"Test ID: %s\n"+
 			"Priority: %s\n"+
 			"Feature: %s\n"+
-			"Subfeature: %s\n"+
-			"Content: %s\n\n",
+			"Subfeature: %s\n",
 			i+1, hit.Score, usedModel, filePath,
 			component, status, testID, priority,
-			feature, subfeature, content)
+			feature, subfeature)
+		// Points indexed from a source with a link, such as a transcript segment, point back to it
+		if url := hit.Payload["url"].GetStringValue(); url != "" {
+			resultText += fmt.Sprintf("URL: %s\n", url)
+		}
+		resultText += fmt.Sprintf("Content: %s\n\n", content)
 	}
 
 	return mcp.NewToolResultText(resultText), nil
@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/athapong/aio-mcp/services"
+	"github.com/pkoukk/tiktoken-go"
+	"github.com/qdrant/go-client/qdrant"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/grpc"
+)
+
+// fakePointsServer is an in-memory Qdrant points service that keeps the payload
+// of upserted points and counts upserts
+type fakePointsServer struct {
+	qdrant.UnimplementedPointsServer
+
+	mu       sync.Mutex
+	payloads map[string]map[string]*qdrant.Value
+	upserts  int
+}
+
+func (f *fakePointsServer) Upsert(ctx context.Context, request *qdrant.UpsertPoints) (*qdrant.PointsOperationResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.upserts++
+	for _, point := range request.Points {
+		f.payloads[point.Id.String()] = point.Payload
+	}
+	return &qdrant.PointsOperationResponse{Result: &qdrant.UpdateResult{Status: qdrant.UpdateStatus_Completed}}, nil
+}
+
+func (f *fakePointsServer) Get(ctx context.Context, request *qdrant.GetPoints) (*qdrant.GetResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var points []*qdrant.RetrievedPoint
+	for _, id := range request.Ids {
+		if payload, ok := f.payloads[id.String()]; ok {
+			points = append(points, &qdrant.RetrievedPoint{Id: id, Payload: payload})
+		}
+	}
+	return &qdrant.GetResponse{Result: points}, nil
+}
+
+// withFakeRagBackends points the Qdrant and embedding clients at in-process
+// fakes for one test, returning the store and a counter of embedding requests
+func withFakeRagBackends(t *testing.T) (*fakePointsServer, func() int) {
+	t.Helper()
+
+	store := &fakePointsServer{payloads: make(map[string]map[string]*qdrant.Value)}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	grpcServer := grpc.NewServer()
+	qdrant.RegisterPointsServer(grpcServer, store)
+	go grpcServer.Serve(listener)
+
+	client, err := qdrant.NewClient(&qdrant.Config{
+		Host:                   "127.0.0.1",
+		Port:                   listener.Addr().(*net.TCPAddr).Port,
+		SkipCompatibilityCheck: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create Qdrant client: %v", err)
+	}
+
+	var (
+		embedMu    sync.Mutex
+		embeddings int
+	)
+	embedder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		embedMu.Lock()
+		embeddings++
+		embedMu.Unlock()
+		json.NewEncoder(w).Encode(openai.EmbeddingResponse{
+			Object: "list",
+			Data:   []openai.Embedding{{Object: "embedding", Embedding: []float32{0.1, 0.2, 0.3}}},
+		})
+	}))
+	embedderConfig := openai.DefaultConfig("test")
+	embedderConfig.BaseURL = embedder.URL
+
+	originalQdrant, originalEmbedder := qdrantClient, services.EmbeddingOpenAIClient
+	qdrantClient = func() *qdrant.Client { return client }
+	services.EmbeddingOpenAIClient = func() *openai.Client { return openai.NewClientWithConfig(embedderConfig) }
+	t.Cleanup(func() {
+		qdrantClient, services.EmbeddingOpenAIClient = originalQdrant, originalEmbedder
+		client.Close()
+		grpcServer.Stop()
+		embedder.Close()
+	})
+
+	return store, func() int {
+		embedMu.Lock()
+		defer embedMu.Unlock()
+		return embeddings
+	}
+}
+
+func TestIndexFileSkipsUnchangedFile(t *testing.T) {
+	// Chunking needs the cl100k_base encoding, which tiktoken downloads on first use
+	if _, err := tiktoken.GetEncoding("cl100k_base"); err != nil {
+		t.Skipf("tokenizer encoding unavailable: %v", err)
+	}
+	store, embeddings := withFakeRagBackends(t)
+
+	filePath := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(filePath, []byte("The deploy runs every Monday."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	model := "text-embedding-3-small"
+
+	first, err := indexFile(ctx, "docs", filePath, model, false, indexOptions{})
+	if err != nil {
+		t.Fatalf("first index: %v", err)
+	}
+	if first.chunks != 1 {
+		t.Fatalf("first index wrote %d chunks, want 1", first.chunks)
+	}
+
+	second, err := indexFile(ctx, "docs", filePath, model, false, indexOptions{})
+	if err != nil {
+		t.Fatalf("second index: %v", err)
+	}
+	if second.chunks != 0 {
+		t.Fatalf("unchanged file was re-indexed with %d chunks", second.chunks)
+	}
+	if embeddings() != 1 || store.upserts != 1 {
+		t.Fatalf("got %d embedding requests and %d upserts, want 1 of each", embeddings(), store.upserts)
+	}
+
+	forced, err := indexFile(ctx, "docs", filePath, model, true, indexOptions{})
+	if err != nil {
+		t.Fatalf("forced index: %v", err)
+	}
+	if forced.chunks != 1 {
+		t.Fatalf("forced index wrote %d chunks, want 1", forced.chunks)
+	}
+
+	if err := os.WriteFile(filePath, []byte("The deploy runs every Tuesday."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := indexFile(ctx, "docs", filePath, model, false, indexOptions{})
+	if err != nil {
+		t.Fatalf("index after change: %v", err)
+	}
+	if changed.chunks != 1 {
+		t.Fatalf("changed file wrote %d chunks, want 1", changed.chunks)
+	}
+	if embeddings() != 3 || store.upserts != 3 {
+		t.Fatalf("got %d embedding requests and %d upserts, want 3 of each", embeddings(), store.upserts)
+	}
+}
@@ -0,0 +1,213 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/athapong/aio-mcp/services"
+	"github.com/athapong/aio-mcp/util"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/qdrant/go-client/qdrant"
+	"github.com/sashabaranov/go-openai"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+const defaultReferenceLimit = 20
+
+// RegisterFindReferencesTool registers the tool that combines GitLab code search
+// with RAG search over an indexed copy of a repository. It needs both the gitlab
+// and rag tool groups.
+func RegisterFindReferencesTool(s *server.MCPServer) {
+	findReferencesTool := mcp.NewTool("find_references",
+		mcp.WithDescription("Find where a symbol or concept is used in a repository by combining GitLab code search with a RAG search of the repository's indexed copy, ranking hits found by both first"),
+		mcp.WithString("query", mcp.Required(), mcp.Description("Symbol name or text to look for")),
+		mcp.WithString("project_path", mcp.Required(), mcp.Description("Project/repo path")),
+		withCollection("Memory collection the repository was indexed into, e.g. with RAG_memory_index_directory"),
+		mcp.WithString("ref", mcp.Description("Branch name or tag to search in GitLab (default: the project's default branch)")),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum number of hits per backend (default: %d)", defaultReferenceLimit))),
+		mcp.WithString("model", mcp.Description("Embedding model the collection was indexed with (default: "+defaultEmbeddingModel()+")")),
+	)
+	s.AddTool(findReferencesTool, util.ErrorGuard(findReferencesHandler))
+}
+
+// reference is one file a backend found the query in
+type reference struct {
+	path     string
+	line     int
+	snippet  string
+	gitlab   bool
+	rag      bool
+	ragScore float32
+}
+
+func findReferencesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	query := arguments["query"].(string)
+	projectPath := arguments["project_path"].(string)
+	ref, _ := arguments["ref"].(string)
+
+	limit := defaultReferenceLimit
+	if value, ok := arguments["limit"].(float64); ok && value >= 1 {
+		limit = int(value)
+	}
+
+	collection, err := collectionArgument(arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	modelStr, _, err := resolveEmbeddingModel(arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	// A failing backend is reported next to the other's hits rather than
+	// failing the call, since either alone still answers the question
+	var failures []string
+	blobs, err := searchGitLabCode(ctx, projectPath, query, ref, limit)
+	if err != nil {
+		failures = append(failures, fmt.Sprintf("gitlab: %v", err))
+	}
+	hits, err := searchCollection(ctx, collection, query, modelStr, limit)
+	if err != nil {
+		failures = append(failures, fmt.Sprintf("rag: %v", err))
+	}
+	if len(failures) == 2 {
+		return nil, util.NewToolError(util.ErrCodeUpstream, "both backends failed:\n- %s", strings.Join(failures, "\n- "))
+	}
+
+	references := mergeReferences(blobs, hits)
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("References to %q in %s (%d found, %d by both backends):\n\n", query, projectPath, len(references), countBoth(references)))
+	for i, r := range references {
+		location := r.path
+		if r.line > 0 {
+			location = fmt.Sprintf("%s:%d", r.path, r.line)
+		}
+		var sources []string
+		if r.gitlab {
+			sources = append(sources, "gitlab")
+		}
+		if r.rag {
+			sources = append(sources, fmt.Sprintf("rag %.2f", r.ragScore))
+		}
+		result.WriteString(fmt.Sprintf("%d. %s [%s]\n", i+1, location, strings.Join(sources, ", ")))
+		result.WriteString(indentSnippet(util.TruncateText(strings.TrimSpace(r.snippet), 500)))
+		result.WriteString("\n")
+	}
+	if len(references) == 0 {
+		result.WriteString("No references found\n")
+	}
+	if len(failures) > 0 {
+		result.WriteString("\nFailed backends:\n- " + strings.Join(failures, "\n- ") + "\n")
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// searchGitLabCode runs a GitLab code search in one project
+func searchGitLabCode(ctx context.Context, projectPath, query, ref string, limit int) ([]*gitlab.Blob, error) {
+	opt := &gitlab.SearchOptions{ListOptions: gitlab.ListOptions{PerPage: limit}}
+	if ref != "" {
+		opt.Ref = gitlab.Ptr(ref)
+	}
+	blobs, _, err := gitlabClient().Search.BlobsByProject(projectPath, query, opt, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, gitlabError(err, "failed to search code")
+	}
+	return blobs, nil
+}
+
+// searchCollection returns the chunks of a RAG collection closest to query
+func searchCollection(ctx context.Context, collection, query, modelStr string, limit int) ([]*qdrant.ScoredPoint, error) {
+	resp, err := services.EmbeddingOpenAIClient().CreateEmbeddings(ctx, openai.EmbeddingRequest{
+		Input: []string{query},
+		Model: openai.EmbeddingModel(modelStr),
+	})
+	if err != nil {
+		return nil, openAIError(err, "failed to generate embeddings for query")
+	}
+
+	scoreThreshold := float32(0.3)
+	queryLimit := uint64(limit)
+	hits, err := qdrantClient().Query(ctx, &qdrant.QueryPoints{
+		CollectionName: collection,
+		Query:          qdrant.NewQuery(resp.Data[0].Embedding...),
+		Limit:          &queryLimit,
+		ScoreThreshold: &scoreThreshold,
+		WithPayload:    qdrant.NewWithPayload(true),
+	})
+	if err != nil {
+		return nil, qdrantError(err, "failed to search in Qdrant")
+	}
+	return hits, nil
+}
+
+// mergeReferences combines the hits of both backends by file. RAG chunks carry
+// the path the file was indexed from, usually a local checkout, so they match a
+// GitLab hit when they end with its repository path. Files found by both
+// backends rank first, then GitLab's exact matches, then RAG hits by score.
+func mergeReferences(blobs []*gitlab.Blob, hits []*qdrant.ScoredPoint) []*reference {
+	var references []*reference
+	for _, blob := range blobs {
+		references = append(references, &reference{path: blob.Path, line: blob.Startline, snippet: blob.Data, gitlab: true})
+	}
+
+	for _, hit := range hits {
+		filePath := filepath.ToSlash(hit.Payload["filePath"].GetStringValue())
+		content := hit.Payload["content"].GetStringValue()
+
+		matched := false
+		for _, r := range references {
+			if r.gitlab && (filePath == r.path || strings.HasSuffix(filePath, "/"+r.path)) {
+				if !r.rag || hit.Score > r.ragScore {
+					r.rag, r.ragScore = true, hit.Score
+				}
+				matched = true
+			}
+		}
+		if !matched {
+			references = append(references, &reference{path: filePath, snippet: content, rag: true, ragScore: hit.Score})
+		}
+	}
+
+	rank := func(r *reference) int {
+		switch {
+		case r.gitlab && r.rag:
+			return 0
+		case r.gitlab:
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.SliceStable(references, func(i, j int) bool {
+		if rank(references[i]) != rank(references[j]) {
+			return rank(references[i]) < rank(references[j])
+		}
+		return references[i].ragScore > references[j].ragScore
+	})
+	return references
+}
+
+func countBoth(references []*reference) int {
+	count := 0
+	for _, r := range references {
+		if r.gitlab && r.rag {
+			count++
+		}
+	}
+	return count
+}
+
+func indentSnippet(snippet string) string {
+	if snippet == "" {
+		return ""
+	}
+	return "   " + strings.ReplaceAll(snippet, "\n", "\n   ") + "\n"
+}
@@ -1,21 +1,32 @@
 package tools
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
 	"fmt"
 	"image/png"
 	"os"
+	"os/exec"
+	"strings"
 	"time"
 
+	"github.com/athapong/aio-mcp/config"
+	"github.com/athapong/aio-mcp/services"
 	"github.com/athapong/aio-mcp/util"
 	"github.com/kbinani/screenshot"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/sashabaranov/go-openai"
 )
 
 // RegisterScreenshotTool registers the screenshot capturing tool with the MCP server
 func RegisterScreenshotTool(s *server.MCPServer) {
 	tool := mcp.NewTool("capture_screenshot",
 		mcp.WithDescription("Capture a screenshot of the entire screen"),
+		mcp.WithString("output_dir", mcp.Description("Directory to save the screenshot in, defaults to OUTPUT_DIR or the current working directory")),
+		mcp.WithBoolean("ocr", mcp.Description("Extract the text from the screenshot and return it alongside the file path")),
+		mcp.WithBoolean("include_image", mcp.DefaultBool(false), mcp.Description("Return the screenshot as an image alongside the text summary. Full-screen images are large, so only the file path is returned by default")),
 	)
 	s.AddTool(tool, util.ErrorGuard(util.AdaptLegacyHandler(screenshotHandler)))
 }
@@ -34,17 +45,105 @@ func screenshotHandler(arguments map[string]interface{}) (*mcp.CallToolResult, e
 	}
 
 	// Save the screenshot to a file
-	fileName := fmt.Sprintf("screenshot_%d.png", time.Now().Unix())
-	file, err := os.Create(fileName)
+	outputDir, _ := arguments["output_dir"].(string)
+	fileName, err := util.OutputPath(outputDir, fmt.Sprintf("screenshot_%d.png", time.Now().Unix()))
 	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, img); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to encode image: %v", err)), nil
+	}
+
+	if err := os.WriteFile(fileName, encoded.Bytes(), 0o644); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create file: %v", err)), nil
 	}
-	defer file.Close()
 
-	err = png.Encode(file, img)
+	message := fmt.Sprintf("Screenshot saved to %s", fileName)
+	if useOCR, _ := arguments["ocr"].(bool); useOCR {
+		text, err := extractScreenshotText(fileName)
+		if err != nil {
+			message += fmt.Sprintf("\n\nOCR unavailable: %v", err)
+		} else {
+			message += "\n\nRecognized text:\n" + text
+		}
+	}
+
+	result := util.NewResultBuilder(message)
+	if includeImage, _ := arguments["include_image"].(bool); includeImage {
+		result.Image(encoded.Bytes(), "image/png")
+	}
+	return result.Build(), nil
+}
+
+// extractScreenshotText runs OCR on an image file. The backend is chosen with
+// OCR_BACKEND: "tesseract", "openai", or "auto" (default) which prefers a local
+// tesseract binary and falls back to an OpenAI vision model.
+func extractScreenshotText(fileName string) (string, error) {
+	cfg := config.Get()
+	switch cfg.OCRBackend {
+	case "tesseract":
+		return tesseractOCR(fileName)
+	case "openai":
+		return openAIOCR(fileName)
+	case "auto":
+		if _, err := exec.LookPath("tesseract"); err == nil {
+			return tesseractOCR(fileName)
+		}
+		if cfg.OpenAIAPIKey != "" {
+			return openAIOCR(fileName)
+		}
+		return "", fmt.Errorf("no OCR backend available, install tesseract or set OPENAI_API_KEY")
+	default:
+		return "", fmt.Errorf("unknown OCR_BACKEND %q", cfg.OCRBackend)
+	}
+}
+
+func tesseractOCR(fileName string) (string, error) {
+	output, err := exec.Command("tesseract", fileName, "stdout").Output()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to encode image: %v", err)), nil
+		return "", fmt.Errorf("tesseract failed: %v", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func openAIOCR(fileName string) (string, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return "", fmt.Errorf("failed to read screenshot: %v", err)
+	}
+
+	resp, err := services.DefaultOpenAIClient().CreateChatCompletion(
+		context.Background(),
+		openai.ChatCompletionRequest{
+			Model: config.Get().OCROpenAIModel,
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role: openai.ChatMessageRoleUser,
+					MultiContent: []openai.ChatMessagePart{
+						{
+							Type: openai.ChatMessagePartTypeText,
+							Text: "Extract all text visible in this screenshot. Answer only with the extracted text.",
+						},
+						{
+							Type: openai.ChatMessagePartTypeImageURL,
+							ImageURL: &openai.ChatMessageImageURL{
+								URL: "data:image/png;base64," + base64.StdEncoding.EncodeToString(data),
+							},
+						},
+					},
+				},
+			},
+		},
+	)
+	if err != nil {
+		return "", fmt.Errorf("vision OCR failed: %v", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no response from vision model")
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Screenshot saved to %s", fileName)), nil
+	return resp.Choices[0].Message.Content, nil
 }
@@ -1,13 +1,15 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"os"
+	"time"
 
 	htmltomarkdownnnn "github.com/JohannesKaufmann/html-to-markdown/v2"
+	"github.com/athapong/aio-mcp/config"
 	"github.com/athapong/aio-mcp/services"
 	"github.com/athapong/aio-mcp/util"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -23,7 +25,7 @@ func RegisterWebSearchTool(s *server.MCPServer) {
 		mcp.WithString("country", mcp.DefaultString("ALL"), mcp.Description("Country code")),
 	)
 
-	s.AddTool(tool, util.ErrorGuard(util.AdaptLegacyHandler(webSearchHandler)))
+	s.AddTool(tool, util.ErrorGuard(webSearchHandler))
 }
 
 type SearchResult struct {
@@ -34,7 +36,12 @@ type SearchResult struct {
 	Age         string `json:"age"`
 }
 
-func webSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+// braveRetryDelay is the delay before the first retry of a failed search;
+// BRAVE_MAX_ATTEMPTS bounds the attempts
+const braveRetryDelay = time.Second
+
+func webSearchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
 	query, ok := arguments["query"].(string)
 	if !ok {
 		return mcp.NewToolResultError("query must be a string"), nil
@@ -55,8 +62,8 @@ func webSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult, er
 		country = countryArg
 	}
 
-	apiKey := os.Getenv("BRAVE_API_KEY")
-	if apiKey == "" {
+	cfg := config.Get()
+	if cfg.BraveAPIKey == "" {
 		return mcp.NewToolResultError("BRAVE_API_KEY environment variable is required"), nil
 	}
 
@@ -66,27 +73,14 @@ func webSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult, er
 	params.Add("count", fmt.Sprintf("%d", count))
 	params.Add("country", country)
 
-	req, err := http.NewRequest("GET", baseURL+"?"+params.Encode(), nil)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to create request: %v", err)), nil
-	}
-
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("X-Subscription-Token", apiKey)
-
-	resp, err := services.DefaultHttpClient().Do(req)
+	var body []byte
+	err := util.Retry(ctx, cfg.BraveMaxAttempts, braveRetryDelay, func() error {
+		var err error
+		body, err = braveSearch(ctx, baseURL+"?"+params.Encode(), cfg.BraveAPIKey, cfg.BraveTimeout)
+		return err
+	})
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to perform search: %v", err)), nil
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to read response: %v", err)), nil
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return mcp.NewToolResultError(fmt.Sprintf("API request failed: %s", string(body))), nil
+		return nil, err
 	}
 
 	var results []*SearchResult
@@ -139,3 +133,37 @@ func webSearchHandler(arguments map[string]interface{}) (*mcp.CallToolResult, er
 
 	return mcp.NewToolResultText(responseText), nil
 }
+
+// braveSearch performs one search request bounded by timeout. Rate limits and
+// server errors are returned as retryable tool errors carrying the status.
+func braveSearch(ctx context.Context, searchURL, apiKey string, timeout time.Duration) ([]byte, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Subscription-Token", apiKey)
+
+	resp, err := services.DefaultHttpClient().Do(req)
+	if err != nil {
+		return nil, util.WrapToolError(util.ErrCodeUpstream, err, "failed to perform search")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, util.WrapToolError(util.ErrCodeUpstream, err, "failed to read response")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, util.UpstreamError(fmt.Errorf("%s", body), resp.StatusCode, fmt.Sprintf("search request failed with status %d", resp.StatusCode))
+	}
+	return body, nil
+}
@@ -1,8 +1,12 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/athapong/aio-mcp/util"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -24,6 +28,7 @@ type ThoughtData struct {
 }
 
 type SequentialThinkingServer struct {
+	mu                sync.Mutex
 	thoughtHistory    []ThoughtData
 	branches          map[string][]ThoughtData
 	currentBranchID   string
@@ -178,13 +183,61 @@ func (s *SequentialThinkingServer) getBranchSummary() map[string]interface{} {
 	return summary
 }
 
-// Add package-level variable to share the server instance
-var thinkingServer *SequentialThinkingServer
+// mergeBranch appends the final thought of a branch to the main thought history
+// and returns to the main line
+func (s *SequentialThinkingServer) mergeBranch(branchID string) (int, error) {
+	thoughts, exists := s.branches[branchID]
+	if !exists || len(thoughts) == 0 {
+		return 0, fmt.Errorf("branch not found: %s", branchID)
+	}
 
-// Modify existing RegisterSequentialThinkingTool to remove history tool registration
-func RegisterSequentialThinkingTool(s *server.MCPServer) {
-	thinkingServer = NewSequentialThinkingServer() // Make thinkingServer package-level
+	last := thoughts[len(thoughts)-1]
+	merged := ThoughtData{
+		Thought:           fmt.Sprintf("[Merged from branch %s] %s", branchID, last.Thought),
+		ThoughtNumber:     len(s.thoughtHistory) + 1,
+		TotalThoughts:     len(s.thoughtHistory) + 1,
+		BranchFromThought: last.BranchFromThought,
+		BranchID:          &branchID,
+		NextThoughtNeeded: last.NextThoughtNeeded,
+		Result:            last.Result,
+		Summary:           last.Summary,
+	}
+	if merged.TotalThoughts < last.TotalThoughts {
+		merged.TotalThoughts = last.TotalThoughts
+	}
+
+	s.thoughtHistory = append(s.thoughtHistory, merged)
+	s.currentBranchID = ""
+	return len(s.thoughtHistory), nil
+}
+
+// thinkingSessions holds the thinking state of each MCP client session, so SSE
+// clients do not share thought history. Sessions idle for a day are dropped.
+var (
+	thinkingSessions   = util.NewCache[string, *SequentialThinkingServer](24*time.Hour, 1000)
+	thinkingSessionsMu sync.Mutex
+)
+
+// thinkingServerFor returns the thinking state for the session in ctx, creating it if needed
+func thinkingServerFor(ctx context.Context) *SequentialThinkingServer {
+	var sessionID string
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		sessionID = session.SessionID()
+	}
+
+	thinkingSessionsMu.Lock()
+	defer thinkingSessionsMu.Unlock()
 
+	thinkingServer, ok := thinkingSessions.Get(sessionID)
+	if !ok {
+		thinkingServer = NewSequentialThinkingServer()
+	}
+	// Set on every access to keep active sessions from expiring
+	thinkingSessions.Set(sessionID, thinkingServer)
+	return thinkingServer
+}
+
+func RegisterSequentialThinkingTool(s *server.MCPServer) {
 	sequentialThinkingTool := mcp.NewTool("sequentialthinking",
 		mcp.WithDescription(`A detailed tool for dynamic and reflective problem-solving through thoughts.
 This tool helps analyze problems through a flexible thinking process that can adapt and evolve.
@@ -253,9 +306,13 @@ You should:
 		mcp.WithString("summary", mcp.Description("Brief summary of the thought's key points")),
 	)
 
-	s.AddTool(sequentialThinkingTool, util.ErrorGuard(util.AdaptLegacyHandler(func(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-		return thinkingServer.processThought(arguments)
-	})))
+	s.AddTool(sequentialThinkingTool, util.ErrorGuard(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		thinkingServer := thinkingServerFor(ctx)
+		thinkingServer.mu.Lock()
+		defer thinkingServer.mu.Unlock()
+
+		return thinkingServer.processThought(request.Params.Arguments)
+	}))
 }
 
 // Move the history tool to its own registration function
@@ -263,9 +320,15 @@ func RegisterSequentialThinkingHistoryTool(s *server.MCPServer) {
 	historyTool := mcp.NewTool("sequentialthinking_history",
 		mcp.WithDescription("Retrieve the thought history for the current thinking process"),
 		mcp.WithString("branchId", mcp.Description("Optional branch ID to get history for")),
+		mcp.WithString("format", mcp.DefaultString("json"), mcp.Description("Output format: json (default) or markdown")),
 	)
 
-	s.AddTool(historyTool, util.ErrorGuard(util.AdaptLegacyHandler(func(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	s.AddTool(historyTool, util.ErrorGuard(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.Params.Arguments
+		thinkingServer := thinkingServerFor(ctx)
+		thinkingServer.mu.Lock()
+		defer thinkingServer.mu.Unlock()
+
 		var history []ThoughtData
 		if branchID, ok := arguments["branchId"].(string); ok && branchID != "" {
 			if branch, exists := thinkingServer.branches[branchID]; exists {
@@ -275,10 +338,106 @@ func RegisterSequentialThinkingHistoryTool(s *server.MCPServer) {
 			history = thinkingServer.thoughtHistory
 		}
 
-		jsonResponse, err := json.MarshalIndent(history, "", "  ")
+		format, _ := arguments["format"].(string)
+		switch format {
+		case "", "json":
+			jsonResponse, err := json.MarshalIndent(history, "", "  ")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return mcp.NewToolResultText(string(jsonResponse)), nil
+		case "markdown":
+			branchID, _ := arguments["branchId"].(string)
+			return mcp.NewToolResultText(renderThoughtsMarkdown(history, branchID)), nil
+		default:
+			return mcp.NewToolResultError("Invalid format. Use 'json' or 'markdown'"), nil
+		}
+	}))
+}
+
+func RegisterSequentialThinkingMergeBranchTool(s *server.MCPServer) {
+	mergeTool := mcp.NewTool("sequentialthinking_merge_branch",
+		mcp.WithDescription("Merge a branch's final thought and result back into the main thought history and return to the main line"),
+		mcp.WithString("branchId", mcp.Required(), mcp.Description("ID of the branch to merge")),
+	)
+
+	s.AddTool(mergeTool, util.ErrorGuard(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		branchID, ok := request.Params.Arguments["branchId"].(string)
+		if !ok || branchID == "" {
+			return nil, fmt.Errorf("branchId argument is required")
+		}
+
+		thinkingServer := thinkingServerFor(ctx)
+		thinkingServer.mu.Lock()
+		defer thinkingServer.mu.Unlock()
+
+		historyLength, err := thinkingServer.mergeBranch(branchID)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		response := map[string]interface{}{
+			"mergedBranch":  branchID,
+			"historyLength": historyLength,
+			"currentBranch": thinkingServer.currentBranchID,
+		}
+		jsonResponse, err := json.MarshalIndent(response, "", "  ")
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 		return mcp.NewToolResultText(string(jsonResponse)), nil
-	})))
+	}))
+}
+
+// renderThoughtsMarkdown renders a thought history as a shareable Markdown reasoning log
+func renderThoughtsMarkdown(history []ThoughtData, branchID string) string {
+	var sb strings.Builder
+
+	if branchID != "" {
+		sb.WriteString(fmt.Sprintf("# Thought History (branch: %s)\n\n", branchID))
+	} else {
+		sb.WriteString("# Thought History\n\n")
+	}
+
+	if len(history) == 0 {
+		sb.WriteString("_No thoughts recorded._\n")
+		return sb.String()
+	}
+
+	for _, thought := range history {
+		sb.WriteString(fmt.Sprintf("## Thought %d of %d\n\n", thought.ThoughtNumber, thought.TotalThoughts))
+
+		var annotations []string
+		if thought.IsRevision != nil && *thought.IsRevision {
+			if thought.RevisesThought != nil {
+				annotations = append(annotations, fmt.Sprintf("revises thought %d", *thought.RevisesThought))
+			} else {
+				annotations = append(annotations, "revision")
+			}
+		}
+		if thought.BranchFromThought != nil {
+			annotations = append(annotations, fmt.Sprintf("branched from thought %d", *thought.BranchFromThought))
+		}
+		if thought.BranchID != nil && *thought.BranchID != "" {
+			annotations = append(annotations, fmt.Sprintf("branch `%s`", *thought.BranchID))
+		}
+		if thought.NeedsMoreThoughts != nil && *thought.NeedsMoreThoughts {
+			annotations = append(annotations, "needs more thoughts")
+		}
+		if len(annotations) > 0 {
+			sb.WriteString(fmt.Sprintf("_%s_\n\n", strings.Join(annotations, "; ")))
+		}
+
+		sb.WriteString(thought.Thought)
+		sb.WriteString("\n\n")
+
+		if thought.Summary != nil && *thought.Summary != "" {
+			sb.WriteString(fmt.Sprintf("> **Summary:** %s\n\n", *thought.Summary))
+		}
+		if thought.Result != nil && *thought.Result != "" {
+			sb.WriteString(fmt.Sprintf("> **Result:** %s\n\n", *thought.Result))
+		}
+	}
+
+	return sb.String()
 }
@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// fakeSession is a client session that only has an ID
+type fakeSession struct {
+	id string
+}
+
+func (f fakeSession) Initialize()                                         {}
+func (f fakeSession) Initialized() bool                                   { return true }
+func (f fakeSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (f fakeSession) SessionID() string                                   { return f.id }
+
+func TestThinkingServerForIsolatesSessions(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0")
+	ctxA := s.WithContext(context.Background(), fakeSession{id: "thinking-test-a"})
+	ctxB := s.WithContext(context.Background(), fakeSession{id: "thinking-test-b"})
+
+	thinkingA := thinkingServerFor(ctxA)
+	result, err := thinkingA.processThought(map[string]interface{}{
+		"thought":           "first step",
+		"thoughtNumber":     float64(1),
+		"totalThoughts":     float64(2),
+		"nextThoughtNeeded": true,
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("processThought = %v, %v", result, err)
+	}
+
+	if got := thinkingServerFor(ctxA); got != thinkingA || len(got.thoughtHistory) != 1 {
+		t.Fatalf("session A lost its thought history")
+	}
+	if history := thinkingServerFor(ctxB).thoughtHistory; len(history) != 0 {
+		t.Fatalf("session B sees %d thoughts of session A", len(history))
+	}
+}
@@ -2,10 +2,16 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/athapong/aio-mcp/config"
 	"github.com/athapong/aio-mcp/services"
 	"github.com/athapong/aio-mcp/util"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -20,72 +26,60 @@ func RegisterToolManagerTool(s *server.MCPServer) {
 		mcp.WithString("tool_name", mcp.Description("Tool name to enable/disable")),
 	)
 
-	s.AddTool(tool, util.ErrorGuard(util.AdaptLegacyHandler(toolManagerHandler)))
+	s.AddTool(tool, util.ErrorGuard(toolManagerHandler(s)))
+
+	describeTool := mcp.NewTool("tool_describe",
+		mcp.WithDescription("Get the description and full argument schema of a registered tool"),
+		mcp.WithString("tool_name", mcp.Required(), mcp.Description("Name of the tool to describe")),
+	)
+	s.AddTool(describeTool, util.ErrorGuard(toolDescribeHandler(s)))
 
 	planTool := mcp.NewTool("tool_use_plan",
 		mcp.WithDescription("Create a plan using available tools to solve the request"),
 		mcp.WithString("request", mcp.Required(), mcp.Description("Request to plan for")),
 		mcp.WithString("context", mcp.Required(), mcp.Description("Context related to the request")),
+		mcp.WithBoolean("execute", mcp.Description("Run the plan's steps with the server's tools after planning, feeding each step's output into the next (default: false)")),
+		mcp.WithNumber("max_steps", mcp.Description(fmt.Sprintf("Refuse to execute plans with more steps than this (default: 5, at most %d)", maxPlanSteps))),
+		mcp.WithBoolean("allow_changes", mcp.Description("Let executed steps make changes; otherwise tools that support dry_run are run with it and plans using other tools that can make changes are refused")),
+		util.WithDryRun(),
 	)
-	s.AddTool(planTool, util.ErrorGuard(util.AdaptLegacyHandler(toolUsePlanHandler)))
+	s.AddTool(planTool, util.ErrorGuard(toolUsePlanHandler(s)))
+}
+
+func toolManagerHandler(s *server.MCPServer) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return manageTools(ctx, s, request.Params.Arguments)
+	}
 }
 
-func toolManagerHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+func manageTools(ctx context.Context, s *server.MCPServer, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
 	action, ok := arguments["action"].(string)
 	if !ok {
 		return mcp.NewToolResultError("action must be a string"), nil
 	}
 
-	enableTools := os.Getenv("ENABLE_TOOLS")
-	toolList := strings.Split(enableTools, ",")
+	toolList := enabledToolGroups()
 
 	switch action {
 	case "list":
-		response := "Available tools:\n"
-		allEnabled := enableTools == ""
-
-		// List all available tools with status
-		tools := []struct {
-			name string
-			desc string
-		}{
-			{"tool_manager", "Tool management"},
-			{"gemini", "AI tools: web search"},
-			{"fetch", "Web content fetching"},
-			{"confluence", "Confluence integration"},
-			{"youtube", "YouTube transcript"},
-			{"jira", "Jira issue management"},
-			{"gitlab", "GitLab integration"},
-			{"script", "Script execution"},
-			{"rag", "RAG memory tools"},
-			{"gmail", "Gmail tools"},
-			{"calendar", "Google Calendar tools"},
-			{"youtube_channel", "YouTube channel tools"},
-			{"sequential_thinking", "Sequential thinking tool"},
-			{"deepseek", "Deepseek reasoning tool"},
-			{"maps_location_search", "Google Maps location search"},
-			{"maps_geocoding", "Google Maps geocoding and reverse geocoding"},
-			{"maps_place_details", "Google Maps detailed place information"},
+		tools, err := listRegisteredTools(ctx, s)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list tools: %v", err)), nil
 		}
 
+		response := fmt.Sprintf("Registered tools (%d):\n", len(tools))
 		for _, t := range tools {
-			status := "disabled"
-			if allEnabled || contains(toolList, t.name) {
-				status = "enabled"
-			}
-			response += fmt.Sprintf("- %s (%s) [%s]\n", t.name, t.desc, status)
+			response += formatToolSummary(t)
 		}
 		response += "\n"
 
-		// List enabled tools
-		response += "Currently enabled tools:\n"
-		if allEnabled {
+		// List enabled tool groups
+		response += "Currently enabled tool groups:\n"
+		if len(toolList) == 0 {
 			response += "All tools are enabled (ENABLE_TOOLS is empty)\n"
 		} else {
 			for _, tool := range toolList {
-				if tool != "" {
-					response += fmt.Sprintf("- %s\n", tool)
-				}
+				response += fmt.Sprintf("- %s\n", tool)
 			}
 		}
 		return mcp.NewToolResultText(response), nil
@@ -96,26 +90,202 @@ func toolManagerHandler(arguments map[string]interface{}) (*mcp.CallToolResult,
 			return mcp.NewToolResultError("tool_name is required for enable/disable actions"), nil
 		}
 
-		if enableTools == "" {
-			toolList = []string{}
+		setToolGroupEnabled(toolName, action == "enable")
+
+		return mcp.NewToolResultText(fmt.Sprintf("Successfully %sd tool: %s", action, toolName)), nil
+
+	default:
+		return mcp.NewToolResultError("Invalid action. Use 'list', 'enable', or 'disable'"), nil
+	}
+}
+
+// runtimeToolGroups is the list of enabled tool groups, starting from
+// ENABLE_TOOLS and changed by tool_manager. Tool calls run concurrently, so it
+// is kept apart from the shared config and guarded by runtimeToolGroupsMu.
+var (
+	runtimeToolGroups     []string
+	runtimeToolGroupsMu   sync.RWMutex
+	loadRuntimeToolGroups = sync.OnceFunc(func() {
+		runtimeToolGroupsMu.Lock()
+		defer runtimeToolGroupsMu.Unlock()
+		runtimeToolGroups = append([]string(nil), config.Get().EnableTools...)
+	})
+)
+
+// enabledToolGroups returns a copy of the enabled tool groups; empty means all are enabled
+func enabledToolGroups() []string {
+	loadRuntimeToolGroups()
+	runtimeToolGroupsMu.RLock()
+	defer runtimeToolGroupsMu.RUnlock()
+	return append([]string(nil), runtimeToolGroups...)
+}
+
+// toolGroupEnabled reports whether a tool group is enabled, following ENABLE_TOOLS semantics
+func toolGroupEnabled(name string) bool {
+	groups := enabledToolGroups()
+	return len(groups) == 0 || contains(groups, name)
+}
+
+// setToolGroupEnabled adds a tool group to or removes it from the enabled tool groups
+func setToolGroupEnabled(name string, enabled bool) {
+	loadRuntimeToolGroups()
+	runtimeToolGroupsMu.Lock()
+	defer runtimeToolGroupsMu.Unlock()
+	if enabled {
+		if !contains(runtimeToolGroups, name) {
+			runtimeToolGroups = append(runtimeToolGroups, name)
+		}
+	} else {
+		runtimeToolGroups = removeString(runtimeToolGroups, name)
+	}
+}
+
+// listRegisteredTools asks the server for its tool list, so the result always
+// matches what clients see
+func listRegisteredTools(ctx context.Context, s *server.MCPServer) ([]mcp.Tool, error) {
+	var tools []mcp.Tool
+	cursor := ""
+	for {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		message, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": mcp.JSONRPC_VERSION,
+			"id":      1,
+			"method":  string(mcp.MethodToolsList),
+			"params":  params,
+		})
+		if err != nil {
+			return nil, err
 		}
 
-		if action == "enable" {
-			if !contains(toolList, toolName) {
-				toolList = append(toolList, toolName)
+		switch response := s.HandleMessage(ctx, message).(type) {
+		case mcp.JSONRPCResponse:
+			result, ok := response.Result.(mcp.ListToolsResult)
+			if !ok {
+				return nil, fmt.Errorf("unexpected tools/list result %T", response.Result)
 			}
-		} else {
-			toolList = removeString(toolList, toolName)
+			tools = append(tools, result.Tools...)
+			if result.NextCursor == "" {
+				return tools, nil
+			}
+			cursor = string(result.NextCursor)
+		case mcp.JSONRPCError:
+			return nil, fmt.Errorf("%s", response.Error.Message)
+		default:
+			return nil, fmt.Errorf("unexpected tools/list response %T", response)
 		}
+	}
+}
 
-		newEnableTools := strings.Join(toolList, ",")
-		os.Setenv("ENABLE_TOOLS", newEnableTools)
+// toolSchema is one tool of the catalog written by WriteToolSchema
+type toolSchema struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	InputSchema mcp.ToolInputSchema `json:"inputSchema"`
+	Enabled     bool                `json:"enabled"`
+}
 
-		return mcp.NewToolResultText(fmt.Sprintf("Successfully %sd tool: %s", action, toolName)), nil
+// WriteToolSchema writes every tool registered on catalog to path as JSON,
+// sorted by name, marking the tools s also registers as enabled. Reading both
+// from the live registry keeps the catalog in step with the code.
+func WriteToolSchema(ctx context.Context, s, catalog *server.MCPServer, path string) error {
+	enabled, err := listRegisteredTools(ctx, s)
+	if err != nil {
+		return fmt.Errorf("failed to list enabled tools: %v", err)
+	}
+	all, err := listRegisteredTools(ctx, catalog)
+	if err != nil {
+		return fmt.Errorf("failed to list tools: %v", err)
+	}
 
-	default:
-		return mcp.NewToolResultError("Invalid action. Use 'list', 'enable', or 'disable'"), nil
+	enabledNames := make(map[string]bool, len(enabled))
+	for _, tool := range enabled {
+		enabledNames[tool.Name] = true
+	}
+	schemas := make([]toolSchema, 0, len(all))
+	for _, tool := range all {
+		schemas = append(schemas, toolSchema{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: tool.InputSchema,
+			Enabled:     enabledNames[tool.Name],
+		})
 	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Name < schemas[j].Name })
+
+	content, err := json.MarshalIndent(map[string]interface{}{"tools": schemas}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal tool schema: %v", err)
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return nil
+}
+
+func toolDescribeHandler(s *server.MCPServer) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		toolName, _ := request.Params.Arguments["tool_name"].(string)
+
+		tool, ok := LookupTool(ctx, s, toolName)
+		if !ok {
+			return nil, util.NewToolError(util.ErrCodeNotFound, "tool %s not found", toolName)
+		}
+
+		schema, err := json.MarshalIndent(tool, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal tool schema: %v", err)
+		}
+		return mcp.NewToolResultText(string(schema)), nil
+	}
+}
+
+// LookupTool returns the definition of the registered tool with the given name
+func LookupTool(ctx context.Context, s *server.MCPServer, name string) (mcp.Tool, bool) {
+	tools, err := listRegisteredTools(ctx, s)
+	if err != nil {
+		return mcp.Tool{}, false
+	}
+	for _, tool := range tools {
+		if tool.Name == name {
+			return tool, true
+		}
+	}
+	return mcp.Tool{}, false
+}
+
+// formatToolSummary describes a tool with its arguments, marking required ones
+func formatToolSummary(tool mcp.Tool) string {
+	var summary strings.Builder
+	summary.WriteString(fmt.Sprintf("- %s: %s\n", tool.Name, tool.Description))
+
+	names := make([]string, 0, len(tool.InputSchema.Properties))
+	for name := range tool.InputSchema.Properties {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return summary.String()
+	}
+	sort.Strings(names)
+
+	args := make([]string, 0, len(names))
+	for _, name := range names {
+		argType := "any"
+		if property, ok := tool.InputSchema.Properties[name].(map[string]interface{}); ok {
+			if t, ok := property["type"].(string); ok {
+				argType = t
+			}
+		}
+		if contains(tool.InputSchema.Required, name) {
+			args = append(args, fmt.Sprintf("%s (%s, required)", name, argType))
+		} else {
+			args = append(args, fmt.Sprintf("%s (%s)", name, argType))
+		}
+	}
+	summary.WriteString(fmt.Sprintf("  Arguments: %s\n", strings.Join(args, ", ")))
+	return summary.String()
 }
 
 func contains(slice []string, item string) bool {
@@ -137,57 +307,390 @@ func removeString(slice []string, item string) []string {
 	return result
 }
 
-func toolUsePlanHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	request, _ := arguments["request"].(string)
-	contextString, _ := arguments["context"].(string)
+// maxPlanSteps is the most steps tool_use_plan will execute in one call
+const maxPlanSteps = 10
 
-	enabledTools := strings.Split(os.Getenv("ENABLE_TOOLS"), ",")
-	if !contains(enabledTools, "deepseek") {
-		return mcp.NewToolResultError("Deepseek tool must be enabled to generate plans"), nil
-	}
+// planStepOutputLimit caps how much of each step's output is passed on to later steps
+const planStepOutputLimit = 4000
 
-	// Check for configuration
-	useOllama := os.Getenv("USE_OLLAMA_DEEPSEEK") == "true"
-	useOpenRouter := os.Getenv("USE_OPENROUTER") == "true"
+// planStepPattern matches a step of the plan format, capturing its number and tool
+var planStepPattern = regexp.MustCompile(`^\s*(\d+)\.\s*\[([A-Za-z0-9_\-]+)\]`)
 
-	if !useOllama && !useOpenRouter && os.Getenv("DEEPSEEK_API_KEY") == "" {
-		return mcp.NewToolResultError("Either USE_OLLAMA_DEEPSEEK, USE_OPENROUTER must be true, or DEEPSEEK_API_KEY must be set"), nil
-	}
+// planStep is one parsed step of a generated plan
+type planStep struct {
+	number int
+	tool   mcp.Tool
+	text   string
+}
+
+func toolUsePlanHandler(s *server.MCPServer) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.Params.Arguments
+		userRequest, _ := arguments["request"].(string)
+		contextString, _ := arguments["context"].(string)
+		execute, _ := arguments["execute"].(bool)
+
+		cfg := config.Get()
+		if !toolGroupEnabled("deepseek") {
+			return mcp.NewToolResultError("Deepseek tool must be enabled to generate plans"), nil
+		}
+
+		// Check for configuration
+		if !cfg.UseOllamaDeepseek && !cfg.UseOpenRouter && cfg.DeepseekAPIKey == "" {
+			return mcp.NewToolResultError("Either USE_OLLAMA_DEEPSEEK, USE_OPENROUTER must be true, or DEEPSEEK_API_KEY must be set"), nil
+		}
+
+		enabledTools := "all tools"
+		if groups := enabledToolGroups(); len(groups) > 0 {
+			enabledTools = strings.Join(groups, ", ")
+		}
+
+		var registered []mcp.Tool
+		if execute {
+			// Executed plans must name registered tools exactly, so list them
+			tools, err := listRegisteredTools(ctx, s)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list tools: %v", err)
+			}
+			var summaries strings.Builder
+			for _, tool := range tools {
+				if !isPlanExcludedTool(tool.Name) {
+					registered = append(registered, tool)
+					summaries.WriteString(formatToolSummary(tool))
+				}
+			}
+			enabledTools = "\n" + summaries.String()
+		}
 
-	systemPrompt := fmt.Sprintf(`You are a tool usage planning assistant. Create a detailed execution plan using the currently enabled tools: %s
+		systemPrompt := fmt.Sprintf(`You are a tool usage planning assistant. Create a detailed execution plan using the currently enabled tools: %s
 
 Context: %s
 
 Output format:
 1. [Tool Name] - Purpose: ... (Expected result: ...)
 2. [Tool Name] - Purpose: ... (Expected result: ...)
-...`, strings.Join(enabledTools, ", "), contextString)
+...`, enabledTools, contextString)
+		if execute {
+			systemPrompt += "\n\nThe plan will be executed: put the exact name of one of the listed tools in the brackets of each step."
+		}
 
-	messages := []openai.ChatCompletionMessage{
-		{
-			Role:    openai.ChatMessageRoleSystem,
-			Content: systemPrompt,
-		},
-		{
-			Role:    openai.ChatMessageRoleUser,
-			Content: request,
+		plan, err := deepseekChat(ctx, []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: withSystemPromptPrefix(systemPrompt)},
+			{Role: openai.ChatMessageRoleUser, Content: userRequest},
+		})
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result := "📝 **Execution Plan:**\n" + plan
+		if !execute {
+			return mcp.NewToolResultText(result), nil
+		}
+
+		execution, err := executePlan(ctx, s, userRequest, plan, registered, arguments)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(result + "\n\n⚙️ **Execution:**\n" + execution), nil
+	}
+}
+
+// executePlan runs the steps of a generated plan in order. Every step is checked
+// before any of them runs: the plan must fit in max_steps and only name
+// registered tools. The arguments of each step are generated from the request,
+// the plan and the outputs of the previous steps, and validated against the
+// tool's schema. Unless allow_changes is set, tools that support dry_run run
+// with it and plans using any other tool that is not known to be read-only are
+// refused. Execution stops at the first failing step.
+func executePlan(ctx context.Context, s *server.MCPServer, userRequest, plan string, registered []mcp.Tool, arguments map[string]interface{}) (string, error) {
+	maxSteps := 5
+	if value, ok := arguments["max_steps"].(float64); ok && value >= 1 {
+		maxSteps = int(value)
+	}
+	if maxSteps > maxPlanSteps {
+		maxSteps = maxPlanSteps
+	}
+	allowChanges, _ := arguments["allow_changes"].(bool)
+
+	steps, problems := parsePlanSteps(plan, registered)
+	if len(steps) == 0 && len(problems) == 0 {
+		problems = append(problems, "the plan has no steps in the expected format")
+	}
+	if !allowChanges {
+		for _, step := range steps {
+			if planStepNeedsChanges(step.tool) {
+				problems = append(problems, fmt.Sprintf("step %d uses %s, which can make changes and has no dry_run; set allow_changes to run it", step.number, step.tool.Name))
+			}
+		}
+	}
+	if len(steps) > maxSteps {
+		problems = append(problems, fmt.Sprintf("the plan has %d steps, more than max_steps (%d)", len(steps), maxSteps))
+	}
+	if len(problems) > 0 {
+		return "Not executed:\n- " + strings.Join(problems, "\n- ") + "\n", nil
+	}
+
+	if util.IsDryRun(arguments) {
+		var result strings.Builder
+		result.WriteString("Dry run: no steps were executed.\n\n")
+		for _, step := range steps {
+			result.WriteString(fmt.Sprintf("Step %d would call %s%s\n", step.number, step.tool.Name, planDryRunNote(step.tool, allowChanges)))
+		}
+		return result.String(), nil
+	}
+
+	var result strings.Builder
+	var outputs []string
+	for _, step := range steps {
+		result.WriteString(fmt.Sprintf("\nStep %d: %s\n", step.number, step.tool.Name))
+
+		stepArguments, err := generateStepArguments(ctx, userRequest, plan, step, outputs)
+		if err != nil {
+			result.WriteString(fmt.Sprintf("Stopped: %v\n", err))
+			return result.String(), nil
+		}
+		if _, ok := step.tool.InputSchema.Properties[util.DryRunArgument]; ok && !allowChanges {
+			stepArguments[util.DryRunArgument] = true
+		}
+
+		argumentsJSON, _ := json.Marshal(stepArguments)
+		result.WriteString(fmt.Sprintf("Arguments: %s\n", argumentsJSON))
+
+		if violations := util.ValidateArguments(step.tool.InputSchema, stepArguments); len(violations) > 0 {
+			result.WriteString(fmt.Sprintf("Stopped: invalid arguments: %s\n", strings.Join(violations, "; ")))
+			return result.String(), nil
+		}
+
+		callResult, err := callTool(ctx, s, step.tool.Name, stepArguments)
+		if err != nil {
+			result.WriteString(fmt.Sprintf("Stopped: %v\n", err))
+			return result.String(), nil
+		}
+
+		output := util.TruncateText(toolResultText(callResult), planStepOutputLimit)
+		result.WriteString("Result:\n" + output + "\n")
+		if callResult.IsError {
+			result.WriteString("Stopped: the step failed\n")
+			return result.String(), nil
+		}
+		outputs = append(outputs, fmt.Sprintf("Step %d (%s) output:\n%s", step.number, step.tool.Name, output))
+	}
+	return result.String(), nil
+}
+
+// parsePlanSteps extracts the numbered steps of a plan, reporting steps that
+// name a tool which is not registered or may not be used in plans
+func parsePlanSteps(plan string, registered []mcp.Tool) ([]planStep, []string) {
+	var steps []planStep
+	var problems []string
+	for _, line := range strings.Split(plan, "\n") {
+		match := planStepPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		number, _ := strconv.Atoi(match[1])
+
+		var tool *mcp.Tool
+		for i := range registered {
+			if registered[i].Name == match[2] {
+				tool = &registered[i]
+				break
+			}
+		}
+		if tool == nil {
+			problems = append(problems, fmt.Sprintf("step %d uses %s, which is not an available tool", number, match[2]))
+			continue
+		}
+		steps = append(steps, planStep{number: number, tool: *tool, text: strings.TrimSpace(line)})
+	}
+	return steps, problems
+}
+
+// isPlanExcludedTool reports whether a tool may not be used by executed plans:
+// plans cannot plan recursively or change which tools are enabled
+func isPlanExcludedTool(name string) bool {
+	return name == "tool_use_plan" || name == "tool_manager"
+}
+
+// planReadOnlyTools are the tools without dry_run that executed plans may run
+// when allow_changes is not set, because they only read
+var planReadOnlyTools = map[string]bool{
+	"RAG_memory_list_collections":  true,
+	"RAG_memory_list_models":       true,
+	"RAG_memory_search":            true,
+	"ai_web_search":                true,
+	"calendar_list_events":         true,
+	"chat_completion":              true,
+	"confluence_compare_versions":  true,
+	"confluence_get_labels":        true,
+	"confluence_get_page":          true,
+	"confluence_search":            true,
+	"confluence_validate_content":  true,
+	"deepseek_reasoning":           true,
+	"find_references":              true,
+	"gchat_list_spaces":            true,
+	"get_web_content":              true,
+	"gitlab_blame":                 true,
+	"gitlab_compare":               true,
+	"gitlab_diff_file":             true,
+	"gitlab_get_commit_details":    true,
+	"gitlab_get_file_content":      true,
+	"gitlab_get_mr_approval_rules": true,
+	"gitlab_get_mr_details":        true,
+	"gitlab_get_mr_file_diff":      true,
+	"gitlab_get_project":           true,
+	"gitlab_get_snippet":           true,
+	"gitlab_get_wiki_page":         true,
+	"gitlab_list_commits":          true,
+	"gitlab_list_group_users":      true,
+	"gitlab_list_mr_files":         true,
+	"gitlab_list_mrs":              true,
+	"gitlab_list_pipelines":        true,
+	"gitlab_list_projects":         true,
+	"gitlab_list_releases":         true,
+	"gitlab_list_user_events":      true,
+	"gitlab_list_wiki_pages":       true,
+	"gitlab_my_reviews":            true,
+	"gitlab_summarize_mr":          true,
+	"gitlab_wait_pipeline":         true,
+	"gmail_get_thread":             true,
+	"gmail_list_filters":           true,
+	"gmail_list_labels":            true,
+	"gmail_search":                 true,
+	"jira_get_issue":               true,
+	"jira_list_sprints":            true,
+	"jira_list_statuses":           true,
+	"jira_search_issue":            true,
+	"jira_sprint_burndown":         true,
+	"maps_directions":              true,
+	"maps_geocoding":               true,
+	"maps_location_search":         true,
+	"maps_place_autocomplete":      true,
+	"maps_place_details":           true,
+	"tool_describe":                true,
+	"web_search":                   true,
+	"youtube_get_video_details":    true,
+	"youtube_list_videos":          true,
+	"youtube_summarize":            true,
+	"youtube_transcript":           true,
+}
+
+// planStepNeedsChanges reports whether a tool may only run in an executed plan
+// with allow_changes: it neither supports dry_run nor is known to be read-only
+func planStepNeedsChanges(tool mcp.Tool) bool {
+	_, ok := tool.InputSchema.Properties[util.DryRunArgument]
+	return !ok && !planReadOnlyTools[tool.Name]
+}
+
+func planDryRunNote(tool mcp.Tool, allowChanges bool) string {
+	if _, ok := tool.InputSchema.Properties[util.DryRunArgument]; ok && !allowChanges {
+		return " (with dry_run, allow_changes is not set)"
+	}
+	return ""
+}
+
+// generateStepArguments asks the model for the arguments of one plan step as a
+// JSON object, given the tool's schema and the outputs of the previous steps
+func generateStepArguments(ctx context.Context, userRequest, plan string, step planStep, outputs []string) (map[string]interface{}, error) {
+	schema, err := json.Marshal(step.tool.InputSchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tool schema: %v", err)
+	}
+
+	previous := "None"
+	if len(outputs) > 0 {
+		previous = strings.Join(outputs, "\n\n")
+	}
+
+	prompt := fmt.Sprintf(`Request: %s
+
+Plan:
+%s
+
+Outputs of the previous steps:
+%s
+
+Give the arguments for step %d (%s) as a single JSON object matching this input schema, with no other text:
+%s`, userRequest, plan, previous, step.number, step.text, schema)
+
+	content, err := deepseekChat(ctx, []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: "You fill in tool call arguments. Answer only with a JSON object."},
+		{Role: openai.ChatMessageRoleUser, Content: prompt},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON arguments in the model response")
+	}
+	stepArguments := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &stepArguments); err != nil {
+		return nil, fmt.Errorf("invalid JSON arguments: %v", err)
+	}
+	return stepArguments, nil
+}
+
+// callTool invokes a registered tool through the server, so the call goes
+// through the same middleware as a client's
+func callTool(ctx context.Context, s *server.MCPServer, name string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	message, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      1,
+		"method":  string(mcp.MethodToolsCall),
+		"params": map[string]interface{}{
+			"name":      name,
+			"arguments": arguments,
 		},
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	switch response := s.HandleMessage(ctx, message).(type) {
+	case mcp.JSONRPCResponse:
+		result, ok := response.Result.(mcp.CallToolResult)
+		if !ok {
+			return nil, fmt.Errorf("unexpected tools/call result %T", response.Result)
+		}
+		return &result, nil
+	case mcp.JSONRPCError:
+		return nil, fmt.Errorf("%s", response.Error.Message)
+	default:
+		return nil, fmt.Errorf("unexpected tools/call response %T", response)
+	}
+}
+
+// toolResultText joins the text items of a tool result
+func toolResultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// deepseekChat sends messages to the configured Deepseek model and returns the answer
+func deepseekChat(ctx context.Context, messages []openai.ChatCompletionMessage) (string, error) {
+	cfg := config.Get()
+
 	client := services.DefaultDeepseekClient()
 	if client == nil {
-		return mcp.NewToolResultError("Failed to initialize client"), nil
+		return "", fmt.Errorf("failed to initialize client")
 	}
 
 	modelName := "deepseek-reasoner"
-	if useOllama {
+	if cfg.UseOllamaDeepseek {
 		modelName = "deepseek-r1:8b"
-	} else if useOpenRouter {
+	} else if cfg.UseOpenRouter {
 		modelName = "deepseek/deepseek-r1-distill-qwen-32b" // or any other model available on OpenRouter
 	}
 
 	resp, err := client.CreateChatCompletion(
-		context.Background(),
+		ctx,
 		openai.ChatCompletionRequest{
 			Model:       modelName,
 			Messages:    messages,
@@ -196,13 +699,12 @@ Output format:
 	)
 
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("API call failed: %v", err)), nil
+		return "", fmt.Errorf("API call failed: %v", err)
 	}
 
 	if len(resp.Choices) == 0 {
-		return mcp.NewToolResultError("No response from Deepseek"), nil
+		return "", fmt.Errorf("no response from Deepseek")
 	}
 
-	content := strings.TrimSpace(resp.Choices[0].Message.Content)
-	return mcp.NewToolResultText("📝 **Execution Plan:**\n" + content), nil
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
 }
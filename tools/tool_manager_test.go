@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestManageToolsConcurrentEnable(t *testing.T) {
+	before := enabledToolGroups()
+	t.Cleanup(func() {
+		runtimeToolGroupsMu.Lock()
+		runtimeToolGroups = before
+		runtimeToolGroupsMu.Unlock()
+	})
+
+	s := server.NewMCPServer("test", "1.0.0")
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("test-group-%d", i)
+			if _, err := manageTools(context.Background(), s, map[string]interface{}{"action": "enable", "tool_name": name}); err != nil {
+				t.Errorf("enable %s: %v", name, err)
+			}
+			if _, err := manageTools(context.Background(), s, map[string]interface{}{"action": "list"}); err != nil {
+				t.Errorf("list: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 20; i++ {
+		if name := fmt.Sprintf("test-group-%d", i); !contains(enabledToolGroups(), name) {
+			t.Errorf("%s was not enabled", name)
+		}
+	}
+
+	setToolGroupEnabled("test-group-0", false)
+	if contains(enabledToolGroups(), "test-group-0") {
+		t.Errorf("test-group-0 is still enabled after disabling it")
+	}
+}
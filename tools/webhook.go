@@ -0,0 +1,279 @@
+package tools
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/athapong/aio-mcp/services"
+	"github.com/ctreminiom/go-atlassian/pkg/infra/models"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxWebhookPayload bounds the size of an accepted webhook request body
+const maxWebhookPayload = 10 << 20
+
+// WebhookRule runs a pipeline of tool calls when a provider webhook matches its
+// source and event, then posts the result of the last step back as a reply.
+// Step arguments and replies are Go text/templates over webhookData.
+type WebhookRule struct {
+	// Source is the provider sending the webhook: gitlab or jira
+	Source string `json:"source"`
+	// Event is the GitLab object_kind (e.g. merge_request) or the Jira
+	// webhookEvent (e.g. jira:issue_created); empty or "*" matches any event
+	Event string        `json:"event"`
+	Steps []WebhookStep `json:"steps"`
+	Reply *WebhookReply `json:"reply,omitempty"`
+}
+
+// WebhookStep calls a tool with templated string arguments
+type WebhookStep struct {
+	Tool      string            `json:"tool"`
+	Arguments map[string]string `json:"arguments"`
+
+	arguments map[string]*template.Template
+}
+
+// WebhookReply posts the pipeline result either as a comment on a Jira issue or
+// by calling a tool, such as gchat_send_message or gitlab_create_MR_note
+type WebhookReply struct {
+	JiraIssue string `json:"jira_issue,omitempty"`
+	WebhookStep
+
+	jiraIssue *template.Template
+}
+
+// webhookData is what step and reply templates are rendered with
+type webhookData struct {
+	Source  string
+	Event   string
+	Payload map[string]interface{}
+	// Result is the text result of the previous step, Results those of every step so far
+	Result  string
+	Results []string
+}
+
+// LoadWebhookRules reads the webhook rules from a JSON file holding an array of
+// WebhookRule, parsing every template up front so mistakes fail startup
+func LoadWebhookRules(path string) ([]WebhookRule, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook config %s: %v", path, err)
+	}
+
+	var rules []WebhookRule
+	if err := json.Unmarshal(content, &rules); err != nil {
+		return nil, fmt.Errorf("invalid webhook config %s: %v", path, err)
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Source != "gitlab" && rule.Source != "jira" {
+			return nil, fmt.Errorf("invalid webhook rule %d: source must be gitlab or jira, got %q", i+1, rule.Source)
+		}
+		if len(rule.Steps) == 0 {
+			return nil, fmt.Errorf("invalid webhook rule %d: at least one step is required", i+1)
+		}
+		for j := range rule.Steps {
+			if err := rule.Steps[j].parse(); err != nil {
+				return nil, fmt.Errorf("invalid webhook rule %d step %d: %v", i+1, j+1, err)
+			}
+		}
+
+		if reply := rule.Reply; reply != nil {
+			if (reply.JiraIssue == "") == (reply.Tool == "") {
+				return nil, fmt.Errorf("invalid webhook rule %d reply: set exactly one of jira_issue and tool", i+1)
+			}
+			if reply.JiraIssue != "" {
+				if reply.jiraIssue, err = parseWebhookTemplate("jira_issue", reply.JiraIssue); err != nil {
+					return nil, fmt.Errorf("invalid webhook rule %d reply: %v", i+1, err)
+				}
+			} else if err := reply.parse(); err != nil {
+				return nil, fmt.Errorf("invalid webhook rule %d reply: %v", i+1, err)
+			}
+		}
+	}
+	return rules, nil
+}
+
+func (step *WebhookStep) parse() error {
+	if step.Tool == "" {
+		return fmt.Errorf("tool is required")
+	}
+	step.arguments = make(map[string]*template.Template, len(step.Arguments))
+	for name, text := range step.Arguments {
+		tmpl, err := parseWebhookTemplate(name, text)
+		if err != nil {
+			return err
+		}
+		step.arguments[name] = tmpl
+	}
+	return nil
+}
+
+func parseWebhookTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(template.FuncMap{"join": strings.Join}).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template for %s: %v", name, err)
+	}
+	return tmpl, nil
+}
+
+func renderWebhookTemplate(tmpl *template.Template, data webhookData) (string, error) {
+	var result strings.Builder
+	if err := tmpl.Execute(&result, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %v", tmpl.Name(), err)
+	}
+	return result.String(), nil
+}
+
+// NewWebhookHandler serves POST /webhooks/gitlab and /webhooks/jira. Requests
+// must prove they know secret, and each matching rule runs in the background so
+// the provider gets a 202 without waiting for the tools.
+func NewWebhookHandler(s *server.MCPServer, rules []WebhookRule, secret string) http.Handler {
+	mux := http.NewServeMux()
+	for _, source := range []string{"gitlab", "jira"} {
+		source := source
+		mux.HandleFunc("/webhooks/"+source, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookPayload))
+			if err != nil {
+				http.Error(w, "Failed to read body", http.StatusBadRequest)
+				return
+			}
+			if !verifyWebhookSecret(r, body, secret) {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			var payload map[string]interface{}
+			if err := json.Unmarshal(body, &payload); err != nil {
+				http.Error(w, "Invalid JSON payload", http.StatusBadRequest)
+				return
+			}
+
+			event := webhookEvent(source, payload)
+			matched := 0
+			for _, rule := range rules {
+				if rule.Source != source || (rule.Event != "" && rule.Event != "*" && rule.Event != event) {
+					continue
+				}
+				matched++
+				go func(rule WebhookRule) {
+					if err := runWebhookRule(context.Background(), s, rule, webhookData{Source: source, Event: event, Payload: payload}); err != nil {
+						slog.Error("Webhook rule failed", "source", source, "event", event, "error", err)
+					}
+				}(rule)
+			}
+
+			w.WriteHeader(http.StatusAccepted)
+			fmt.Fprintf(w, "%d rule(s) triggered for %s event %s\n", matched, source, event)
+		})
+	}
+	return mux
+}
+
+// verifyWebhookSecret accepts the secret as GitLab's X-Gitlab-Token header, as
+// an X-Hub-Signature HMAC-SHA256 of the body (Jira Cloud webhook secrets), or as
+// a secret query parameter for senders that can do neither
+func verifyWebhookSecret(r *http.Request, body []byte, secret string) bool {
+	if token := r.Header.Get("X-Gitlab-Token"); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+	}
+
+	if signature, ok := strings.CutPrefix(r.Header.Get("X-Hub-Signature"), "sha256="); ok {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		expected := hex.EncodeToString(mac.Sum(nil))
+		return hmac.Equal([]byte(signature), []byte(expected))
+	}
+
+	if token := r.URL.Query().Get("secret"); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+	}
+	return false
+}
+
+// webhookEvent names the event of a payload the way rules match it
+func webhookEvent(source string, payload map[string]interface{}) string {
+	key := "object_kind"
+	if source == "jira" {
+		key = "webhookEvent"
+	}
+	event, _ := payload[key].(string)
+	return event
+}
+
+// runWebhookRule calls the rule's steps in order, each seeing the result of the
+// previous one, and posts the final result as the rule's reply
+func runWebhookRule(ctx context.Context, s *server.MCPServer, rule WebhookRule, data webhookData) error {
+	for i, step := range rule.Steps {
+		result, err := runWebhookStep(ctx, s, step, data)
+		if err != nil {
+			return fmt.Errorf("step %d (%s): %v", i+1, step.Tool, err)
+		}
+		data.Result = result
+		data.Results = append(data.Results, result)
+	}
+
+	reply := rule.Reply
+	if reply == nil {
+		return nil
+	}
+
+	if reply.jiraIssue != nil {
+		issueKey, err := renderWebhookTemplate(reply.jiraIssue, data)
+		if err != nil {
+			return err
+		}
+		_, response, err := services.JiraClient().Issue.Comment.Add(ctx, issueKey, &models.CommentPayloadSchemeV2{Body: data.Result}, nil)
+		if err != nil {
+			if response != nil {
+				return fmt.Errorf("failed to comment on %s: %s", issueKey, response.Bytes.String())
+			}
+			return fmt.Errorf("failed to comment on %s: %v", issueKey, err)
+		}
+		return nil
+	}
+
+	if _, err := runWebhookStep(ctx, s, reply.WebhookStep, data); err != nil {
+		return fmt.Errorf("reply (%s): %v", reply.Tool, err)
+	}
+	return nil
+}
+
+// runWebhookStep renders the step's arguments and calls its tool, returning the text result
+func runWebhookStep(ctx context.Context, s *server.MCPServer, step WebhookStep, data webhookData) (string, error) {
+	arguments := make(map[string]interface{}, len(step.arguments))
+	for name, tmpl := range step.arguments {
+		value, err := renderWebhookTemplate(tmpl, data)
+		if err != nil {
+			return "", err
+		}
+		arguments[name] = value
+	}
+
+	result, err := callTool(ctx, s, step.Tool, arguments)
+	if err != nil {
+		return "", err
+	}
+	text := toolResultText(result)
+	if result.IsError {
+		return "", fmt.Errorf("%s", text)
+	}
+	return text, nil
+}
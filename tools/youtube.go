@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"html"
@@ -10,10 +11,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/athapong/aio-mcp/config"
 	"github.com/athapong/aio-mcp/services"
 	"github.com/athapong/aio-mcp/util"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/sashabaranov/go-openai"
 )
 
 const (
@@ -30,6 +33,15 @@ func RegisterYouTubeTool(s *server.MCPServer) {
 	)
 
 	s.AddTool(tool, util.ErrorGuard(util.AdaptLegacyHandler(youtubeTranscriptHandler)))
+
+	summarizeTool := mcp.NewTool("youtube_summarize",
+		mcp.WithDescription("Summarize a YouTube video from its transcript, returning key points with timestamps"),
+		mcp.WithString("video_id", mcp.Required(), mcp.Description("YouTube video ID or URL")),
+		mcp.WithString("length", mcp.DefaultString("medium"), mcp.Description("Summary length: short, medium or long")),
+		mcp.WithString("model", mcp.Description("Chat model to use, defaults to YOUTUBE_SUMMARY_MODEL or gpt-4o-mini")),
+	)
+
+	s.AddTool(summarizeTool, util.ErrorGuard(youtubeSummarizeHandler))
 }
 
 func youtubeTranscriptHandler(arguments map[string]interface{}) (*mcp.CallToolResult, error) {
@@ -45,22 +57,116 @@ func youtubeTranscriptHandler(arguments map[string]interface{}) (*mcp.CallToolRe
 		return nil, fmt.Errorf("failed to fetch transcript: %v", err)
 	}
 
-	// Build result string
-	var builder strings.Builder
-	builder.WriteString(fmt.Sprintf("Title: %s\n\n", videoTitle))
+	return mcp.NewToolResultText(fmt.Sprintf("Title: %s\n\n", videoTitle) + strings.Join(formatTranscriptLines(transcripts), "\n") + "\n"), nil
+}
 
+// formatTranscriptLines renders each transcript entry as a "[HH:MM:SS] text" line
+func formatTranscriptLines(transcripts []TranscriptResponse) []string {
+	lines := make([]string, 0, len(transcripts))
 	for _, transcript := range transcripts {
-		// Decode HTML entities in the text
-		decodedText := decodeHTML(transcript.Text)
-		// Format timestamp in [HH:MM:SS] format
-		timestamp := formatTimestamp(transcript.Offset)
+		// Decode HTML entities in the text and prefix it with the timestamp
+		lines = append(lines, formatTimestamp(transcript.Offset)+decodeHTML(transcript.Text))
+	}
+	return lines
+}
+
+// transcriptChunkSize is the approximate number of characters of transcript sent per summarization request
+const transcriptChunkSize = 12000
+
+var summaryLengths = map[string]string{
+	"short":  "3-5 key points",
+	"medium": "5-10 key points",
+	"long":   "10-20 key points with a short explanation for each",
+}
+
+func youtubeSummarizeHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	videoID, ok := arguments["video_id"].(string)
+	if !ok {
+		return nil, fmt.Errorf("video_id argument is required")
+	}
+
+	length, _ := arguments["length"].(string)
+	if length == "" {
+		length = "medium"
+	}
+	lengthInstruction, ok := summaryLengths[length]
+	if !ok {
+		return nil, fmt.Errorf("invalid length %q, use short, medium or long", length)
+	}
+
+	model, _ := arguments["model"].(string)
+	if model == "" {
+		model = config.Get().YouTubeSummaryModel
+	}
+
+	transcripts, videoTitle, err := FetchTranscript(videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transcript: %v", err)
+	}
+
+	// Split the transcript on line boundaries so timestamps stay attached to their text
+	var (
+		chunks  []string
+		current strings.Builder
+	)
+	for _, line := range formatTranscriptLines(transcripts) {
+		if current.Len() > 0 && current.Len()+len(line) > transcriptChunkSize {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("transcript is empty")
+	}
+
+	// Map: summarize each chunk independently
+	partials := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		partial, err := summarizeText(ctx, model, fmt.Sprintf(
+			"Summarize part %d of %d of the transcript of the YouTube video %q. List the key points as bullets, each starting with the [HH:MM:SS] timestamp where it is discussed.\n\n%s",
+			i+1, len(chunks), videoTitle, chunk))
+		if err != nil {
+			return nil, err
+		}
+		partials = append(partials, partial)
+	}
+
+	// Reduce: combine the partial summaries into the final structured summary
+	summary, err := summarizeText(ctx, model, fmt.Sprintf(
+		"Combine these partial summaries of the YouTube video %q into one structured summary in Markdown with a one-paragraph overview followed by a \"Key Points\" section of %s. Keep the [HH:MM:SS] timestamps.\n\n%s",
+		videoTitle, lengthInstruction, strings.Join(partials, "\n\n")))
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Title: %s\n\n%s", videoTitle, summary)), nil
+}
+
+func summarizeText(ctx context.Context, model, prompt string) (string, error) {
+	resp, err := services.DefaultOpenAIClient().CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: model,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: prompt,
+			},
+		},
+	})
+	if err != nil {
+		return "", openAIError(err, "failed to summarize")
+	}
 
-		builder.WriteString(timestamp)
-		builder.WriteString(decodedText)
-		builder.WriteString("\n")
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no response from model")
 	}
 
-	return mcp.NewToolResultText(builder.String()), nil
+	return resp.Choices[0].Message.Content, nil
 }
 
 // Error types
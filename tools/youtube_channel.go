@@ -3,10 +3,11 @@ package tools
 import (
 	"context"
 	"fmt"
-	"os"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/athapong/aio-mcp/config"
 	"github.com/athapong/aio-mcp/services"
 	"github.com/athapong/aio-mcp/util"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -24,8 +25,9 @@ func RegisterYouTubeChannelTools(s *server.MCPServer) {
 		mcp.WithString("description", mcp.Required(), mcp.Description("New description of the video")),
 		mcp.WithString("keywords", mcp.Required(), mcp.Description("Comma-separated list of keywords for the video")),
 		mcp.WithString("category", mcp.Required(), mcp.Description("Category ID for the video. See https://developers.google.com/youtube/v3/docs/videoCategories/list for more information.")),
+		util.WithDryRun(),
 	)
-	s.AddTool(updateVideoTool, util.ErrorGuard(util.AdaptLegacyHandler(youtubeUpdateVideoHandler)))
+	s.AddTool(updateVideoTool, util.ErrorGuard(util.DryRunGuard("update YouTube video", "PUT /youtube/v3/videos", util.AdaptLegacyHandler(youtubeUpdateVideoHandler))))
 
 	getVideoDetailsTool := mcp.NewTool("youtube_get_video_details",
 		mcp.WithDescription("Get details (title, description, ...) for a specific video"),
@@ -37,7 +39,11 @@ func RegisterYouTubeChannelTools(s *server.MCPServer) {
 	listMyChannelsTool := mcp.NewTool("youtube_list_videos",
 		mcp.WithDescription("List YouTube videos managed by the user"),
 		mcp.WithString("channel_id", mcp.Required(), mcp.Description("ID of the channel to list videos for")),
-		mcp.WithNumber("max_results", mcp.Required(), mcp.Description("Maximum number of videos to return")),
+		mcp.WithNumber("max_results", mcp.DefaultNumber(10), mcp.Description("Maximum number of videos to return (1-50)")),
+		mcp.WithString("published_after", mcp.Description("Only return videos published after this time (RFC3339, e.g. 2024-01-01T00:00:00Z)")),
+		mcp.WithString("order", mcp.DefaultString("date"), mcp.Description("Order of results: date or viewCount")),
+		mcp.WithString("page_token", mcp.Description("Page token from a previous call to fetch the next page")),
+		mcp.WithString("since", mcp.Description("Only return videos published after this time, as RFC3339 or a duration ago such as 24h. Uses the cheaper uploads listing and stops at the first older video; requires order date")),
 	)
 	s.AddTool(listMyChannelsTool, util.ErrorGuard(util.AdaptLegacyHandler(youtubeListVideosHandler)))
 
@@ -46,12 +52,12 @@ func RegisterYouTubeChannelTools(s *server.MCPServer) {
 var youtubeService = sync.OnceValue(func() *youtube.Service {
 	ctx := context.Background()
 
-	tokenFile := os.Getenv("GOOGLE_TOKEN_FILE")
+	tokenFile := config.Get().GoogleTokenFile
 	if tokenFile == "" {
 		panic("GOOGLE_TOKEN_FILE environment variable must be set")
 	}
 
-	credentialsFile := os.Getenv("GOOGLE_CREDENTIALS_FILE")
+	credentialsFile := config.Get().GoogleCredentialsFile
 	if credentialsFile == "" {
 		panic("GOOGLE_CREDENTIALS_FILE environment variable must be set")
 	}
@@ -155,52 +161,158 @@ func youtubeListVideosHandler(arguments map[string]interface{}) (*mcp.CallToolRe
 	} else {
 		maxResults = 10
 	}
+	if maxResults < 1 || maxResults > 50 {
+		return mcp.NewToolResultError("max_results must be between 1 and 50"), nil
+	}
 
-	// Get the channel's uploads playlist ID
-	channelsListCall := youtubeService().Channels.List([]string{"contentDetails"}).
-		Id(channelID)
-	channelsListResponse, err := channelsListCall.Do()
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to get channel details: %v", err)), nil
+	order := "date"
+	if orderArg, ok := arguments["order"].(string); ok && orderArg != "" {
+		order = orderArg
+	}
+	if order != "date" && order != "viewCount" {
+		return mcp.NewToolResultError("order must be date or viewCount"), nil
 	}
 
-	if len(channelsListResponse.Items) == 0 {
-		return mcp.NewToolResultError("channel not found"), nil
+	publishedAfter, _ := arguments["published_after"].(string)
+	if publishedAfter != "" {
+		if _, err := time.Parse(time.RFC3339, publishedAfter); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid published_after, expected RFC3339: %v", err)), nil
+		}
 	}
+	pageToken, _ := arguments["page_token"].(string)
+
+	var since time.Time
+	if sinceArg, ok := arguments["since"].(string); ok && sinceArg != "" {
+		if publishedAfter != "" || order != "date" {
+			return mcp.NewToolResultError("since cannot be combined with published_after or order viewCount"), nil
+		}
+		parsed, err := parseSince(sinceArg)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		since = parsed
+	}
+
+	var (
+		videoIDs      []string
+		nextPageToken string
+	)
 
-	uploadsPlaylistID := channelsListResponse.Items[0].ContentDetails.RelatedPlaylists.Uploads
+	if publishedAfter != "" || order != "date" {
+		// Filtering and ordering require the search API
+		searchCall := youtubeService().Search.List([]string{"id"}).
+			ChannelId(channelID).
+			Type("video").
+			Order(order).
+			MaxResults(maxResults)
+		if publishedAfter != "" {
+			searchCall = searchCall.PublishedAfter(publishedAfter)
+		}
+		if pageToken != "" {
+			searchCall = searchCall.PageToken(pageToken)
+		}
+		searchResponse, err := searchCall.Do()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to search videos: %v", err)), nil
+		}
+
+		for _, item := range searchResponse.Items {
+			videoIDs = append(videoIDs, item.Id.VideoId)
+		}
+		nextPageToken = searchResponse.NextPageToken
+	} else {
+		// Get the channel's uploads playlist ID
+		channelsListCall := youtubeService().Channels.List([]string{"contentDetails"}).
+			Id(channelID)
+		channelsListResponse, err := channelsListCall.Do()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get channel details: %v", err)), nil
+		}
+
+		if len(channelsListResponse.Items) == 0 {
+			return mcp.NewToolResultError("channel not found"), nil
+		}
 
-	// List videos in the uploads playlist
-	playlistItemsListCall := youtubeService().PlaylistItems.List([]string{"snippet"}).
-		PlaylistId(uploadsPlaylistID).
-		MaxResults(maxResults)
-	playlistItemsListResponse, err := playlistItemsListCall.Do()
+		uploadsPlaylistID := channelsListResponse.Items[0].ContentDetails.RelatedPlaylists.Uploads
+
+		// List videos in the uploads playlist
+		playlistItemsListCall := youtubeService().PlaylistItems.List([]string{"snippet", "contentDetails"}).
+			PlaylistId(uploadsPlaylistID).
+			MaxResults(maxResults)
+		if pageToken != "" {
+			playlistItemsListCall = playlistItemsListCall.PageToken(pageToken)
+		}
+		playlistItemsListResponse, err := playlistItemsListCall.Do()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list videos: %v", err)), nil
+		}
+
+		nextPageToken = playlistItemsListResponse.NextPageToken
+		for _, playlistItem := range playlistItemsListResponse.Items {
+			// Uploads are listed newest first, so the first older video ends the listing
+			if !since.IsZero() {
+				published, err := time.Parse(time.RFC3339, playlistItem.ContentDetails.VideoPublishedAt)
+				if err == nil && !published.After(since) {
+					nextPageToken = ""
+					break
+				}
+			}
+			videoIDs = append(videoIDs, playlistItem.Snippet.ResourceId.VideoId)
+		}
+	}
+
+	if len(videoIDs) == 0 {
+		if !since.IsZero() {
+			return mcp.NewToolResultText(fmt.Sprintf("No videos published since %s\nHas More: false\n", since.Format(time.RFC3339))), nil
+		}
+		return mcp.NewToolResultText("No videos found"), nil
+	}
+
+	videoDetailsCall := youtubeService().Videos.List([]string{"snippet", "contentDetails", "statistics"}).
+		Id(videoIDs...)
+	videoDetailsResponse, err := videoDetailsCall.Do()
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to list videos: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get video details: %v", err)), nil
+	}
+
+	videos := make(map[string]*youtube.Video, len(videoDetailsResponse.Items))
+	for _, video := range videoDetailsResponse.Items {
+		videos[video.Id] = video
 	}
 
 	var result string
-	for _, playlistItem := range playlistItemsListResponse.Items {
-		videoID := playlistItem.Snippet.ResourceId.VideoId
-		videoDetailsCall := youtubeService().Videos.List([]string{"snippet", "statistics"}).
-			Id(videoID)
-		videoDetailsResponse, err := videoDetailsCall.Do()
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to get video details: %v", err)), nil
+	for _, videoID := range videoIDs {
+		video, ok := videos[videoID]
+		if !ok {
+			continue
 		}
+		result += fmt.Sprintf("Video ID: %s\n", video.Id)
+		result += fmt.Sprintf("Title: %s\n", video.Snippet.Title)
+		result += fmt.Sprintf("Published At: %s\n", video.Snippet.PublishedAt)
+		result += fmt.Sprintf("Duration: %s\n", video.ContentDetails.Duration)
+		result += fmt.Sprintf("View Count: %d\n", video.Statistics.ViewCount)
+		result += fmt.Sprintf("Like Count: %d\n", video.Statistics.LikeCount)
+		result += fmt.Sprintf("Comment Count: %d\n", video.Statistics.CommentCount)
+		result += fmt.Sprintf("Description: %s\n", video.Snippet.Description)
+		result += "-------------------\n"
+	}
 
-		if len(videoDetailsResponse.Items) > 0 {
-			video := videoDetailsResponse.Items[0]
-			result += fmt.Sprintf("Video ID: %s\n", video.Id)
-			result += fmt.Sprintf("Published At: %s\n", video.Snippet.PublishedAt)
-			result += fmt.Sprintf("View Count: %d\n", video.Statistics.ViewCount)
-			result += fmt.Sprintf("Like Count: %d\n", video.Statistics.LikeCount)
-			result += fmt.Sprintf("Comment Count: %d\n", video.Statistics.CommentCount)
-			result += fmt.Sprintf("Title: %s\n", video.Snippet.Title)
-			result += fmt.Sprintf("Description: %s\n", video.Snippet.Description)
-			result += "-------------------\n"
-		}
+	if nextPageToken != "" {
+		result += fmt.Sprintf("Next Page Token: %s\n", nextPageToken)
 	}
+	result += fmt.Sprintf("Has More: %t\n", nextPageToken != "")
 
 	return mcp.NewToolResultText(result), nil
 }
+
+// parseSince parses an RFC3339 time or a duration before now, such as 24h
+func parseSince(value string) (time.Time, error) {
+	if since, err := time.Parse(time.RFC3339, value); err == nil {
+		return since, nil
+	}
+	ago, err := time.ParseDuration(value)
+	if err != nil || ago < 0 {
+		return time.Time{}, fmt.Errorf("invalid since %q: expected RFC3339 or a duration such as 24h", value)
+	}
+	return time.Now().Add(-ago), nil
+}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/athapong/aio-mcp/util"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// transcriptSegmentSize is the approximate number of characters of transcript
+// indexed per point. It stays below the RAG chunk size so every segment is
+// embedded whole and keeps its own timestamp range.
+const transcriptSegmentSize = 1500
+
+// RegisterYouTubeRagTool registers the tool that indexes YouTube transcripts into
+// RAG memory. It needs both the youtube and rag tool groups.
+func RegisterYouTubeRagTool(s *server.MCPServer) {
+	indexTranscriptTool := mcp.NewTool("youtube_index_transcript",
+		mcp.WithDescription("Index the transcript of a YouTube video into a RAG memory collection in timestamped segments, so searches return the moment in the video with a link that jumps to it"),
+		mcp.WithString("video_id", mcp.Required(), mcp.Description("YouTube video ID or URL")),
+		withCollection("Memory collection name"),
+		mcp.WithString("model", mcp.Description("Embedding model to use (default: "+defaultEmbeddingModel()+")")),
+		util.WithDryRun(),
+	)
+	s.AddTool(indexTranscriptTool, util.ErrorGuard(util.DryRunGuard("index YouTube transcript", "PUT /collections/{collection}/points", youtubeIndexTranscriptHandler)))
+}
+
+// transcriptSegment is a run of consecutive transcript lines indexed as one point
+type transcriptSegment struct {
+	text  strings.Builder
+	start float64
+	end   float64
+}
+
+func youtubeIndexTranscriptHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.Params.Arguments
+	videoArg, _ := arguments["video_id"].(string)
+	videoID, err := retrieveVideoId(videoArg)
+	if err != nil {
+		return nil, util.WrapToolError(util.ErrCodeInvalidArgument, err, "invalid video_id")
+	}
+
+	collection, err := collectionArgument(arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	modelStr, _, err := resolveEmbeddingModel(arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	transcripts, videoTitle, err := FetchTranscript(videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transcript: %v", err)
+	}
+
+	// Split the transcript on line boundaries so each segment knows where it starts and ends
+	var segments []*transcriptSegment
+	lines := formatTranscriptLines(transcripts)
+	for i, transcript := range transcripts {
+		line := lines[i]
+		if len(segments) == 0 || segments[len(segments)-1].text.Len()+len(line) > transcriptSegmentSize {
+			segments = append(segments, &transcriptSegment{start: transcript.Offset})
+		}
+		segment := segments[len(segments)-1]
+		segment.text.WriteString(line)
+		segment.text.WriteString("\n")
+		segment.end = transcript.Offset + transcript.Duration
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("transcript is empty")
+	}
+
+	// Segments are keyed as youtube:<video_id>#<segment> so re-indexing replaces earlier points
+	var failures []string
+	for i, segment := range segments {
+		jumpURL := fmt.Sprintf("https://www.youtube.com/watch?v=%s&t=%ds", videoID, int(segment.start))
+		metadata := map[string]any{
+			"source":        "youtube",
+			"video_id":      videoID,
+			"title":         videoTitle,
+			"start_seconds": segment.start,
+			"end_seconds":   segment.end,
+			"timestamp":     strings.Trim(formatTimestamp(segment.start), "[] ") + "-" + strings.Trim(formatTimestamp(segment.end), "[] "),
+			"url":           jumpURL,
+		}
+		content := fmt.Sprintf("Video: %s\n%s", videoTitle, segment.text.String())
+
+		if _, err := upsertContent(ctx, collection, fmt.Sprintf("youtube:%s#%d", videoID, i), content, modelStr, metadata, indexOptions{}); err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			failures = append(failures, fmt.Sprintf("segment %d (%s): %v", i, metadata["timestamp"], err))
+		}
+	}
+
+	if len(failures) == len(segments) {
+		return nil, util.NewToolError(util.ErrCodeUpstream, "failed to index any of %d segments:\n%s", len(segments), strings.Join(failures, "\n"))
+	}
+
+	result := fmt.Sprintf("Indexed %d of %d transcript segments of %q into collection %s with model %s", len(segments)-len(failures), len(segments), videoTitle, collection, modelStr)
+	if len(failures) > 0 {
+		result += "\n\nFailed segments:\n" + strings.Join(failures, "\n")
+	}
+	return mcp.NewToolResultText(result), nil
+}
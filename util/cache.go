@@ -0,0 +1,131 @@
+package util
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type cacheEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+	lastUsed  uint64
+}
+
+// Cache is a small in-memory LRU cache with a per-entry TTL and a maximum size.
+// When the cache is full the least recently used entry is evicted; both Get and
+// Set count as a use.
+type Cache[K comparable, V any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	maxSize int
+	items   map[K]*cacheEntry[V]
+	// clock orders uses of entries, advancing on every Get hit and Set
+	clock uint64
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// NewCache creates a cache whose entries live for ttl. A maxSize of 0 or less
+// means the cache is unbounded.
+func NewCache[K comparable, V any](ttl time.Duration, maxSize int) *Cache[K, V] {
+	return &Cache[K, V]{
+		ttl:     ttl,
+		maxSize: maxSize,
+		items:   make(map[K]*cacheEntry[V]),
+	}
+}
+
+// Get returns the cached value for key if present and not expired, marking it
+// as recently used
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.items[key]
+	if ok && time.Now().Before(entry.expiresAt) {
+		c.clock++
+		entry.lastUsed = c.clock
+		c.hits.Add(1)
+		return entry.value, true
+	}
+	if ok {
+		delete(c.items, key)
+	}
+
+	c.misses.Add(1)
+	var zero V
+	return zero, false
+}
+
+// Set stores value under key, evicting an entry if the cache is full
+func (c *Cache[K, V]) Set(key K, value V) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.items[key]; !exists && c.maxSize > 0 && len(c.items) >= c.maxSize {
+		c.evict()
+	}
+
+	c.clock++
+	c.items[key] = &cacheEntry[V]{
+		value:     value,
+		expiresAt: time.Now().Add(c.ttl),
+		lastUsed:  c.clock,
+	}
+}
+
+// Delete removes key from the cache
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, key)
+}
+
+// Len returns the number of entries currently held, including expired ones
+// that have not been evicted yet
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// Stats returns the number of cache hits and misses since creation
+func (c *Cache[K, V]) Stats() (hits, misses uint64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+// evict drops expired entries, or the least recently used entry if none
+// expired. Callers must hold c.mu.
+func (c *Cache[K, V]) evict() {
+	now := time.Now()
+	var (
+		lruKey  K
+		lruUsed uint64
+		found   bool
+	)
+
+	for key, entry := range c.items {
+		if !now.Before(entry.expiresAt) {
+			delete(c.items, key)
+			continue
+		}
+		if !found || entry.lastUsed < lruUsed {
+			lruKey = key
+			lruUsed = entry.lastUsed
+			found = true
+		}
+	}
+
+	if len(c.items) < c.maxSize {
+		return
+	}
+	if found {
+		delete(c.items, lruKey)
+	}
+}
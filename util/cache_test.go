@@ -0,0 +1,94 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheHitAndMiss(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, 10)
+
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("Get on an empty cache reported a hit")
+	}
+	cache.Set("a", 1)
+	if value, ok := cache.Get("a"); !ok || value != 1 {
+		t.Fatalf("Get(a) = %d, %v, want 1, true", value, ok)
+	}
+
+	if hits, misses := cache.Stats(); hits != 1 || misses != 1 {
+		t.Fatalf("Stats() = %d hits, %d misses, want 1, 1", hits, misses)
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	cache := NewCache[string, int](20*time.Millisecond, 10)
+	cache.Set("a", 1)
+
+	time.Sleep(40 * time.Millisecond)
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("Get returned an expired entry")
+	}
+	if cache.Len() != 0 {
+		t.Fatalf("Len() = %d after reading an expired entry, want 0", cache.Len())
+	}
+}
+
+func TestCacheDisabledWithoutTTL(t *testing.T) {
+	cache := NewCache[string, int](0, 10)
+	cache.Set("a", 1)
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("a cache with no TTL stored an entry")
+	}
+}
+
+func TestCacheEvictsOldestUnusedEntry(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, 2)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+
+	if cache.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", cache.Len())
+	}
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("oldest entry a was not evicted")
+	}
+	for _, key := range []string{"b", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Fatalf("entry %s was evicted", key)
+		}
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, 2)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	// Reading a makes b the least recently used entry, although a was stored first
+	cache.Get("a")
+	cache.Set("c", 3)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Fatal("least recently used entry b was not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Fatalf("entry %s was evicted", key)
+		}
+	}
+}
+
+func TestCacheOverwriteDoesNotEvict(t *testing.T) {
+	cache := NewCache[string, int](time.Minute, 2)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("a", 3)
+
+	if value, ok := cache.Get("a"); !ok || value != 3 {
+		t.Fatalf("Get(a) = %d, %v, want 3, true", value, ok)
+	}
+	if _, ok := cache.Get("b"); !ok {
+		t.Fatal("overwriting a key evicted another entry")
+	}
+}
@@ -0,0 +1,67 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DryRunArgument is the optional argument that makes a mutating tool only report
+// what it would do
+const DryRunArgument = "dry_run"
+
+// WithDryRun adds the dry_run argument to a mutating tool definition
+func WithDryRun() mcp.ToolOption {
+	return mcp.WithBoolean(DryRunArgument, mcp.Description("Validate the arguments and report what would be done without making any change"))
+}
+
+// IsDryRun reports whether the dry_run argument is set
+func IsDryRun(arguments map[string]interface{}) bool {
+	dryRun, _ := arguments[DryRunArgument].(bool)
+	return dryRun
+}
+
+// DryRunResult describes the action a mutating tool would take. Handlers return
+// it after validating their arguments and before calling the upstream API.
+func DryRunResult(action, endpoint string, payload interface{}) *mcp.CallToolResult {
+	var result strings.Builder
+	result.WriteString("Dry run: no changes were made.\n\n")
+	result.WriteString(fmt.Sprintf("Action: %s\n", action))
+	result.WriteString(fmt.Sprintf("Endpoint: %s\n", endpoint))
+
+	if payload != nil {
+		payloadJSON, err := json.MarshalIndent(payload, "", "  ")
+		if err != nil {
+			payloadJSON = []byte(fmt.Sprintf("%+v", payload))
+		}
+		result.WriteString("Payload:\n")
+		result.Write(payloadJSON)
+		result.WriteString("\n")
+	}
+
+	return mcp.NewToolResultText(result.String())
+}
+
+// DryRunGuard wraps the handler of a mutating tool that has no dry run of its
+// own. With dry_run set it reports the action with the call's arguments as the
+// payload instead of calling the handler. Pair it with WithDryRun in the tool
+// definition.
+func DryRunGuard(action, endpoint string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !IsDryRun(request.Params.Arguments) {
+			return handler(ctx, request)
+		}
+
+		payload := make(map[string]interface{}, len(request.Params.Arguments))
+		for name, value := range request.Params.Arguments {
+			if name != DryRunArgument {
+				payload[name] = value
+			}
+		}
+		return DryRunResult(action, endpoint, payload), nil
+	}
+}
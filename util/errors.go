@@ -0,0 +1,78 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrorCode classifies a tool error so clients can decide how to handle it
+type ErrorCode string
+
+const (
+	ErrCodeConfigMissing   ErrorCode = "CONFIG_MISSING"
+	ErrCodeInvalidArgument ErrorCode = "INVALID_ARGUMENT"
+	ErrCodeNotFound        ErrorCode = "NOT_FOUND"
+	ErrCodeUnauthorized    ErrorCode = "UNAUTHORIZED"
+	ErrCodeRateLimited     ErrorCode = "RATE_LIMITED"
+	ErrCodeUpstream        ErrorCode = "UPSTREAM_ERROR"
+)
+
+// ToolError is an error with a code that ErrorGuard surfaces in the tool result
+type ToolError struct {
+	Code    ErrorCode
+	Message string
+	Err     error
+}
+
+func (e *ToolError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *ToolError) Unwrap() error {
+	return e.Err
+}
+
+// NewToolError creates a ToolError with the given code and formatted message
+func NewToolError(code ErrorCode, format string, args ...interface{}) *ToolError {
+	return &ToolError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// WrapToolError wraps err in a ToolError with the given code and message
+func WrapToolError(code ErrorCode, err error, message string) *ToolError {
+	return &ToolError{Code: code, Message: message, Err: err}
+}
+
+// UpstreamError wraps an error from an upstream API, deriving the code from the
+// HTTP status code of the response when one is available
+func UpstreamError(err error, statusCode int, message string) *ToolError {
+	return WrapToolError(CodeFromStatus(statusCode), err, message)
+}
+
+// CodeFromStatus maps an HTTP status code to an ErrorCode
+func CodeFromStatus(statusCode int) ErrorCode {
+	switch statusCode {
+	case http.StatusNotFound:
+		return ErrCodeNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrCodeUnauthorized
+	case http.StatusTooManyRequests:
+		return ErrCodeRateLimited
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ErrCodeInvalidArgument
+	default:
+		return ErrCodeUpstream
+	}
+}
+
+// ErrorCodeOf returns the code of the first ToolError in err's chain, if any
+func ErrorCodeOf(err error) (ErrorCode, bool) {
+	var toolErr *ToolError
+	if errors.As(err, &toolErr) {
+		return toolErr.Code, true
+	}
+	return "", false
+}
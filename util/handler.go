@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"unicode/utf8"
+
+	"github.com/athapong/aio-mcp/config"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -39,8 +42,64 @@ func ErrorGuard(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
 		}()
 		result, err = handler(ctx, request)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Error: %v", err)), nil
+			return errorResult(err), nil
+		}
+		return limitResultSize(result, config.Get().MaxResultBytes), nil
+	}
+}
+
+// limitResultSize truncates the text of a result to maxBytes in total, ending
+// with a marker that tells the caller to narrow the query. Images and other
+// non-text content are left as they are. A maxBytes of 0 or less disables the limit.
+func limitResultSize(result *mcp.CallToolResult, maxBytes int) *mcp.CallToolResult {
+	if result == nil || maxBytes <= 0 {
+		return result
+	}
+
+	total := 0
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			total += len(text.Text)
+		}
+	}
+	if total <= maxBytes {
+		return result
+	}
+
+	remaining := maxBytes
+	contents := make([]mcp.Content, 0, len(result.Content))
+	for _, content := range result.Content {
+		text, ok := content.(mcp.TextContent)
+		if !ok {
+			contents = append(contents, content)
+			continue
+		}
+		if remaining <= 0 {
+			continue
+		}
+		if len(text.Text) > remaining {
+			cut := remaining
+			for cut > 0 && !utf8.RuneStart(text.Text[cut]) {
+				cut--
+			}
+			text.Text = text.Text[:cut]
 		}
-		return result, nil
+		remaining -= len(text.Text)
+		contents = append(contents, text)
+	}
+	contents = append(contents, mcp.NewTextContent(fmt.Sprintf(
+		"... (result truncated to %d of %d bytes by MAX_RESULT_BYTES; narrow the query, e.g. with filters, a smaller page or a single file, to see the rest)",
+		maxBytes, total)))
+
+	truncated := *result
+	truncated.Content = contents
+	return &truncated
+}
+
+// errorResult turns a handler error into an error tool result, prefixed with its code when it has one
+func errorResult(err error) *mcp.CallToolResult {
+	if code, ok := ErrorCodeOf(err); ok {
+		return mcp.NewToolResultError(fmt.Sprintf("Error [%s]: %v", code, err))
 	}
+	return mcp.NewToolResultError(fmt.Sprintf("Error: %v", err))
 }
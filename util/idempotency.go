@@ -0,0 +1,71 @@
+package util
+
+import (
+	"context"
+	"sync"
+
+	"github.com/athapong/aio-mcp/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// IdempotencyKeyArgument is the optional argument that makes a create tool idempotent
+const IdempotencyKeyArgument = "idempotency_key"
+
+var (
+	idempotentResults = sync.OnceValue(func() *Cache[string, *mcp.CallToolResult] {
+		return NewCache[string, *mcp.CallToolResult](config.Get().IdempotencyTTL, 1000)
+	})
+
+	idempotencyMu       sync.Mutex
+	idempotencyInFlight = make(map[string]chan struct{})
+)
+
+// Idempotent wraps a create handler so that calls repeating an idempotency_key
+// return the first successful result instead of creating a duplicate. Keys are
+// scoped to the tool and remembered for IDEMPOTENCY_TTL; failed calls are not
+// remembered so they can be retried. A concurrent call with the same key waits
+// for the first one to finish.
+func Idempotent(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		key, _ := request.Params.Arguments[IdempotencyKeyArgument].(string)
+		if key == "" || IsDryRun(request.Params.Arguments) {
+			return handler(ctx, request)
+		}
+		key = request.Params.Name + "\x00" + key
+
+		for {
+			idempotencyMu.Lock()
+			if result, ok := idempotentResults().Get(key); ok {
+				idempotencyMu.Unlock()
+				return result, nil
+			}
+			done, running := idempotencyInFlight[key]
+			if !running {
+				done = make(chan struct{})
+				idempotencyInFlight[key] = done
+				idempotencyMu.Unlock()
+				break
+			}
+			idempotencyMu.Unlock()
+
+			select {
+			case <-done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		result, err := handler(ctx, request)
+
+		idempotencyMu.Lock()
+		if err == nil && result != nil && !result.IsError {
+			idempotentResults().Set(key, result)
+		}
+		close(idempotencyInFlight[key])
+		delete(idempotencyInFlight, key)
+		idempotencyMu.Unlock()
+
+		return result, err
+	}
+}
@@ -0,0 +1,117 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// countingCreateHandler returns a handler that numbers the items it creates
+func countingCreateHandler(calls *int) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var mu sync.Mutex
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		*calls++
+		return mcp.NewToolResultText(fmt.Sprintf("created item %d", *calls)), nil
+	}
+}
+
+func createRequest(tool string, arguments map[string]interface{}) mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Name = tool
+	request.Params.Arguments = arguments
+	return request
+}
+
+func resultText(result *mcp.CallToolResult) string {
+	return result.Content[0].(mcp.TextContent).Text
+}
+
+func TestIdempotentRunsRepeatedCallOnce(t *testing.T) {
+	var calls int
+	handler := Idempotent(countingCreateHandler(&calls))
+	request := createRequest("idempotency_test_create", map[string]interface{}{"title": "a", IdempotencyKeyArgument: "key-1"})
+
+	first, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls)
+	}
+	if resultText(second) != resultText(first) {
+		t.Fatalf("second result = %q, want the first result %q", resultText(second), resultText(first))
+	}
+}
+
+func TestIdempotentConcurrentCallsRunOnce(t *testing.T) {
+	var calls int
+	handler := Idempotent(countingCreateHandler(&calls))
+	request := createRequest("idempotency_test_concurrent", map[string]interface{}{IdempotencyKeyArgument: "key-1"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler(context.Background(), request)
+		}()
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("handler ran %d times for concurrent calls, want 1", calls)
+	}
+}
+
+func TestIdempotentRunsEveryCallWithoutKey(t *testing.T) {
+	var calls int
+	handler := Idempotent(countingCreateHandler(&calls))
+
+	for _, arguments := range []map[string]interface{}{
+		{"title": "a"},
+		{"title": "a"},
+		{IdempotencyKeyArgument: "key-1", DryRunArgument: true},
+		{IdempotencyKeyArgument: "key-2"},
+		{IdempotencyKeyArgument: "key-3"},
+	} {
+		if _, err := handler(context.Background(), createRequest("idempotency_test_keys", arguments)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if calls != 5 {
+		t.Fatalf("handler ran %d times, want 5", calls)
+	}
+}
+
+func TestIdempotentDoesNotRememberFailures(t *testing.T) {
+	calls := 0
+	handler := Idempotent(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		if calls == 1 {
+			return mcp.NewToolResultError("upstream unavailable"), nil
+		}
+		return mcp.NewToolResultText("created"), nil
+	})
+	request := createRequest("idempotency_test_failure", map[string]interface{}{IdempotencyKeyArgument: "key-1"})
+
+	handler(context.Background(), request)
+	result, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 2 || result.IsError {
+		t.Fatalf("retry after a failure ran %d times with error %v, want 2 runs and a success", calls, result.IsError)
+	}
+}
@@ -0,0 +1,138 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// sensitiveArgumentKeys are substrings of argument names whose values are never logged
+var sensitiveArgumentKeys = []string{"token", "api_key", "apikey", "password", "secret", "authorization", "credential"}
+
+const maxLoggedValueLength = 200
+
+// SetupLogging makes a slog logger with the given level (debug, info, warn or
+// error) and format (text or json) the default. The standard log package is
+// routed through it at Info level, so log with slog directly. Every secret value is replaced with [REDACTED] wherever it
+// appears in a message or attribute, as are attributes with sensitive names.
+func SetupLogging(level, format string, secrets []string) {
+	var logLevel slog.Level
+	// Validated when the configuration is loaded
+	_ = logLevel.UnmarshalText([]byte(level))
+
+	options := &slog.HandlerOptions{
+		Level: logLevel,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if IsSensitiveKey(attr.Key) {
+				return slog.String(attr.Key, "[REDACTED]")
+			}
+			if attr.Value.Kind() == slog.KindString {
+				return slog.String(attr.Key, redactSecrets(attr.Value.String(), secrets))
+			}
+			return attr
+		},
+	}
+
+	var handler slog.Handler = slog.NewTextHandler(os.Stderr, options)
+	if format == "json" {
+		handler = slog.NewJSONHandler(os.Stderr, options)
+	}
+	slog.SetDefault(slog.New(handler))
+}
+
+func redactSecrets(text string, secrets []string) string {
+	for _, secret := range secrets {
+		text = strings.ReplaceAll(text, secret, "[REDACTED]")
+	}
+	return text
+}
+
+// ToolLoggingMiddleware logs every tool call with its name, arguments, duration and
+// outcome. The level controls verbosity: "debug" logs argument values with
+// sensitive ones redacted, "info" logs argument keys only, "error" logs failed
+// calls only and "off" disables logging.
+func ToolLoggingMiddleware(level string) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		if level == "off" {
+			return next
+		}
+		return logToolCalls(level, next)
+	}
+}
+
+func logToolCalls(level string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, request)
+		duration := time.Since(start)
+
+		failed := err != nil || (result != nil && result.IsError)
+		if level == "error" && !failed {
+			return result, err
+		}
+
+		attrs := []any{
+			"tool", request.Params.Name,
+			"args", formatArguments(request.Params.Arguments, level == "debug"),
+			"duration", duration.Round(time.Millisecond),
+		}
+		if !failed {
+			slog.InfoContext(ctx, "tool call", append(attrs, "status", "ok")...)
+			return result, err
+		}
+
+		attrs = append(attrs, "status", "error")
+		if err != nil {
+			// As a string, so configured secrets are redacted from it
+			attrs = append(attrs, "error", err.Error())
+		}
+		slog.ErrorContext(ctx, "tool call", attrs...)
+
+		return result, err
+	}
+}
+
+// formatArguments renders argument keys, and values when withValues is set,
+// redacting values of sensitive keys
+func formatArguments(arguments map[string]interface{}, withValues bool) string {
+	keys := make([]string, 0, len(arguments))
+	for key := range arguments {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if !withValues {
+		return "[" + strings.Join(keys, ",") + "]"
+	}
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := "[REDACTED]"
+		if !IsSensitiveKey(key) {
+			value = fmt.Sprintf("%v", arguments[key])
+			if len(value) > maxLoggedValueLength {
+				value = value[:maxLoggedValueLength] + "..."
+			}
+		}
+		parts = append(parts, fmt.Sprintf("%s=%q", key, value))
+	}
+	return "{" + strings.Join(parts, " ") + "}"
+}
+
+// IsSensitiveKey reports whether an argument name looks like it holds a secret
+func IsSensitiveKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, sensitive := range sensitiveArgumentKeys {
+		if strings.Contains(lower, sensitive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestToolLoggingKeepsFailuresAtErrorLevel(t *testing.T) {
+	previous := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	var out bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelError})))
+
+	ok := ToolLoggingMiddleware("info")(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("done"), nil
+	})
+	failing := ToolLoggingMiddleware("info")(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("upstream unavailable")
+	})
+
+	if _, err := callToolNamed(ok, "ok_tool"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("successful call was logged at error level: %s", out.String())
+	}
+
+	if _, err := callToolNamed(failing, "failing_tool"); err == nil {
+		t.Fatal("expected the handler error to be returned")
+	}
+	line := out.String()
+	for _, want := range []string{"level=ERROR", "tool=failing_tool", "status=error", `error="upstream unavailable"`} {
+		if !strings.Contains(line, want) {
+			t.Errorf("log line %q does not contain %s", line, want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/athapong/aio-mcp/config"
+)
+
+// OutputPath returns the absolute path for a generated file. Files are rooted in
+// dir when given, otherwise in OUTPUT_DIR, falling back to the current working
+// directory. The directory is created if it does not exist.
+func OutputPath(dir, fileName string) (string, error) {
+	if dir == "" {
+		dir = config.Get().OutputDir
+	}
+	if dir == "" {
+		dir = "."
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve output directory %s: %v", dir, err)
+	}
+
+	if err := os.MkdirAll(absDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create output directory %s: %v", absDir, err)
+	}
+
+	return filepath.Join(absDir, fileName), nil
+}
@@ -0,0 +1,106 @@
+package util
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Pagination is the paging of a list tool: where to start, how many items to
+// request per page, and whether to keep fetching pages until the results run out
+// or MaxItems items have been collected
+type Pagination struct {
+	Page     int
+	Cursor   string
+	PerPage  int
+	FetchAll bool
+	MaxItems int
+}
+
+// WithPagePagination adds the page, per_page, fetch_all and max_items arguments
+// to a tool over a page-numbered API, documenting the given defaults
+func WithPagePagination(defaults Pagination) mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		mcp.WithNumber("page", mcp.Description("Page to start from (default: 1)"))(tool)
+		withPagingArguments(tool, defaults)
+	}
+}
+
+// WithCursorPagination adds the cursor, per_page, fetch_all and max_items
+// arguments to a tool over a cursor-based API, documenting the given defaults
+func WithCursorPagination(defaults Pagination) mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		mcp.WithString("cursor", mcp.Description("Cursor returned by a previous call to continue from"))(tool)
+		withPagingArguments(tool, defaults)
+	}
+}
+
+func withPagingArguments(tool *mcp.Tool, defaults Pagination) {
+	mcp.WithNumber("per_page", mcp.Description(fmt.Sprintf("Number of items per page (default: %d)", defaults.PerPage)))(tool)
+	mcp.WithBoolean("fetch_all", mcp.Description(fmt.Sprintf("Keep fetching pages until all results or max_items are collected (default: %t)", defaults.FetchAll)))(tool)
+	mcp.WithNumber("max_items", mcp.Description(fmt.Sprintf("Maximum number of items to return when fetching all pages (default: %d)", defaults.MaxItems)))(tool)
+}
+
+// ParsePagination reads the paging arguments, using defaults for those not given
+func ParsePagination(arguments map[string]interface{}, defaults Pagination) Pagination {
+	p := defaults
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if page, ok := arguments["page"].(float64); ok && page >= 1 {
+		p.Page = int(page)
+	}
+	if cursor, ok := arguments["cursor"].(string); ok {
+		p.Cursor = cursor
+	}
+	if perPage, ok := arguments["per_page"].(float64); ok && perPage >= 1 {
+		p.PerPage = int(perPage)
+	}
+	if fetchAll, ok := arguments["fetch_all"].(bool); ok {
+		p.FetchAll = fetchAll
+	}
+	if maxItems, ok := arguments["max_items"].(float64); ok && maxItems >= 1 {
+		p.MaxItems = int(maxItems)
+	}
+	return p
+}
+
+// Paginate collects items from a paged API starting at the start cursor. fetch
+// returns the items of one page and the cursor of the next, or the zero cursor
+// after the last page. Only one page is fetched unless FetchAll is set, and
+// never more than MaxItems items are returned. The returned cursor is where to
+// continue from, the zero cursor when everything was fetched; when the last
+// page had to be cut short it points at that page again, so continuing may
+// repeat some items but never skips any.
+func Paginate[T any, C comparable](start C, p Pagination, fetch func(cursor C, perPage int) ([]T, C, error)) ([]T, C, error) {
+	var zero C
+	var items []T
+	cursor := start
+	for {
+		page, next, err := fetch(cursor, p.PerPage)
+		if err != nil {
+			return nil, zero, err
+		}
+
+		if p.MaxItems > 0 && len(items)+len(page) > p.MaxItems {
+			items = append(items, page[:p.MaxItems-len(items)]...)
+			return items, cursor, nil
+		}
+		items = append(items, page...)
+
+		cursor = next
+		if cursor == zero || !p.FetchAll || (p.MaxItems > 0 && len(items) >= p.MaxItems) {
+			return items, cursor, nil
+		}
+	}
+}
+
+// PaginationNote tells the caller how to fetch the results after next, or
+// returns "" when there are none
+func PaginationNote[C comparable](next C, argument string) string {
+	var zero C
+	if next == zero {
+		return ""
+	}
+	return fmt.Sprintf("\nMore results are available: call again with %s=%v, or set fetch_all to true\n", argument, next)
+}
@@ -0,0 +1,30 @@
+package util
+
+import (
+	"encoding/base64"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ResultBuilder assembles a tool result made of several content items, such as
+// a text summary followed by the image it produced, so clients can
+// render each artifact natively instead of parsing it out of one text blob
+type ResultBuilder struct {
+	contents []mcp.Content
+}
+
+// NewResultBuilder starts a result whose first item is the given text summary
+func NewResultBuilder(summary string) *ResultBuilder {
+	return &ResultBuilder{contents: []mcp.Content{mcp.NewTextContent(summary)}}
+}
+
+// Image appends an image item with already encoded image data
+func (b *ResultBuilder) Image(data []byte, mimeType string) *ResultBuilder {
+	b.contents = append(b.contents, mcp.NewImageContent(base64.StdEncoding.EncodeToString(data), mimeType))
+	return b
+}
+
+// Build returns the assembled result
+func (b *ResultBuilder) Build() *mcp.CallToolResult {
+	return &mcp.CallToolResult{Content: b.contents}
+}
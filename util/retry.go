@@ -0,0 +1,37 @@
+package util
+
+import (
+	"context"
+	"time"
+)
+
+// Retryable reports whether err may succeed on a later attempt. Rate limits,
+// upstream failures and errors without a code (such as network errors) are
+// retried; invalid arguments, missing resources and auth failures are not.
+func Retryable(err error) bool {
+	code, ok := ErrorCodeOf(err)
+	if !ok {
+		return true
+	}
+	return code == ErrCodeRateLimited || code == ErrCodeUpstream
+}
+
+// Retry calls fn until it succeeds, returns an error that is not Retryable, or
+// has been called attempts times. The delay between attempts starts at
+// initialDelay and doubles after each failure. The last error is returned.
+func Retry(ctx context.Context, attempts int, initialDelay time.Duration, fn func() error) error {
+	delay := initialDelay
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || attempt >= attempts || !Retryable(err) {
+			return err
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+		delay *= 2
+	}
+}
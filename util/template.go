@@ -0,0 +1,69 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// resultTemplates are the user-supplied templates that replace the built-in
+// result format of a tool, keyed by tool name. They are loaded once at startup.
+var resultTemplates = map[string]*template.Template{}
+
+var templateFuncs = template.FuncMap{
+	"join":     strings.Join,
+	"truncate": func(maxLen int, text string) string { return TruncateText(text, maxLen) },
+	"indent": func(prefix, text string) string {
+		return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
+	},
+}
+
+// LoadResultTemplates parses every <tool_name>.tmpl file in dir as a Go
+// text/template for that tool's result. All templates are parsed up front so a
+// broken template fails startup instead of a tool call. An empty dir loads nothing.
+func LoadResultTemplates(dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return fmt.Errorf("failed to list result templates in %s: %v", dir, err)
+	}
+
+	templates := make(map[string]*template.Template, len(files))
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read result template %s: %v", file, err)
+		}
+
+		name := strings.TrimSuffix(filepath.Base(file), ".tmpl")
+		tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(string(content))
+		if err != nil {
+			return fmt.Errorf("invalid result template %s: %v", file, err)
+		}
+		templates[name] = tmpl
+	}
+
+	resultTemplates = templates
+	return nil
+}
+
+// RenderResult renders data with the template configured for tool. It reports
+// false when the tool has no template, in which case the caller uses its
+// built-in format.
+func RenderResult(tool string, data interface{}) (string, bool, error) {
+	tmpl, ok := resultTemplates[tool]
+	if !ok {
+		return "", false, nil
+	}
+
+	var result strings.Builder
+	if err := tmpl.Execute(&result, data); err != nil {
+		return "", true, fmt.Errorf("failed to render result template for %s: %v", tool, err)
+	}
+	return result.String(), true, nil
+}
@@ -0,0 +1,21 @@
+package util
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// TruncateText shortens text to at most maxLen bytes, cutting on a rune boundary
+// and noting how much was dropped. A maxLen of 0 or less disables truncation.
+func TruncateText(text string, maxLen int) string {
+	if maxLen <= 0 || len(text) <= maxLen {
+		return text
+	}
+
+	cut := maxLen
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+
+	return fmt.Sprintf("%s\n... (truncated %d of %d characters)", text[:cut], len(text)-cut, len(text))
+}
@@ -0,0 +1,75 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ToolTimeoutMiddleware enforces a per-tool timeout through the request context.
+// Tools without an entry in timeouts use defaultTimeout; a timeout of 0 or less
+// disables the limit.
+//
+// A timed out call returns at once, but a handler that does not watch its
+// context keeps running. Tools that make changes, recognised by lookup as those
+// taking idempotency_key or dry_run, therefore get no default timeout: a call
+// reported as failed could still complete its write, and a retry would repeat
+// it. They are only limited by an entry in timeouts.
+func ToolTimeoutMiddleware(defaultTimeout time.Duration, timeouts map[string]time.Duration, lookup ToolLookupFunc) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			timeout, ok := timeouts[request.Params.Name]
+			if !ok {
+				timeout = defaultTimeout
+				if lookup != nil {
+					if tool, found := lookup(ctx, request.Params.Name); found && makesChanges(tool) {
+						timeout = 0
+					}
+				}
+			}
+			if timeout <= 0 {
+				return next(ctx, request)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			type callResult struct {
+				result *mcp.CallToolResult
+				err    error
+			}
+			// Buffered so a handler that ignores cancellation can still finish without blocking
+			done := make(chan callResult, 1)
+			go func() {
+				defer func() {
+					if r := recover(); r != nil {
+						done <- callResult{nil, fmt.Errorf("panic: %v", r)}
+					}
+				}()
+				result, err := next(ctx, request)
+				done <- callResult{result, err}
+			}()
+
+			select {
+			case res := <-done:
+				return res.result, res.err
+			case <-ctx.Done():
+				if ctx.Err() == context.DeadlineExceeded {
+					return mcp.NewToolResultError(fmt.Sprintf("tool %s timed out after %s", request.Params.Name, timeout)), nil
+				}
+				return nil, ctx.Err()
+			}
+		}
+	}
+}
+
+// makesChanges reports whether a tool takes idempotency_key or dry_run, which
+// only tools that make changes do
+func makesChanges(tool mcp.Tool) bool {
+	_, idempotent := tool.InputSchema.Properties[IdempotencyKeyArgument]
+	_, dryRun := tool.InputSchema.Properties[DryRunArgument]
+	return idempotent || dryRun
+}
@@ -0,0 +1,89 @@
+package util
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// slowHandler takes a second to answer unless its context is cancelled first
+func slowHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	select {
+	case <-time.After(time.Second):
+		return mcp.NewToolResultText("done"), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func callToolNamed(handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), name string) (*mcp.CallToolResult, error) {
+	var request mcp.CallToolRequest
+	request.Params.Name = name
+	return handler(context.Background(), request)
+}
+
+func TestToolTimeoutMiddlewareStopsSlowHandler(t *testing.T) {
+	handler := ToolTimeoutMiddleware(20*time.Millisecond, nil, nil)(slowHandler)
+
+	start := time.Now()
+	result, err := callToolNamed(handler, "slow_tool")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("call took %s, want it cut off after the timeout", elapsed)
+	}
+	if !result.IsError {
+		t.Fatal("timed out call did not return an error result")
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "slow_tool timed out after 20ms") {
+		t.Fatalf("result = %q, want a timeout message", text)
+	}
+}
+
+func TestToolTimeoutMiddlewarePerToolOverride(t *testing.T) {
+	handler := ToolTimeoutMiddleware(20*time.Millisecond, map[string]time.Duration{"slow_tool": 0}, nil)(slowHandler)
+
+	result, err := callToolNamed(handler, "slow_tool")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatal("tool with its timeout disabled was cut off")
+	}
+}
+
+func TestToolTimeoutMiddlewarePassesFastResult(t *testing.T) {
+	fast := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	}
+	handler := ToolTimeoutMiddleware(time.Second, nil, nil)(fast)
+
+	result, err := callToolNamed(handler, "fast_tool")
+	if err != nil || result.IsError {
+		t.Fatalf("fast call = %v, %v, want its result", result, err)
+	}
+}
+
+func TestToolTimeoutMiddlewareSkipsToolsThatMakeChanges(t *testing.T) {
+	lookup := func(ctx context.Context, name string) (mcp.Tool, bool) {
+		return mcp.NewTool(name, WithDryRun()), true
+	}
+	handler := ToolTimeoutMiddleware(20*time.Millisecond, nil, lookup)(slowHandler)
+
+	result, err := callToolNamed(handler, "create_tool")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatal("tool taking dry_run was cut off by the default timeout")
+	}
+
+	handler = ToolTimeoutMiddleware(time.Second, map[string]time.Duration{"create_tool": 20 * time.Millisecond}, lookup)(slowHandler)
+	if result, _ := callToolNamed(handler, "create_tool"); result == nil || !result.IsError {
+		t.Fatal("explicit timeout for a tool taking dry_run was not applied")
+	}
+}
@@ -0,0 +1,147 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ToolLookupFunc returns the definition of a registered tool by name
+type ToolLookupFunc func(ctx context.Context, name string) (mcp.Tool, bool)
+
+// ArgumentValidationMiddleware checks the arguments of every tool call against
+// the input schema the tool was registered with, so handlers never see a missing
+// required argument or a value of the wrong type. Invalid calls get an
+// INVALID_ARGUMENT error listing every violation without reaching the handler.
+func ArgumentValidationMiddleware(lookup ToolLookupFunc) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			tool, ok := lookup(ctx, request.Params.Name)
+			if !ok {
+				return next(ctx, request)
+			}
+
+			if violations := ValidateArguments(tool.InputSchema, request.Params.Arguments); len(violations) > 0 {
+				return errorResult(NewToolError(ErrCodeInvalidArgument, "invalid arguments for %s: %s", request.Params.Name, strings.Join(violations, "; "))), nil
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// ValidateArguments returns a description of each way arguments violate schema:
+// missing required arguments, values whose type does not match the declared
+// type, and values outside a declared enum. Arguments not in the schema are
+// ignored.
+func ValidateArguments(schema mcp.ToolInputSchema, arguments map[string]interface{}) []string {
+	var violations []string
+	for _, name := range schema.Required {
+		if value, ok := arguments[name]; !ok || value == nil {
+			violations = append(violations, fmt.Sprintf("%s is required", name))
+		}
+	}
+
+	names := make([]string, 0, len(arguments))
+	for name := range arguments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := arguments[name]
+		property, ok := schema.Properties[name].(map[string]interface{})
+		if !ok || value == nil {
+			continue
+		}
+
+		if expected, ok := property["type"].(string); ok && !matchesSchemaType(expected, value) {
+			violations = append(violations, fmt.Sprintf("%s must be a %s, got %s", name, expected, jsonTypeName(value)))
+			continue
+		}
+
+		if enum, ok := property["enum"].([]string); ok && len(enum) > 0 {
+			str, _ := value.(string)
+			if !containsString(enum, str) {
+				violations = append(violations, fmt.Sprintf("%s must be one of %s", name, strings.Join(enum, ", ")))
+			}
+		}
+	}
+	return violations
+}
+
+// matchesSchemaType reports whether a decoded JSON value has the given JSON schema type
+func matchesSchemaType(expected string, value interface{}) bool {
+	switch expected {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := numberValue(value)
+		return ok
+	case "integer":
+		number, ok := numberValue(value)
+		return ok && number == math.Trunc(number)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	default:
+		return true
+	}
+}
+
+func numberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		number, err := v.Float64()
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// jsonTypeName names the JSON type of a decoded value for error messages
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	if _, ok := numberValue(value); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
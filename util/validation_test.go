@@ -0,0 +1,103 @@
+package util
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+var validationTestTool = mcp.NewTool("validation_test",
+	mcp.WithString("project", mcp.Required()),
+	mcp.WithNumber("limit"),
+	mcp.WithBoolean("draft"),
+	mcp.WithArray("labels"),
+	mcp.WithString("state", mcp.Enum("opened", "closed")),
+)
+
+func TestValidateArguments(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments map[string]interface{}
+		want      []string
+	}{
+		{
+			name:      "valid",
+			arguments: map[string]interface{}{"project": "group/repo", "limit": float64(10), "draft": true, "labels": []interface{}{"bug"}, "state": "opened"},
+		},
+		{
+			name:      "unknown arguments are ignored",
+			arguments: map[string]interface{}{"project": "group/repo", "extra": 1},
+		},
+		{
+			name:      "missing required argument",
+			arguments: map[string]interface{}{"limit": float64(10)},
+			want:      []string{"project is required"},
+		},
+		{
+			name:      "null required argument",
+			arguments: map[string]interface{}{"project": nil},
+			want:      []string{"project is required"},
+		},
+		{
+			name:      "string instead of number",
+			arguments: map[string]interface{}{"project": "group/repo", "limit": "10"},
+			want:      []string{"limit must be a number, got string"},
+		},
+		{
+			name:      "number instead of string",
+			arguments: map[string]interface{}{"project": float64(42)},
+			want:      []string{"project must be a string, got number"},
+		},
+		{
+			name:      "string instead of boolean",
+			arguments: map[string]interface{}{"project": "group/repo", "draft": "true"},
+			want:      []string{"draft must be a boolean, got string"},
+		},
+		{
+			name:      "string instead of array",
+			arguments: map[string]interface{}{"project": "group/repo", "labels": "bug"},
+			want:      []string{"labels must be a array, got string"},
+		},
+		{
+			name:      "value outside enum",
+			arguments: map[string]interface{}{"project": "group/repo", "state": "merged"},
+			want:      []string{"state must be one of opened, closed"},
+		},
+		{
+			name:      "every violation is reported",
+			arguments: map[string]interface{}{"draft": float64(1), "limit": true},
+			want:      []string{"project is required", "draft must be a boolean, got number", "limit must be a number, got boolean"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ValidateArguments(validationTestTool.InputSchema, test.arguments)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("ValidateArguments() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestArgumentValidationMiddlewareRejectsInvalidCall(t *testing.T) {
+	called := false
+	next := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called = true
+		return mcp.NewToolResultText("ok"), nil
+	}
+	lookup := func(ctx context.Context, name string) (mcp.Tool, bool) {
+		return validationTestTool, name == validationTestTool.Name
+	}
+	handler := ArgumentValidationMiddleware(lookup)(next)
+
+	result, err := handler(context.Background(), createRequest("validation_test", map[string]interface{}{"limit": "10"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if called || !result.IsError {
+		t.Fatalf("invalid call reached the handler (%v) or did not fail (%v)", called, !result.IsError)
+	}
+}